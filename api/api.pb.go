@@ -29,16 +29,24 @@ type Event_Mode int32
 const (
 	Event_TIMESTAMP Event_Mode = 0
 	Event_CRON      Event_Mode = 1
+	// The event recurs every interval_seconds from its anchor
+	Event_INTERVAL Event_Mode = 2
+	// The event recurs according to its rrule
+	Event_RRULE Event_Mode = 3
 )
 
 var Event_Mode_name = map[int32]string{
 	0: "TIMESTAMP",
 	1: "CRON",
+	2: "INTERVAL",
+	3: "RRULE",
 }
 
 var Event_Mode_value = map[string]int32{
 	"TIMESTAMP": 0,
 	"CRON":      1,
+	"INTERVAL":  2,
+	"RRULE":     3,
 }
 
 func (x Event_Mode) String() string {
@@ -49,17 +57,135 @@ func (Event_Mode) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_00212fb1f9d3bf1c, []int{0, 0}
 }
 
+// What happens to the occurrences of a recurring event missed while the scheduler was down
+type Event_MisfirePolicy int32
+
+const (
+	// A single occurrence is dispatched for all the missed ones
+	Event_FIRE_ONCE Event_MisfirePolicy = 0
+	// Every missed occurrence is dispatched, up to the 100 most recent ones
+	Event_FIRE_ALL Event_MisfirePolicy = 1
+	// The missed occurrences are dropped
+	Event_SKIP Event_MisfirePolicy = 2
+)
+
+var Event_MisfirePolicy_name = map[int32]string{
+	0: "FIRE_ONCE",
+	1: "FIRE_ALL",
+	2: "SKIP",
+}
+
+var Event_MisfirePolicy_value = map[string]int32{
+	"FIRE_ONCE": 0,
+	"FIRE_ALL":  1,
+	"SKIP":      2,
+}
+
+func (x Event_MisfirePolicy) String() string {
+	return proto.EnumName(Event_MisfirePolicy_name, int32(x))
+}
+
+func (Event_MisfirePolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0, 1}
+}
+
+// What happens to the occurrences falling outside the business days and hours of the calendar
+type Event_CalendarPolicy int32
+
+const (
+	// The occurrences are dropped
+	Event_CALENDAR_SKIP Event_CalendarPolicy = 0
+	// The occurrences are moved to the next business day, or to the opening time
+	Event_CALENDAR_SHIFT Event_CalendarPolicy = 1
+)
+
+var Event_CalendarPolicy_name = map[int32]string{
+	0: "CALENDAR_SKIP",
+	1: "CALENDAR_SHIFT",
+}
+
+var Event_CalendarPolicy_value = map[string]int32{
+	"CALENDAR_SKIP":  0,
+	"CALENDAR_SHIFT": 1,
+}
+
+func (x Event_CalendarPolicy) String() string {
+	return proto.EnumName(Event_CalendarPolicy_name, int32(x))
+}
+
+func (Event_CalendarPolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0, 2}
+}
+
+// How the occurrences are delayed within the jitter window
+type Event_JitterMode int32
+
+const (
+	// Every occurrence is delayed by the same offset derived from the id
+	Event_JITTER_HASH Event_JitterMode = 0
+	// Each occurrence is delayed by its own pseudo-random offset
+	Event_JITTER_RANDOM Event_JitterMode = 1
+)
+
+var Event_JitterMode_name = map[int32]string{
+	0: "JITTER_HASH",
+	1: "JITTER_RANDOM",
+}
+
+var Event_JitterMode_value = map[string]int32{
+	"JITTER_HASH":   0,
+	"JITTER_RANDOM": 1,
+}
+
+func (x Event_JitterMode) String() string {
+	return proto.EnumName(Event_JitterMode_name, int32(x))
+}
+
+func (Event_JitterMode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0, 3}
+}
+
 type Event struct {
 	Id             string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	CronExpression string `protobuf:"bytes,2,opt,name=cron_expression,json=cronExpression,proto3" json:"cron_expression,omitempty"`
 	// Unix timestamp
-	ShouldExecuteAt      int64      `protobuf:"varint,3,opt,name=should_execute_at,json=shouldExecuteAt,proto3" json:"should_execute_at,omitempty"`
-	Mode                 Event_Mode `protobuf:"varint,4,opt,name=mode,proto3,enum=api.Event_Mode" json:"mode,omitempty"`
-	Topic                string     `protobuf:"bytes,5,opt,name=topic,proto3" json:"topic,omitempty"`
-	Payload              []byte     `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	ShouldExecuteAt int64      `protobuf:"varint,3,opt,name=should_execute_at,json=shouldExecuteAt,proto3" json:"should_execute_at,omitempty"`
+	Mode            Event_Mode `protobuf:"varint,4,opt,name=mode,proto3,enum=api.Event_Mode" json:"mode,omitempty"`
+	Topic           string     `protobuf:"bytes,5,opt,name=topic,proto3" json:"topic,omitempty"`
+	Payload         []byte     `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The subscribers can filter the events of a topic on their labels
+	Labels map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// IANA time zone the cron expression is evaluated in, e.g. Europe/Paris, the zone of the server by default.
+	// A CRON_TZ= prefix of the cron expression is used as well
+	TimeZone string `protobuf:"bytes,8,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	// Unix timestamps bounding the occurrences of a recurring event, ignored if 0
+	StartAt int64 `protobuf:"varint,9,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	EndAt   int64 `protobuf:"varint,10,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	// The recurring event is unscheduled after this number of occurrences, it recurs until end_at if 0
+	MaxOccurrences uint32 `protobuf:"varint,11,opt,name=max_occurrences,json=maxOccurrences,proto3" json:"max_occurrences,omitempty"`
+	// Number of occurrences already dispatched, it is ignored by Schedule
+	FireCount     uint32              `protobuf:"varint,12,opt,name=fire_count,json=fireCount,proto3" json:"fire_count,omitempty"`
+	MisfirePolicy Event_MisfirePolicy `protobuf:"varint,13,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=api.Event_MisfirePolicy" json:"misfire_policy,omitempty"`
+	// Unix timestamp of the last dispatched occurrence, it is ignored by Schedule
+	LastFiredAt int64 `protobuf:"varint,14,opt,name=last_fired_at,json=lastFiredAt,proto3" json:"last_fired_at,omitempty"`
+	// Duration between two occurrences of an INTERVAL event, at least 1 second
+	IntervalSeconds int64 `protobuf:"varint,15,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	// Unix timestamp the occurrences of an INTERVAL event are aligned on, the first occurrence if 0.
+	// It is the start of the rules of a RRULE event which have no DTSTART
+	Anchor int64 `protobuf:"varint,16,opt,name=anchor,proto3" json:"anchor,omitempty"`
+	// RFC 5545 RRULE, EXRULE, RDATE and EXDATE lines of a RRULE event, e.g. "RRULE:FREQ=MONTHLY;BYDAY=2TU".
+	// The rules start at their DTSTART line if any, at the anchor otherwise
+	Rrule string `protobuf:"bytes,17,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	// Name of the calendar restricting the occurrences of a recurring event
+	Calendar       string               `protobuf:"bytes,18,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	CalendarPolicy Event_CalendarPolicy `protobuf:"varint,19,opt,name=calendar_policy,json=calendarPolicy,proto3,enum=api.Event_CalendarPolicy" json:"calendar_policy,omitempty"`
+	// Window in milliseconds within which each occurrence is delayed to spread the load,
+	// the default jitter of the topic if 0 and no jitter at all if -1
+	JitterMs             int64            `protobuf:"varint,20,opt,name=jitter_ms,json=jitterMs,proto3" json:"jitter_ms,omitempty"`
+	JitterMode           Event_JitterMode `protobuf:"varint,21,opt,name=jitter_mode,json=jitterMode,proto3,enum=api.Event_JitterMode" json:"jitter_mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -129,6 +255,111 @@ func (m *Event) GetPayload() []byte {
 	return nil
 }
 
+func (m *Event) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Event) GetTimeZone() string {
+	if m != nil {
+		return m.TimeZone
+	}
+	return ""
+}
+
+func (m *Event) GetStartAt() int64 {
+	if m != nil {
+		return m.StartAt
+	}
+	return 0
+}
+
+func (m *Event) GetEndAt() int64 {
+	if m != nil {
+		return m.EndAt
+	}
+	return 0
+}
+
+func (m *Event) GetMaxOccurrences() uint32 {
+	if m != nil {
+		return m.MaxOccurrences
+	}
+	return 0
+}
+
+func (m *Event) GetFireCount() uint32 {
+	if m != nil {
+		return m.FireCount
+	}
+	return 0
+}
+
+func (m *Event) GetMisfirePolicy() Event_MisfirePolicy {
+	if m != nil {
+		return m.MisfirePolicy
+	}
+	return Event_FIRE_ONCE
+}
+
+func (m *Event) GetLastFiredAt() int64 {
+	if m != nil {
+		return m.LastFiredAt
+	}
+	return 0
+}
+
+func (m *Event) GetIntervalSeconds() int64 {
+	if m != nil {
+		return m.IntervalSeconds
+	}
+	return 0
+}
+
+func (m *Event) GetAnchor() int64 {
+	if m != nil {
+		return m.Anchor
+	}
+	return 0
+}
+
+func (m *Event) GetRrule() string {
+	if m != nil {
+		return m.Rrule
+	}
+	return ""
+}
+
+func (m *Event) GetCalendar() string {
+	if m != nil {
+		return m.Calendar
+	}
+	return ""
+}
+
+func (m *Event) GetCalendarPolicy() Event_CalendarPolicy {
+	if m != nil {
+		return m.CalendarPolicy
+	}
+	return Event_CALENDAR_SKIP
+}
+
+func (m *Event) GetJitterMs() int64 {
+	if m != nil {
+		return m.JitterMs
+	}
+	return 0
+}
+
+func (m *Event) GetJitterMode() Event_JitterMode {
+	if m != nil {
+		return m.JitterMode
+	}
+	return Event_JITTER_HASH
+}
+
 type Event_ID struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -246,6 +477,122 @@ func (m *ScheduleResponse) GetId() *Event_ID {
 	return nil
 }
 
+// The event is validated as by Schedule but it is not scheduled, the caller must be allowed
+// to schedule on its topic
+type PreviewScheduleRequest struct {
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// Number of occurrences to list, at most 100, 10 if 0
+	Count                uint32   `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PreviewScheduleRequest) Reset()         { *m = PreviewScheduleRequest{} }
+func (m *PreviewScheduleRequest) String() string { return proto.CompactTextString(m) }
+func (*PreviewScheduleRequest) ProtoMessage()    {}
+func (*PreviewScheduleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+}
+
+func (m *PreviewScheduleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PreviewScheduleRequest.Unmarshal(m, b)
+}
+func (m *PreviewScheduleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PreviewScheduleRequest.Marshal(b, m, deterministic)
+}
+func (m *PreviewScheduleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PreviewScheduleRequest.Merge(m, src)
+}
+func (m *PreviewScheduleRequest) XXX_Size() int {
+	return xxx_messageInfo_PreviewScheduleRequest.Size(m)
+}
+func (m *PreviewScheduleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PreviewScheduleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PreviewScheduleRequest proto.InternalMessageInfo
+
+func (m *PreviewScheduleRequest) GetEvent() *Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *PreviewScheduleRequest) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type PreviewScheduleResponse struct {
+	// The error is the reason why Schedule would reject the event
+	Valid bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// English description of the schedule, e.g. "at 08:00 on Monday to Friday (Europe/Paris)"
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Unix timestamps of the next occurrences, without the jitter
+	Occurrences          []int64  `protobuf:"varint,4,rep,packed,name=occurrences,proto3" json:"occurrences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PreviewScheduleResponse) Reset()         { *m = PreviewScheduleResponse{} }
+func (m *PreviewScheduleResponse) String() string { return proto.CompactTextString(m) }
+func (*PreviewScheduleResponse) ProtoMessage()    {}
+func (*PreviewScheduleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+}
+
+func (m *PreviewScheduleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PreviewScheduleResponse.Unmarshal(m, b)
+}
+func (m *PreviewScheduleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PreviewScheduleResponse.Marshal(b, m, deterministic)
+}
+func (m *PreviewScheduleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PreviewScheduleResponse.Merge(m, src)
+}
+func (m *PreviewScheduleResponse) XXX_Size() int {
+	return xxx_messageInfo_PreviewScheduleResponse.Size(m)
+}
+func (m *PreviewScheduleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PreviewScheduleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PreviewScheduleResponse proto.InternalMessageInfo
+
+func (m *PreviewScheduleResponse) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *PreviewScheduleResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *PreviewScheduleResponse) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *PreviewScheduleResponse) GetOccurrences() []int64 {
+	if m != nil {
+		return m.Occurrences
+	}
+	return nil
+}
+
 type UnscheduleRequest struct {
 	Id                   *Event_ID `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
@@ -257,7 +604,7 @@ func (m *UnscheduleRequest) Reset()         { *m = UnscheduleRequest{} }
 func (m *UnscheduleRequest) String() string { return proto.CompactTextString(m) }
 func (*UnscheduleRequest) ProtoMessage()    {}
 func (*UnscheduleRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
 }
 
 func (m *UnscheduleRequest) XXX_Unmarshal(b []byte) error {
@@ -295,7 +642,7 @@ func (m *UnscheduleResponse) Reset()         { *m = UnscheduleResponse{} }
 func (m *UnscheduleResponse) String() string { return proto.CompactTextString(m) }
 func (*UnscheduleResponse) ProtoMessage()    {}
 func (*UnscheduleResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
 }
 
 func (m *UnscheduleResponse) XXX_Unmarshal(b []byte) error {
@@ -316,18 +663,104 @@ func (m *UnscheduleResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_UnscheduleResponse proto.InternalMessageInfo
 
-type StreamEventsRequest struct {
+// An empty topic lists the events of every topic
+type ListEventsRequest struct {
 	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *ListEventsRequest) Reset()         { *m = ListEventsRequest{} }
+func (m *ListEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListEventsRequest) ProtoMessage()    {}
+func (*ListEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{7}
+}
+
+func (m *ListEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEventsRequest.Unmarshal(m, b)
+}
+func (m *ListEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEventsRequest.Merge(m, src)
+}
+func (m *ListEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListEventsRequest.Size(m)
+}
+func (m *ListEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEventsRequest proto.InternalMessageInfo
+
+func (m *ListEventsRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type ListEventsResponse struct {
+	Events               []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListEventsResponse) Reset()         { *m = ListEventsResponse{} }
+func (m *ListEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListEventsResponse) ProtoMessage()    {}
+func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{8}
+}
+
+func (m *ListEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEventsResponse.Unmarshal(m, b)
+}
+func (m *ListEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEventsResponse.Merge(m, src)
+}
+func (m *ListEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListEventsResponse.Size(m)
+}
+func (m *ListEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEventsResponse proto.InternalMessageInfo
+
+func (m *ListEventsResponse) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type StreamEventsRequest struct {
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// The subscribers of a topic sharing a group receive each event once, in turn
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// Only the events whose labels match the expression are streamed, e.g. `tenant = acme and region in (eu, us)`
+	Filter string `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	// The last resume token received by the subscriber, the events it missed since are streamed first.
+	// The events may be received twice, they are deduplicated on their sequence
+	ResumeToken          string   `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
 func (m *StreamEventsRequest) Reset()         { *m = StreamEventsRequest{} }
 func (m *StreamEventsRequest) String() string { return proto.CompactTextString(m) }
 func (*StreamEventsRequest) ProtoMessage()    {}
 func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{9}
 }
 
 func (m *StreamEventsRequest) XXX_Unmarshal(b []byte) error {
@@ -355,8 +788,33 @@ func (m *StreamEventsRequest) GetTopic() string {
 	return ""
 }
 
+func (m *StreamEventsRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *StreamEventsRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *StreamEventsRequest) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
+}
+
 type StreamEventsResponse struct {
-	Event                *Event   `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// Position of the event in the dispatches of the server, it increases with each dispatch
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Every event of the subscription up to the token has been sent
+	ResumeToken          string   `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -366,7 +824,7 @@ func (m *StreamEventsResponse) Reset()         { *m = StreamEventsResponse{} }
 func (m *StreamEventsResponse) String() string { return proto.CompactTextString(m) }
 func (*StreamEventsResponse) ProtoMessage()    {}
 func (*StreamEventsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
+	return fileDescriptor_00212fb1f9d3bf1c, []int{10}
 }
 
 func (m *StreamEventsResponse) XXX_Unmarshal(b []byte) error {
@@ -394,57 +852,1108 @@ func (m *StreamEventsResponse) GetEvent() *Event {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("api.Event_Mode", Event_Mode_name, Event_Mode_value)
-	proto.RegisterType((*Event)(nil), "api.Event")
-	proto.RegisterType((*Event_ID)(nil), "api.Event.ID")
-	proto.RegisterType((*ScheduleRequest)(nil), "api.ScheduleRequest")
-	proto.RegisterType((*ScheduleResponse)(nil), "api.ScheduleResponse")
-	proto.RegisterType((*UnscheduleRequest)(nil), "api.UnscheduleRequest")
-	proto.RegisterType((*UnscheduleResponse)(nil), "api.UnscheduleResponse")
-	proto.RegisterType((*StreamEventsRequest)(nil), "api.StreamEventsRequest")
-	proto.RegisterType((*StreamEventsResponse)(nil), "api.StreamEventsResponse")
+func (m *StreamEventsResponse) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
 }
 
-func init() {
-	proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c)
+func (m *StreamEventsResponse) GetResumeToken() string {
+	if m != nil {
+		return m.ResumeToken
+	}
+	return ""
 }
 
-var fileDescriptor_00212fb1f9d3bf1c = []byte{
-	// 395 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x52, 0x4f, 0x8f, 0x93, 0x40,
-	0x14, 0xdf, 0xa1, 0xb0, 0x2e, 0x6f, 0x77, 0x4b, 0xf7, 0x89, 0x3a, 0x92, 0x18, 0x09, 0x1e, 0x24,
-	0x9a, 0x34, 0xca, 0x5e, 0xf4, 0x64, 0x36, 0x2e, 0x31, 0x3d, 0x54, 0x0d, 0xad, 0xe7, 0x06, 0x61,
-	0x92, 0x92, 0xb4, 0x0c, 0x32, 0x83, 0xa9, 0xdf, 0xd2, 0x8f, 0xe3, 0xd1, 0x30, 0x14, 0x8a, 0xd4,
-	0xa4, 0xc7, 0x79, 0xbf, 0xf7, 0x7e, 0xff, 0x32, 0x60, 0xc6, 0x45, 0x36, 0x2d, 0x4a, 0x2e, 0x39,
-	0x8e, 0xe2, 0x22, 0xf3, 0xfe, 0x10, 0x30, 0xc2, 0x9f, 0x2c, 0x97, 0x38, 0x06, 0x2d, 0x4b, 0x29,
-	0x71, 0x89, 0x6f, 0x46, 0x5a, 0x96, 0xe2, 0x4b, 0xb0, 0x92, 0x92, 0xe7, 0x2b, 0xb6, 0x2b, 0x4a,
-	0x26, 0x44, 0xc6, 0x73, 0xaa, 0x29, 0x70, 0x5c, 0x8f, 0xc3, 0x6e, 0x8a, 0xaf, 0xe0, 0x46, 0xac,
-	0x79, 0xb5, 0x49, 0x57, 0x6c, 0xc7, 0x92, 0x4a, 0xb2, 0x55, 0x2c, 0xe9, 0xc8, 0x25, 0xfe, 0x28,
-	0xb2, 0x1a, 0x20, 0x6c, 0xe6, 0x77, 0x12, 0x5f, 0x80, 0xbe, 0xe5, 0x29, 0xa3, 0xba, 0x4b, 0xfc,
-	0x71, 0x60, 0x4d, 0x6b, 0x37, 0x4a, 0x7e, 0x3a, 0xe7, 0x29, 0x8b, 0x14, 0x88, 0x36, 0x18, 0x92,
-	0x17, 0x59, 0x42, 0x0d, 0xa5, 0xd7, 0x3c, 0x90, 0xc2, 0x83, 0x22, 0xfe, 0xb5, 0xe1, 0x71, 0x4a,
-	0xcf, 0x5d, 0xe2, 0x5f, 0x45, 0xed, 0xd3, 0xb1, 0x41, 0x9b, 0xdd, 0x0f, 0xfd, 0x7b, 0xcf, 0x41,
-	0xaf, 0x39, 0xf1, 0x1a, 0xcc, 0xe5, 0x6c, 0x1e, 0x2e, 0x96, 0x77, 0xf3, 0xaf, 0x93, 0x33, 0xbc,
-	0x00, 0xfd, 0x63, 0xf4, 0xe5, 0xf3, 0x84, 0x78, 0xb7, 0x60, 0x2d, 0x92, 0x35, 0x4b, 0xab, 0x0d,
-	0x8b, 0xd8, 0x8f, 0x8a, 0x09, 0x89, 0x2e, 0x18, 0xac, 0x76, 0xa3, 0x68, 0x2e, 0x03, 0x38, 0xf8,
-	0x8b, 0x1a, 0xc0, 0x7b, 0x0b, 0x93, 0xc3, 0x91, 0x28, 0x78, 0x2e, 0x18, 0x3e, 0xeb, 0x94, 0x2f,
-	0x83, 0xeb, 0x5e, 0xa4, 0xd9, 0xbd, 0x32, 0x12, 0xc0, 0xcd, 0xb7, 0x5c, 0x0c, 0x94, 0x4e, 0xdc,
-	0xd8, 0x80, 0xfd, 0x9b, 0x46, 0xc8, 0x7b, 0x0d, 0x0f, 0x17, 0xb2, 0x64, 0xf1, 0x56, 0xed, 0x8a,
-	0x96, 0xab, 0xeb, 0x8b, 0xf4, 0xfa, 0xf2, 0xde, 0x81, 0xfd, 0xef, 0xf2, 0xde, 0xed, 0xc9, 0x8c,
-	0xc1, 0x6f, 0x02, 0x66, 0x1b, 0xb2, 0xc4, 0xf7, 0x70, 0xd1, 0x3e, 0xd0, 0x56, 0xcb, 0x83, 0xd6,
-	0x9c, 0x47, 0x83, 0xe9, 0xde, 0xed, 0x19, 0x7e, 0x00, 0x38, 0xa4, 0xc0, 0xc7, 0x6a, 0xed, 0xa8,
-	0x0a, 0xe7, 0xc9, 0xd1, 0xbc, 0x23, 0xf8, 0x04, 0x57, 0xfd, 0x0c, 0x48, 0x1b, 0xa5, 0xe3, 0x0e,
-	0x9c, 0xa7, 0xff, 0x41, 0x5a, 0x9a, 0x37, 0xe4, 0xfb, 0xb9, 0xfa, 0xf2, 0xb7, 0x7f, 0x03, 0x00,
-	0x00, 0xff, 0xff, 0xd7, 0xbf, 0x51, 0xe9, 0xff, 0x02, 0x00, 0x00,
+type Config struct {
+	System               *Config_System   `protobuf:"bytes,1,opt,name=system,proto3" json:"system,omitempty"`
+	Dispatch             *Config_Dispatch `protobuf:"bytes,2,opt,name=dispatch,proto3" json:"dispatch,omitempty"`
+	Input                *Config_Input    `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
+func (m *Config) Reset()         { *m = Config{} }
+func (m *Config) String() string { return proto.CompactTextString(m) }
+func (*Config) ProtoMessage()    {}
+func (*Config) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11}
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
+func (m *Config) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Config.Unmarshal(m, b)
+}
+func (m *Config) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Config.Marshal(b, m, deterministic)
+}
+func (m *Config) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Config.Merge(m, src)
+}
+func (m *Config) XXX_Size() int {
+	return xxx_messageInfo_Config.Size(m)
+}
+func (m *Config) XXX_DiscardUnknown() {
+	xxx_messageInfo_Config.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Config proto.InternalMessageInfo
+
+func (m *Config) GetSystem() *Config_System {
+	if m != nil {
+		return m.System
+	}
+	return nil
+}
+
+func (m *Config) GetDispatch() *Config_Dispatch {
+	if m != nil {
+		return m.Dispatch
+	}
+	return nil
+}
+
+func (m *Config) GetInput() *Config_Input {
+	if m != nil {
+		return m.Input
+	}
+	return nil
+}
+
+type Config_System struct {
+	StacksNumber         int32    `protobuf:"varint,1,opt,name=stacks_number,json=stacksNumber,proto3" json:"stacks_number,omitempty"`
+	DefaultStackCapacity int32    `protobuf:"varint,2,opt,name=default_stack_capacity,json=defaultStackCapacity,proto3" json:"default_stack_capacity,omitempty"`
+	MaxStackCapacity     int32    `protobuf:"varint,3,opt,name=max_stack_capacity,json=maxStackCapacity,proto3" json:"max_stack_capacity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Config_System) Reset()         { *m = Config_System{} }
+func (m *Config_System) String() string { return proto.CompactTextString(m) }
+func (*Config_System) ProtoMessage()    {}
+func (*Config_System) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11, 0}
+}
+
+func (m *Config_System) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Config_System.Unmarshal(m, b)
+}
+func (m *Config_System) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Config_System.Marshal(b, m, deterministic)
+}
+func (m *Config_System) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Config_System.Merge(m, src)
+}
+func (m *Config_System) XXX_Size() int {
+	return xxx_messageInfo_Config_System.Size(m)
+}
+func (m *Config_System) XXX_DiscardUnknown() {
+	xxx_messageInfo_Config_System.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Config_System proto.InternalMessageInfo
+
+func (m *Config_System) GetStacksNumber() int32 {
+	if m != nil {
+		return m.StacksNumber
+	}
+	return 0
+}
+
+func (m *Config_System) GetDefaultStackCapacity() int32 {
+	if m != nil {
+		return m.DefaultStackCapacity
+	}
+	return 0
+}
+
+func (m *Config_System) GetMaxStackCapacity() int32 {
+	if m != nil {
+		return m.MaxStackCapacity
+	}
+	return 0
+}
+
+type Config_Dispatch struct {
+	WorkersNumber        int32    `protobuf:"varint,1,opt,name=workers_number,json=workersNumber,proto3" json:"workers_number,omitempty"`
+	DefaultQueueCapacity int32    `protobuf:"varint,2,opt,name=default_queue_capacity,json=defaultQueueCapacity,proto3" json:"default_queue_capacity,omitempty"`
+	MaxQueueCapacity     int32    `protobuf:"varint,3,opt,name=max_queue_capacity,json=maxQueueCapacity,proto3" json:"max_queue_capacity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Config_Dispatch) Reset()         { *m = Config_Dispatch{} }
+func (m *Config_Dispatch) String() string { return proto.CompactTextString(m) }
+func (*Config_Dispatch) ProtoMessage()    {}
+func (*Config_Dispatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11, 1}
+}
+
+func (m *Config_Dispatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Config_Dispatch.Unmarshal(m, b)
+}
+func (m *Config_Dispatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Config_Dispatch.Marshal(b, m, deterministic)
+}
+func (m *Config_Dispatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Config_Dispatch.Merge(m, src)
+}
+func (m *Config_Dispatch) XXX_Size() int {
+	return xxx_messageInfo_Config_Dispatch.Size(m)
+}
+func (m *Config_Dispatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_Config_Dispatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Config_Dispatch proto.InternalMessageInfo
+
+func (m *Config_Dispatch) GetWorkersNumber() int32 {
+	if m != nil {
+		return m.WorkersNumber
+	}
+	return 0
+}
+
+func (m *Config_Dispatch) GetDefaultQueueCapacity() int32 {
+	if m != nil {
+		return m.DefaultQueueCapacity
+	}
+	return 0
+}
+
+func (m *Config_Dispatch) GetMaxQueueCapacity() int32 {
+	if m != nil {
+		return m.MaxQueueCapacity
+	}
+	return 0
+}
+
+type Config_Input struct {
+	DefaultQueueCapacity int32    `protobuf:"varint,1,opt,name=default_queue_capacity,json=defaultQueueCapacity,proto3" json:"default_queue_capacity,omitempty"`
+	MaxQueueCapacity     int32    `protobuf:"varint,2,opt,name=max_queue_capacity,json=maxQueueCapacity,proto3" json:"max_queue_capacity,omitempty"`
+	MaxBulkLimit         int32    `protobuf:"varint,3,opt,name=max_bulk_limit,json=maxBulkLimit,proto3" json:"max_bulk_limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Config_Input) Reset()         { *m = Config_Input{} }
+func (m *Config_Input) String() string { return proto.CompactTextString(m) }
+func (*Config_Input) ProtoMessage()    {}
+func (*Config_Input) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{11, 2}
+}
+
+func (m *Config_Input) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Config_Input.Unmarshal(m, b)
+}
+func (m *Config_Input) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Config_Input.Marshal(b, m, deterministic)
+}
+func (m *Config_Input) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Config_Input.Merge(m, src)
+}
+func (m *Config_Input) XXX_Size() int {
+	return xxx_messageInfo_Config_Input.Size(m)
+}
+func (m *Config_Input) XXX_DiscardUnknown() {
+	xxx_messageInfo_Config_Input.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Config_Input proto.InternalMessageInfo
+
+func (m *Config_Input) GetDefaultQueueCapacity() int32 {
+	if m != nil {
+		return m.DefaultQueueCapacity
+	}
+	return 0
+}
+
+func (m *Config_Input) GetMaxQueueCapacity() int32 {
+	if m != nil {
+		return m.MaxQueueCapacity
+	}
+	return 0
+}
+
+func (m *Config_Input) GetMaxBulkLimit() int32 {
+	if m != nil {
+		return m.MaxBulkLimit
+	}
+	return 0
+}
+
+type GetConfigRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConfigRequest) Reset()         { *m = GetConfigRequest{} }
+func (m *GetConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*GetConfigRequest) ProtoMessage()    {}
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{12}
+}
+
+func (m *GetConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConfigRequest.Unmarshal(m, b)
+}
+func (m *GetConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *GetConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConfigRequest.Merge(m, src)
+}
+func (m *GetConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_GetConfigRequest.Size(m)
+}
+func (m *GetConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConfigRequest proto.InternalMessageInfo
+
+type GetConfigResponse struct {
+	Config               *Config  `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetConfigResponse) Reset()         { *m = GetConfigResponse{} }
+func (m *GetConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*GetConfigResponse) ProtoMessage()    {}
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{13}
+}
+
+func (m *GetConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetConfigResponse.Unmarshal(m, b)
+}
+func (m *GetConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetConfigResponse.Marshal(b, m, deterministic)
+}
+func (m *GetConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetConfigResponse.Merge(m, src)
+}
+func (m *GetConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_GetConfigResponse.Size(m)
+}
+func (m *GetConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetConfigResponse proto.InternalMessageInfo
+
+func (m *GetConfigResponse) GetConfig() *Config {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+// Zero values are left unchanged
+type SetConfigRequest struct {
+	Config               *Config  `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetConfigRequest) Reset()         { *m = SetConfigRequest{} }
+func (m *SetConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*SetConfigRequest) ProtoMessage()    {}
+func (*SetConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{14}
+}
+
+func (m *SetConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetConfigRequest.Unmarshal(m, b)
+}
+func (m *SetConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *SetConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetConfigRequest.Merge(m, src)
+}
+func (m *SetConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_SetConfigRequest.Size(m)
+}
+func (m *SetConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetConfigRequest proto.InternalMessageInfo
+
+func (m *SetConfigRequest) GetConfig() *Config {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type SetConfigResponse struct {
+	Config               *Config  `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetConfigResponse) Reset()         { *m = SetConfigResponse{} }
+func (m *SetConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*SetConfigResponse) ProtoMessage()    {}
+func (*SetConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{15}
+}
+
+func (m *SetConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetConfigResponse.Unmarshal(m, b)
+}
+func (m *SetConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetConfigResponse.Marshal(b, m, deterministic)
+}
+func (m *SetConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetConfigResponse.Merge(m, src)
+}
+func (m *SetConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_SetConfigResponse.Size(m)
+}
+func (m *SetConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetConfigResponse proto.InternalMessageInfo
+
+func (m *SetConfigResponse) GetConfig() *Config {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type Subscriber struct {
+	Id        int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic     string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Group     string `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Peer      string `protobuf:"bytes,4,opt,name=peer,proto3" json:"peer,omitempty"`
+	Principal string `protobuf:"bytes,5,opt,name=principal,proto3" json:"principal,omitempty"`
+	// Unix timestamp
+	ConnectedSince       int64    `protobuf:"varint,6,opt,name=connected_since,json=connectedSince,proto3" json:"connected_since,omitempty"`
+	Sent                 uint64   `protobuf:"varint,7,opt,name=sent,proto3" json:"sent,omitempty"`
+	Dropped              uint64   `protobuf:"varint,8,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Overflows            uint64   `protobuf:"varint,9,opt,name=overflows,proto3" json:"overflows,omitempty"`
+	Buffered             int32    `protobuf:"varint,10,opt,name=buffered,proto3" json:"buffered,omitempty"`
+	Filter               string   `protobuf:"bytes,11,opt,name=filter,proto3" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Subscriber) Reset()         { *m = Subscriber{} }
+func (m *Subscriber) String() string { return proto.CompactTextString(m) }
+func (*Subscriber) ProtoMessage()    {}
+func (*Subscriber) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{16}
+}
+
+func (m *Subscriber) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Subscriber.Unmarshal(m, b)
+}
+func (m *Subscriber) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Subscriber.Marshal(b, m, deterministic)
+}
+func (m *Subscriber) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Subscriber.Merge(m, src)
+}
+func (m *Subscriber) XXX_Size() int {
+	return xxx_messageInfo_Subscriber.Size(m)
+}
+func (m *Subscriber) XXX_DiscardUnknown() {
+	xxx_messageInfo_Subscriber.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Subscriber proto.InternalMessageInfo
+
+func (m *Subscriber) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Subscriber) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *Subscriber) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Subscriber) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *Subscriber) GetPrincipal() string {
+	if m != nil {
+		return m.Principal
+	}
+	return ""
+}
+
+func (m *Subscriber) GetConnectedSince() int64 {
+	if m != nil {
+		return m.ConnectedSince
+	}
+	return 0
+}
+
+func (m *Subscriber) GetSent() uint64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+func (m *Subscriber) GetDropped() uint64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+func (m *Subscriber) GetOverflows() uint64 {
+	if m != nil {
+		return m.Overflows
+	}
+	return 0
+}
+
+func (m *Subscriber) GetBuffered() int32 {
+	if m != nil {
+		return m.Buffered
+	}
+	return 0
+}
+
+func (m *Subscriber) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+// An empty topic lists the subscribers of every topic
+type ListSubscribersRequest struct {
+	Topic                string   `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSubscribersRequest) Reset()         { *m = ListSubscribersRequest{} }
+func (m *ListSubscribersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSubscribersRequest) ProtoMessage()    {}
+func (*ListSubscribersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{17}
+}
+
+func (m *ListSubscribersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSubscribersRequest.Unmarshal(m, b)
+}
+func (m *ListSubscribersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSubscribersRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSubscribersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSubscribersRequest.Merge(m, src)
+}
+func (m *ListSubscribersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSubscribersRequest.Size(m)
+}
+func (m *ListSubscribersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSubscribersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSubscribersRequest proto.InternalMessageInfo
+
+func (m *ListSubscribersRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type ListSubscribersResponse struct {
+	Subscribers          []*Subscriber `protobuf:"bytes,1,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListSubscribersResponse) Reset()         { *m = ListSubscribersResponse{} }
+func (m *ListSubscribersResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSubscribersResponse) ProtoMessage()    {}
+func (*ListSubscribersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{18}
+}
+
+func (m *ListSubscribersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSubscribersResponse.Unmarshal(m, b)
+}
+func (m *ListSubscribersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSubscribersResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSubscribersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSubscribersResponse.Merge(m, src)
+}
+func (m *ListSubscribersResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSubscribersResponse.Size(m)
+}
+func (m *ListSubscribersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSubscribersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSubscribersResponse proto.InternalMessageInfo
+
+func (m *ListSubscribersResponse) GetSubscribers() []*Subscriber {
+	if m != nil {
+		return m.Subscribers
+	}
+	return nil
+}
+
+type DisconnectSubscriberRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DisconnectSubscriberRequest) Reset()         { *m = DisconnectSubscriberRequest{} }
+func (m *DisconnectSubscriberRequest) String() string { return proto.CompactTextString(m) }
+func (*DisconnectSubscriberRequest) ProtoMessage()    {}
+func (*DisconnectSubscriberRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{19}
+}
+
+func (m *DisconnectSubscriberRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DisconnectSubscriberRequest.Unmarshal(m, b)
+}
+func (m *DisconnectSubscriberRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DisconnectSubscriberRequest.Marshal(b, m, deterministic)
+}
+func (m *DisconnectSubscriberRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DisconnectSubscriberRequest.Merge(m, src)
+}
+func (m *DisconnectSubscriberRequest) XXX_Size() int {
+	return xxx_messageInfo_DisconnectSubscriberRequest.Size(m)
+}
+func (m *DisconnectSubscriberRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DisconnectSubscriberRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DisconnectSubscriberRequest proto.InternalMessageInfo
+
+func (m *DisconnectSubscriberRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type DisconnectSubscriberResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DisconnectSubscriberResponse) Reset()         { *m = DisconnectSubscriberResponse{} }
+func (m *DisconnectSubscriberResponse) String() string { return proto.CompactTextString(m) }
+func (*DisconnectSubscriberResponse) ProtoMessage()    {}
+func (*DisconnectSubscriberResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{20}
+}
+
+func (m *DisconnectSubscriberResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DisconnectSubscriberResponse.Unmarshal(m, b)
+}
+func (m *DisconnectSubscriberResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DisconnectSubscriberResponse.Marshal(b, m, deterministic)
+}
+func (m *DisconnectSubscriberResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DisconnectSubscriberResponse.Merge(m, src)
+}
+func (m *DisconnectSubscriberResponse) XXX_Size() int {
+	return xxx_messageInfo_DisconnectSubscriberResponse.Size(m)
+}
+func (m *DisconnectSubscriberResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DisconnectSubscriberResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DisconnectSubscriberResponse proto.InternalMessageInfo
+
+type Calendar struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// IANA time zone of the dates and hours, the zone of the server by default
+	TimeZone string `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	// YYYY-MM-DD dates which are not business days, e.g. the bank holidays
+	ExcludedDates []string `protobuf:"bytes,3,rep,name=excluded_dates,json=excludedDates,proto3" json:"excluded_dates,omitempty"`
+	// English names of the business days of the week, Monday to Friday by default
+	WorkingDays []string `protobuf:"bytes,4,rep,name=working_days,json=workingDays,proto3" json:"working_days,omitempty"`
+	// HH:MM-HH:MM business hours of the working days, open all day if empty
+	WorkingHours         string   `protobuf:"bytes,5,opt,name=working_hours,json=workingHours,proto3" json:"working_hours,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Calendar) Reset()         { *m = Calendar{} }
+func (m *Calendar) String() string { return proto.CompactTextString(m) }
+func (*Calendar) ProtoMessage()    {}
+func (*Calendar) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{21}
+}
+
+func (m *Calendar) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Calendar.Unmarshal(m, b)
+}
+func (m *Calendar) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Calendar.Marshal(b, m, deterministic)
+}
+func (m *Calendar) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Calendar.Merge(m, src)
+}
+func (m *Calendar) XXX_Size() int {
+	return xxx_messageInfo_Calendar.Size(m)
+}
+func (m *Calendar) XXX_DiscardUnknown() {
+	xxx_messageInfo_Calendar.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Calendar proto.InternalMessageInfo
+
+func (m *Calendar) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Calendar) GetTimeZone() string {
+	if m != nil {
+		return m.TimeZone
+	}
+	return ""
+}
+
+func (m *Calendar) GetExcludedDates() []string {
+	if m != nil {
+		return m.ExcludedDates
+	}
+	return nil
+}
+
+func (m *Calendar) GetWorkingDays() []string {
+	if m != nil {
+		return m.WorkingDays
+	}
+	return nil
+}
+
+func (m *Calendar) GetWorkingHours() string {
+	if m != nil {
+		return m.WorkingHours
+	}
+	return ""
+}
+
+// The calendar replaces the one with the same name
+type SetCalendarRequest struct {
+	Calendar             *Calendar `protobuf:"bytes,1,opt,name=calendar,proto3" json:"calendar,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *SetCalendarRequest) Reset()         { *m = SetCalendarRequest{} }
+func (m *SetCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*SetCalendarRequest) ProtoMessage()    {}
+func (*SetCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{22}
+}
+
+func (m *SetCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetCalendarRequest.Unmarshal(m, b)
+}
+func (m *SetCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *SetCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetCalendarRequest.Merge(m, src)
+}
+func (m *SetCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_SetCalendarRequest.Size(m)
+}
+func (m *SetCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetCalendarRequest proto.InternalMessageInfo
+
+func (m *SetCalendarRequest) GetCalendar() *Calendar {
+	if m != nil {
+		return m.Calendar
+	}
+	return nil
+}
+
+type SetCalendarResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetCalendarResponse) Reset()         { *m = SetCalendarResponse{} }
+func (m *SetCalendarResponse) String() string { return proto.CompactTextString(m) }
+func (*SetCalendarResponse) ProtoMessage()    {}
+func (*SetCalendarResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{23}
+}
+
+func (m *SetCalendarResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetCalendarResponse.Unmarshal(m, b)
+}
+func (m *SetCalendarResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetCalendarResponse.Marshal(b, m, deterministic)
+}
+func (m *SetCalendarResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetCalendarResponse.Merge(m, src)
+}
+func (m *SetCalendarResponse) XXX_Size() int {
+	return xxx_messageInfo_SetCalendarResponse.Size(m)
+}
+func (m *SetCalendarResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetCalendarResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetCalendarResponse proto.InternalMessageInfo
+
+type DeleteCalendarRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteCalendarRequest) Reset()         { *m = DeleteCalendarRequest{} }
+func (m *DeleteCalendarRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteCalendarRequest) ProtoMessage()    {}
+func (*DeleteCalendarRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{24}
+}
+
+func (m *DeleteCalendarRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteCalendarRequest.Unmarshal(m, b)
+}
+func (m *DeleteCalendarRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteCalendarRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteCalendarRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteCalendarRequest.Merge(m, src)
+}
+func (m *DeleteCalendarRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteCalendarRequest.Size(m)
+}
+func (m *DeleteCalendarRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteCalendarRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteCalendarRequest proto.InternalMessageInfo
+
+func (m *DeleteCalendarRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type DeleteCalendarResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteCalendarResponse) Reset()         { *m = DeleteCalendarResponse{} }
+func (m *DeleteCalendarResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteCalendarResponse) ProtoMessage()    {}
+func (*DeleteCalendarResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{25}
+}
+
+func (m *DeleteCalendarResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteCalendarResponse.Unmarshal(m, b)
+}
+func (m *DeleteCalendarResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteCalendarResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteCalendarResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteCalendarResponse.Merge(m, src)
+}
+func (m *DeleteCalendarResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteCalendarResponse.Size(m)
+}
+func (m *DeleteCalendarResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteCalendarResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteCalendarResponse proto.InternalMessageInfo
+
+type ListCalendarsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListCalendarsRequest) Reset()         { *m = ListCalendarsRequest{} }
+func (m *ListCalendarsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListCalendarsRequest) ProtoMessage()    {}
+func (*ListCalendarsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{26}
+}
+
+func (m *ListCalendarsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListCalendarsRequest.Unmarshal(m, b)
+}
+func (m *ListCalendarsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListCalendarsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListCalendarsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListCalendarsRequest.Merge(m, src)
+}
+func (m *ListCalendarsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListCalendarsRequest.Size(m)
+}
+func (m *ListCalendarsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListCalendarsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListCalendarsRequest proto.InternalMessageInfo
+
+type ListCalendarsResponse struct {
+	Calendars            []*Calendar `protobuf:"bytes,1,rep,name=calendars,proto3" json:"calendars,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListCalendarsResponse) Reset()         { *m = ListCalendarsResponse{} }
+func (m *ListCalendarsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListCalendarsResponse) ProtoMessage()    {}
+func (*ListCalendarsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{27}
+}
+
+func (m *ListCalendarsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListCalendarsResponse.Unmarshal(m, b)
+}
+func (m *ListCalendarsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListCalendarsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListCalendarsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListCalendarsResponse.Merge(m, src)
+}
+func (m *ListCalendarsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListCalendarsResponse.Size(m)
+}
+func (m *ListCalendarsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListCalendarsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListCalendarsResponse proto.InternalMessageInfo
+
+func (m *ListCalendarsResponse) GetCalendars() []*Calendar {
+	if m != nil {
+		return m.Calendars
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("api.Event_Mode", Event_Mode_name, Event_Mode_value)
+	proto.RegisterEnum("api.Event_MisfirePolicy", Event_MisfirePolicy_name, Event_MisfirePolicy_value)
+	proto.RegisterEnum("api.Event_CalendarPolicy", Event_CalendarPolicy_name, Event_CalendarPolicy_value)
+	proto.RegisterEnum("api.Event_JitterMode", Event_JitterMode_name, Event_JitterMode_value)
+	proto.RegisterType((*Event)(nil), "api.Event")
+	proto.RegisterMapType((map[string]string)(nil), "api.Event.LabelsEntry")
+	proto.RegisterType((*Event_ID)(nil), "api.Event.ID")
+	proto.RegisterType((*ScheduleRequest)(nil), "api.ScheduleRequest")
+	proto.RegisterType((*ScheduleResponse)(nil), "api.ScheduleResponse")
+	proto.RegisterType((*PreviewScheduleRequest)(nil), "api.PreviewScheduleRequest")
+	proto.RegisterType((*PreviewScheduleResponse)(nil), "api.PreviewScheduleResponse")
+	proto.RegisterType((*UnscheduleRequest)(nil), "api.UnscheduleRequest")
+	proto.RegisterType((*UnscheduleResponse)(nil), "api.UnscheduleResponse")
+	proto.RegisterType((*ListEventsRequest)(nil), "api.ListEventsRequest")
+	proto.RegisterType((*ListEventsResponse)(nil), "api.ListEventsResponse")
+	proto.RegisterType((*StreamEventsRequest)(nil), "api.StreamEventsRequest")
+	proto.RegisterType((*StreamEventsResponse)(nil), "api.StreamEventsResponse")
+	proto.RegisterType((*Config)(nil), "api.Config")
+	proto.RegisterType((*Config_System)(nil), "api.Config.System")
+	proto.RegisterType((*Config_Dispatch)(nil), "api.Config.Dispatch")
+	proto.RegisterType((*Config_Input)(nil), "api.Config.Input")
+	proto.RegisterType((*GetConfigRequest)(nil), "api.GetConfigRequest")
+	proto.RegisterType((*GetConfigResponse)(nil), "api.GetConfigResponse")
+	proto.RegisterType((*SetConfigRequest)(nil), "api.SetConfigRequest")
+	proto.RegisterType((*SetConfigResponse)(nil), "api.SetConfigResponse")
+	proto.RegisterType((*Subscriber)(nil), "api.Subscriber")
+	proto.RegisterType((*ListSubscribersRequest)(nil), "api.ListSubscribersRequest")
+	proto.RegisterType((*ListSubscribersResponse)(nil), "api.ListSubscribersResponse")
+	proto.RegisterType((*DisconnectSubscriberRequest)(nil), "api.DisconnectSubscriberRequest")
+	proto.RegisterType((*DisconnectSubscriberResponse)(nil), "api.DisconnectSubscriberResponse")
+	proto.RegisterType((*Calendar)(nil), "api.Calendar")
+	proto.RegisterType((*SetCalendarRequest)(nil), "api.SetCalendarRequest")
+	proto.RegisterType((*SetCalendarResponse)(nil), "api.SetCalendarResponse")
+	proto.RegisterType((*DeleteCalendarRequest)(nil), "api.DeleteCalendarRequest")
+	proto.RegisterType((*DeleteCalendarResponse)(nil), "api.DeleteCalendarResponse")
+	proto.RegisterType((*ListCalendarsRequest)(nil), "api.ListCalendarsRequest")
+	proto.RegisterType((*ListCalendarsResponse)(nil), "api.ListCalendarsResponse")
+}
+
+func init() {
+	proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c)
+}
+
+var fileDescriptor_00212fb1f9d3bf1c = []byte{
+	// 1746 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0x5f, 0x73, 0xdb, 0xc6,
+	0x11, 0x17, 0xf8, 0xcf, 0xe4, 0x42, 0x24, 0xa1, 0x33, 0x25, 0xc1, 0xb0, 0xd3, 0x61, 0xe0, 0x66,
+	0x22, 0x27, 0xad, 0xc6, 0x61, 0x3a, 0x89, 0xd2, 0xc9, 0x8c, 0x87, 0x16, 0xe9, 0x88, 0x09, 0x25,
+	0xab, 0xa0, 0xd2, 0x87, 0xce, 0x74, 0x30, 0x10, 0x70, 0xb2, 0x10, 0x81, 0x00, 0x72, 0x77, 0x90,
+	0xa5, 0x4e, 0xbf, 0x40, 0x1f, 0xfa, 0xd0, 0x87, 0x7e, 0x82, 0x3e, 0xb5, 0x5f, 0xa6, 0x9f, 0xa1,
+	0xdf, 0xa4, 0x73, 0x87, 0x03, 0x08, 0x82, 0xa8, 0x9d, 0xf4, 0x0d, 0xfb, 0xfb, 0xed, 0xed, 0xee,
+	0xed, 0xde, 0xed, 0x2d, 0x09, 0x1d, 0x27, 0xf6, 0x0f, 0x63, 0x12, 0xb1, 0x08, 0xd5, 0x9d, 0xd8,
+	0x37, 0xff, 0xd9, 0x86, 0xe6, 0xf4, 0x16, 0x87, 0x0c, 0xf5, 0xa0, 0xe6, 0x7b, 0xba, 0x32, 0x54,
+	0x0e, 0x3a, 0x56, 0xcd, 0xf7, 0xd0, 0xc7, 0xd0, 0x77, 0x49, 0x14, 0xda, 0xf8, 0x2e, 0x26, 0x98,
+	0x52, 0x3f, 0x0a, 0xf5, 0x9a, 0x20, 0x7b, 0x1c, 0x9e, 0xe6, 0x28, 0xfa, 0x04, 0x76, 0xe8, 0x75,
+	0x94, 0x04, 0x9e, 0x8d, 0xef, 0xb0, 0x9b, 0x30, 0x6c, 0x3b, 0x4c, 0xaf, 0x0f, 0x95, 0x83, 0xba,
+	0xd5, 0x4f, 0x89, 0x69, 0x8a, 0x8f, 0x19, 0x7a, 0x0a, 0x8d, 0x65, 0xe4, 0x61, 0xbd, 0x31, 0x54,
+	0x0e, 0x7a, 0xa3, 0xfe, 0x21, 0x8f, 0x46, 0xb8, 0x3f, 0x3c, 0x8d, 0x3c, 0x6c, 0x09, 0x12, 0x0d,
+	0xa0, 0xc9, 0xa2, 0xd8, 0x77, 0xf5, 0xa6, 0xf0, 0x97, 0x0a, 0x48, 0x87, 0x07, 0xb1, 0x73, 0x1f,
+	0x44, 0x8e, 0xa7, 0xb7, 0x86, 0xca, 0xc1, 0xb6, 0x95, 0x89, 0xe8, 0x10, 0x5a, 0x81, 0x73, 0x89,
+	0x03, 0xaa, 0x3f, 0x18, 0xd6, 0x0f, 0xd4, 0xd1, 0x5e, 0xc1, 0xec, 0x5c, 0x10, 0xd3, 0x90, 0x91,
+	0x7b, 0x4b, 0x6a, 0xa1, 0xc7, 0xd0, 0x61, 0xfe, 0x12, 0xdb, 0x7f, 0x8a, 0x42, 0xac, 0xb7, 0x85,
+	0x8f, 0x36, 0x07, 0xfe, 0x10, 0x85, 0x18, 0x3d, 0x82, 0x36, 0x65, 0x0e, 0x61, 0x7c, 0x13, 0x1d,
+	0xb1, 0x89, 0x07, 0x42, 0x1e, 0x33, 0xb4, 0x0b, 0x2d, 0x1c, 0x7a, 0x9c, 0x00, 0x41, 0x34, 0x71,
+	0xe8, 0x8d, 0x19, 0x4f, 0xd4, 0xd2, 0xb9, 0xb3, 0x23, 0xd7, 0x4d, 0x08, 0xc1, 0xa1, 0x8b, 0xa9,
+	0xae, 0x0e, 0x95, 0x83, 0xae, 0xd5, 0x5b, 0x3a, 0x77, 0xaf, 0x57, 0x28, 0xfa, 0x00, 0xe0, 0xca,
+	0x27, 0xd8, 0x76, 0xa3, 0x24, 0x64, 0xfa, 0xb6, 0xd0, 0xe9, 0x70, 0xe4, 0x98, 0x03, 0xe8, 0x05,
+	0xf4, 0x96, 0x3e, 0x15, 0x1a, 0x71, 0x14, 0xf8, 0xee, 0xbd, 0xde, 0x15, 0x59, 0xd2, 0x8b, 0x59,
+	0x4a, 0x15, 0xce, 0x05, 0x6f, 0x75, 0x97, 0x45, 0x11, 0x99, 0xd0, 0x0d, 0x1c, 0xca, 0x6c, 0x0e,
+	0x89, 0x30, 0x7b, 0x22, 0x4c, 0x95, 0x83, 0xaf, 0x38, 0x36, 0x66, 0xe8, 0x19, 0x68, 0x7e, 0xc8,
+	0x30, 0xb9, 0x75, 0x02, 0x9b, 0x62, 0x37, 0x0a, 0x3d, 0xaa, 0xf7, 0xd3, 0x5a, 0x65, 0xf8, 0x22,
+	0x85, 0xd1, 0x1e, 0xb4, 0x9c, 0xd0, 0xbd, 0x8e, 0x88, 0xae, 0x09, 0x05, 0x29, 0xf1, 0xf2, 0x10,
+	0x92, 0x04, 0x58, 0xdf, 0x49, 0xcb, 0x23, 0x04, 0x64, 0x40, 0xdb, 0x75, 0x02, 0x1c, 0x7a, 0x0e,
+	0xd1, 0x51, 0x9a, 0xd3, 0x4c, 0x46, 0x2f, 0xa1, 0x9f, 0x7d, 0x67, 0x5b, 0x7b, 0x28, 0xb6, 0xf6,
+	0xa8, 0xb0, 0xb5, 0x63, 0xa9, 0x21, 0xf7, 0xd6, 0x73, 0xd7, 0x64, 0x5e, 0xb4, 0x1f, 0x7c, 0xc6,
+	0x30, 0xb1, 0x97, 0x54, 0x1f, 0x88, 0x80, 0xda, 0x29, 0x70, 0x4a, 0xd1, 0x17, 0xa0, 0x66, 0x24,
+	0x3f, 0x5d, 0xbb, 0xc2, 0xf8, 0x6e, 0xc1, 0xf8, 0xb7, 0xa9, 0x26, 0x3f, 0x63, 0xf0, 0x43, 0xfe,
+	0x6d, 0x0c, 0xa0, 0x36, 0x9b, 0x94, 0x4f, 0xbe, 0xf1, 0x15, 0xa8, 0x85, 0x63, 0x83, 0x34, 0xa8,
+	0xdf, 0xe0, 0x7b, 0xc9, 0xf3, 0x4f, 0x9e, 0x81, 0x5b, 0x27, 0x48, 0xb0, 0xbc, 0x10, 0xa9, 0xf0,
+	0xdb, 0xda, 0x91, 0x62, 0x1e, 0x41, 0x83, 0x1b, 0x46, 0x5d, 0xe8, 0x5c, 0xcc, 0x4e, 0xa7, 0x8b,
+	0x8b, 0xf1, 0xe9, 0xb9, 0xb6, 0x85, 0xda, 0xd0, 0x38, 0xb6, 0x5e, 0x9f, 0x69, 0x0a, 0xda, 0x86,
+	0xf6, 0xec, 0xec, 0x62, 0x6a, 0xfd, 0x7e, 0x3c, 0xd7, 0x6a, 0xa8, 0x03, 0x4d, 0xcb, 0xfa, 0x7e,
+	0x3e, 0xd5, 0xea, 0xe6, 0x17, 0xd0, 0x5d, 0x2b, 0x2e, 0x37, 0xf1, 0x6a, 0x66, 0x4d, 0xed, 0xd7,
+	0x67, 0xc7, 0x53, 0x6d, 0x8b, 0x2f, 0x14, 0xe2, 0x78, 0x3e, 0xd7, 0x14, 0x6e, 0x70, 0xf1, 0xdd,
+	0xec, 0x5c, 0xab, 0x99, 0x5f, 0x42, 0x6f, 0x3d, 0x73, 0x68, 0x07, 0xba, 0xc7, 0xe3, 0xf9, 0xf4,
+	0x6c, 0x32, 0xb6, 0x6c, 0xa1, 0xb4, 0x85, 0x10, 0xf4, 0x56, 0xd0, 0xc9, 0xec, 0xd5, 0x85, 0xa6,
+	0x98, 0xcf, 0x01, 0x56, 0x59, 0x41, 0x7d, 0x50, 0xbf, 0x9d, 0x5d, 0x5c, 0x4c, 0x2d, 0xfb, 0x64,
+	0xbc, 0x38, 0xd1, 0xb6, 0xb8, 0x15, 0x09, 0x58, 0xe3, 0xb3, 0xc9, 0xeb, 0x53, 0x4d, 0x31, 0x3f,
+	0x87, 0xfe, 0xc2, 0xbd, 0xc6, 0x5e, 0x12, 0x60, 0x0b, 0xff, 0x98, 0x60, 0xca, 0xd0, 0x10, 0x9a,
+	0x98, 0x27, 0x58, 0x64, 0x47, 0x1d, 0xc1, 0x2a, 0xe5, 0x56, 0x4a, 0x98, 0x9f, 0x81, 0xb6, 0x5a,
+	0x44, 0xe3, 0x28, 0xa4, 0x18, 0x7d, 0x90, 0x27, 0x5c, 0x1d, 0x75, 0x0b, 0x55, 0x9a, 0x4d, 0x78,
+	0xfe, 0xcd, 0x73, 0xd8, 0x3b, 0x27, 0xf8, 0xd6, 0xc7, 0x6f, 0x7f, 0xb6, 0x3b, 0x5e, 0x9a, 0xf4,
+	0x7a, 0xd5, 0xc4, 0xf5, 0x4a, 0x05, 0xf3, 0x2f, 0x0a, 0xec, 0x6f, 0x98, 0x94, 0xc1, 0xa4, 0xc5,
+	0x94, 0xf1, 0xb4, 0xad, 0x54, 0xe0, 0x28, 0x26, 0x24, 0x22, 0x59, 0x89, 0x85, 0x80, 0x86, 0xa0,
+	0x7a, 0x98, 0xba, 0xc4, 0x8f, 0x19, 0xef, 0x87, 0x75, 0xc1, 0x15, 0x21, 0xae, 0x51, 0x6c, 0x04,
+	0x8d, 0x61, 0x9d, 0xdf, 0xc0, 0x02, 0x64, 0x8e, 0x60, 0xe7, 0xfb, 0x90, 0x96, 0x36, 0xf6, 0x9e,
+	0x8c, 0x0c, 0x00, 0x15, 0xd7, 0xa4, 0x91, 0x9b, 0xcf, 0x60, 0x67, 0xee, 0x53, 0x26, 0x34, 0x69,
+	0x66, 0x29, 0x6f, 0x9e, 0x4a, 0xa1, 0x79, 0x9a, 0x47, 0x80, 0x8a, 0xaa, 0x72, 0xeb, 0x26, 0xb4,
+	0x44, 0xd6, 0xa8, 0xae, 0x0c, 0xeb, 0xa5, 0x7c, 0x4a, 0xc6, 0xfc, 0x33, 0x3c, 0x5c, 0x30, 0x82,
+	0x9d, 0xe5, 0x4f, 0x70, 0xc3, 0xd1, 0x37, 0x24, 0x4a, 0xe2, 0x2c, 0x6b, 0x42, 0xe0, 0x8d, 0xe4,
+	0xca, 0x0f, 0x18, 0x26, 0x32, 0x61, 0x52, 0x42, 0x1f, 0xc2, 0x36, 0xc1, 0x34, 0x59, 0x62, 0x9b,
+	0x45, 0x37, 0x38, 0x14, 0x8f, 0x42, 0xc7, 0x52, 0x53, 0xec, 0x82, 0x43, 0xe6, 0x5b, 0x18, 0xac,
+	0x7b, 0x97, 0x91, 0xbf, 0xff, 0x20, 0x18, 0xd0, 0xa6, 0x3c, 0xd6, 0xd0, 0x4d, 0xaf, 0x69, 0xc3,
+	0xca, 0xe5, 0x0d, 0xc7, 0xf5, 0x4d, 0xc7, 0xff, 0x6e, 0x40, 0xeb, 0x38, 0x0a, 0xaf, 0xfc, 0x37,
+	0xe8, 0x13, 0x68, 0xd1, 0x7b, 0xca, 0xf0, 0x52, 0x3a, 0x43, 0xc2, 0x59, 0x4a, 0x1e, 0x2e, 0x04,
+	0x63, 0x49, 0x0d, 0xf4, 0x1c, 0xda, 0x9e, 0x4f, 0x63, 0x87, 0xb9, 0xd7, 0xc2, 0xab, 0x3a, 0x1a,
+	0x14, 0xb5, 0x27, 0x92, 0xb3, 0x72, 0x2d, 0xf4, 0x31, 0x34, 0xfd, 0x30, 0x4e, 0xd2, 0x17, 0x53,
+	0x1d, 0xed, 0x14, 0xd5, 0x67, 0x9c, 0xb0, 0x52, 0xde, 0xf8, 0x9b, 0x02, 0xad, 0xd4, 0x1b, 0x7a,
+	0x0a, 0x5d, 0xca, 0x1c, 0xf7, 0x86, 0xda, 0x61, 0xb2, 0xbc, 0xc4, 0x44, 0x04, 0xd6, 0xb4, 0xb6,
+	0x53, 0xf0, 0x4c, 0x60, 0xe8, 0x37, 0xb0, 0xe7, 0xe1, 0x2b, 0x27, 0x09, 0x98, 0x2d, 0x70, 0xdb,
+	0x75, 0x62, 0xc7, 0xf5, 0xd9, 0xbd, 0x08, 0xac, 0x69, 0x0d, 0x24, 0xbb, 0xe0, 0xe4, 0xb1, 0xe4,
+	0xd0, 0xaf, 0x00, 0xf1, 0xc7, 0xac, 0xb4, 0xa2, 0x2e, 0x56, 0x68, 0x4b, 0xe7, 0x6e, 0x4d, 0xdb,
+	0xf8, 0xbb, 0x02, 0xed, 0x6c, 0x4f, 0xe8, 0x23, 0xe8, 0xbd, 0x8d, 0xc8, 0x0d, 0x26, 0xa5, 0xb0,
+	0xba, 0x12, 0xdd, 0x8c, 0xeb, 0xc7, 0x04, 0x27, 0xf8, 0x7f, 0xc5, 0xf5, 0x3b, 0x4e, 0x96, 0xe3,
+	0x2a, 0xad, 0x58, 0xc5, 0xb5, 0xa6, 0xcd, 0x73, 0xd5, 0x14, 0xc9, 0x7b, 0x87, 0x37, 0xe5, 0x67,
+	0x7b, 0xab, 0x55, 0x7b, 0x43, 0xbf, 0x04, 0xfe, 0xd2, 0xdb, 0x97, 0x49, 0x70, 0x63, 0x07, 0xfe,
+	0xd2, 0x67, 0x32, 0xae, 0xed, 0xa5, 0x73, 0xf7, 0x32, 0x09, 0x6e, 0xe6, 0x1c, 0x33, 0x11, 0x68,
+	0xdf, 0x60, 0x96, 0x56, 0x56, 0xde, 0x22, 0xf3, 0x08, 0x76, 0x0a, 0x98, 0x3c, 0xdb, 0x4f, 0xa1,
+	0xe5, 0x0a, 0x44, 0x9e, 0x37, 0xb5, 0x70, 0x24, 0x2c, 0x49, 0x99, 0x5f, 0x82, 0xb6, 0x28, 0x59,
+	0xfb, 0x69, 0x0b, 0x8f, 0x60, 0x67, 0xf1, 0xff, 0xb9, 0xfc, 0x47, 0x0d, 0x60, 0x91, 0x5c, 0xf2,
+	0x5e, 0xc7, 0xeb, 0xb8, 0x7a, 0x35, 0xeb, 0x62, 0x5e, 0xcc, 0x3b, 0x42, 0xad, 0xb2, 0x23, 0xd4,
+	0x8b, 0x1d, 0x01, 0x41, 0x23, 0xc6, 0x98, 0xc8, 0x1b, 0x2f, 0xbe, 0xd1, 0x13, 0xe8, 0xc4, 0xc4,
+	0x0f, 0x5d, 0x3f, 0x76, 0x02, 0x39, 0xf9, 0xad, 0x00, 0x31, 0x8d, 0x46, 0x61, 0x88, 0x5d, 0x86,
+	0x3d, 0x9b, 0xfa, 0xfc, 0x56, 0xb7, 0x84, 0xeb, 0x5e, 0x0e, 0x2f, 0x38, 0xca, 0x4d, 0x53, 0xde,
+	0x18, 0x1e, 0x88, 0x3b, 0x2f, 0xbe, 0xf9, 0xe8, 0xe8, 0x91, 0x28, 0x8e, 0xb1, 0x27, 0xc6, 0xbd,
+	0x86, 0x95, 0x89, 0xdc, 0x69, 0x74, 0x8b, 0xc9, 0x55, 0x10, 0xbd, 0xa5, 0x62, 0xdc, 0x6b, 0x58,
+	0x2b, 0x80, 0xf7, 0x90, 0xcb, 0xe4, 0xea, 0x0a, 0x13, 0xec, 0x89, 0x91, 0xaf, 0x69, 0xe5, 0x72,
+	0xa1, 0xa9, 0xa9, 0xc5, 0xa6, 0x66, 0x1e, 0xc2, 0x1e, 0xef, 0xb4, 0xab, 0x44, 0xbd, 0xa7, 0x33,
+	0xcf, 0x61, 0x7f, 0x43, 0x5f, 0x56, 0xe5, 0x33, 0x50, 0xe9, 0x0a, 0x96, 0x3d, 0x3a, 0x9d, 0x99,
+	0x57, 0xea, 0x56, 0x51, 0xc7, 0xfc, 0x35, 0x3c, 0x9e, 0xf8, 0x54, 0xa6, 0xa4, 0xa0, 0x24, 0x43,
+	0x28, 0xd5, 0xcc, 0xfc, 0x05, 0x3c, 0xa9, 0x56, 0x97, 0x2f, 0xcc, 0xbf, 0x14, 0x68, 0x67, 0xd3,
+	0x05, 0xcf, 0x6c, 0xe8, 0x2c, 0xb1, 0x0c, 0x5f, 0x7c, 0xaf, 0x8f, 0xd2, 0xb5, 0xd2, 0x28, 0xfd,
+	0x11, 0xf4, 0xf0, 0x9d, 0x1b, 0x24, 0x1e, 0xf6, 0x6c, 0xcf, 0x61, 0x98, 0xea, 0xf5, 0x61, 0xfd,
+	0xa0, 0x63, 0x75, 0x33, 0x74, 0xc2, 0x41, 0xde, 0x8d, 0x79, 0x87, 0xf0, 0xc3, 0x37, 0xb6, 0xe7,
+	0xdc, 0xa7, 0x6f, 0x66, 0xc7, 0x52, 0x25, 0x36, 0x71, 0xee, 0x29, 0x6f, 0x78, 0x99, 0xca, 0x75,
+	0x94, 0x10, 0x2a, 0xcf, 0x47, 0xb6, 0xee, 0x84, 0x63, 0xe6, 0x0b, 0x40, 0xfc, 0x64, 0xcb, 0x70,
+	0xb3, 0x2d, 0x3f, 0x2b, 0xcc, 0xa5, 0xc5, 0xf7, 0x35, 0xd7, 0xcb, 0x69, 0x73, 0x17, 0x1e, 0xae,
+	0x19, 0x90, 0x49, 0xf8, 0x14, 0x76, 0x27, 0x38, 0xc0, 0x0c, 0x97, 0x4d, 0x57, 0x24, 0xc4, 0xd4,
+	0x61, 0xaf, 0xac, 0x2c, 0xcd, 0xec, 0xc1, 0x80, 0x17, 0x3a, 0xc3, 0xb3, 0x63, 0x61, 0x4e, 0x60,
+	0xb7, 0x84, 0xcb, 0xf2, 0x7f, 0x0a, 0x9d, 0x2c, 0xb4, 0xac, 0xf8, 0xa5, 0xd0, 0x57, 0xfc, 0xe8,
+	0x3f, 0x35, 0xe8, 0x64, 0xa3, 0x0d, 0x41, 0x5f, 0x41, 0x3b, 0x13, 0x50, 0xfa, 0x00, 0x95, 0x26,
+	0x29, 0x63, 0xb7, 0x84, 0xca, 0x20, 0xb7, 0xd0, 0x0b, 0x80, 0xd5, 0xa8, 0x81, 0xd2, 0x9f, 0x52,
+	0x1b, 0xf3, 0x8a, 0xb1, 0xbf, 0x81, 0xe7, 0x06, 0xce, 0xa0, 0x5f, 0x1a, 0xb5, 0xd0, 0x63, 0xa1,
+	0x5d, 0x3d, 0xd3, 0x19, 0x4f, 0xaa, 0xc9, 0xdc, 0xde, 0x37, 0xb0, 0x5d, 0x1c, 0x01, 0x50, 0xfa,
+	0x73, 0xa8, 0x62, 0x26, 0x31, 0x1e, 0x55, 0x30, 0x99, 0x99, 0xe7, 0x0a, 0xdf, 0xd9, 0x6a, 0x06,
+	0x92, 0x3b, 0xdb, 0x98, 0x9f, 0x8c, 0xfd, 0x0d, 0x3c, 0x33, 0x31, 0xfa, 0x6b, 0x03, 0x9a, 0x63,
+	0x6f, 0xe9, 0x87, 0xe8, 0x6b, 0xe8, 0xe4, 0x7d, 0x1b, 0xa5, 0xa9, 0x2c, 0xf7, 0x76, 0x63, 0xaf,
+	0x0c, 0xe7, 0x3b, 0xfa, 0x1a, 0x3a, 0x8b, 0xd2, 0xea, 0x45, 0xf5, 0xea, 0x45, 0xc5, 0xea, 0x33,
+	0xe8, 0x97, 0x1a, 0x86, 0xcc, 0x6f, 0x75, 0xdb, 0x31, 0x9e, 0x54, 0x93, 0xb9, 0xbd, 0x3f, 0xc2,
+	0xa0, 0xaa, 0x07, 0xa0, 0xa1, 0x58, 0xf7, 0x8e, 0x6e, 0x62, 0x7c, 0xf8, 0x0e, 0x8d, 0xdc, 0xfc,
+	0x4b, 0x50, 0x0b, 0x97, 0x0a, 0xed, 0xe7, 0xfb, 0x5a, 0xbf, 0x4c, 0x86, 0xbe, 0x49, 0xe4, 0x36,
+	0xbe, 0x83, 0xde, 0xfa, 0xa5, 0x42, 0x46, 0xea, 0xba, 0xea, 0x5a, 0x1a, 0x8f, 0x2b, 0xb9, 0xdc,
+	0xd8, 0x09, 0x74, 0xd7, 0xee, 0x1b, 0x7a, 0x94, 0x27, 0xa8, 0x7c, 0x37, 0x0d, 0xa3, 0x8a, 0xca,
+	0x2c, 0x5d, 0xb6, 0xc4, 0xff, 0x28, 0x9f, 0xff, 0x77, 0x00, 0xa9, 0x2a, 0x51, 0x78, 0x54, 0x11,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
 const _ = grpc.SupportPackageIsVersion6
 
 // SchedulerClient is the client API for Scheduler service.
@@ -453,7 +1962,9 @@ const _ = grpc.SupportPackageIsVersion6
 type SchedulerClient interface {
 	Schedule(ctx context.Context, in *ScheduleRequest, opts ...grpc.CallOption) (*ScheduleResponse, error)
 	Unschedule(ctx context.Context, in *UnscheduleRequest, opts ...grpc.CallOption) (*UnscheduleResponse, error)
+	PreviewSchedule(ctx context.Context, in *PreviewScheduleRequest, opts ...grpc.CallOption) (*PreviewScheduleResponse, error)
 	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Scheduler_StreamEventsClient, error)
+	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
 }
 
 type schedulerClient struct {
@@ -482,6 +1993,15 @@ func (c *schedulerClient) Unschedule(ctx context.Context, in *UnscheduleRequest,
 	return out, nil
 }
 
+func (c *schedulerClient) PreviewSchedule(ctx context.Context, in *PreviewScheduleRequest, opts ...grpc.CallOption) (*PreviewScheduleResponse, error) {
+	out := new(PreviewScheduleResponse)
+	err := c.cc.Invoke(ctx, "/api.Scheduler/PreviewSchedule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *schedulerClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Scheduler_StreamEventsClient, error) {
 	stream, err := c.cc.NewStream(ctx, &_Scheduler_serviceDesc.Streams[0], "/api.Scheduler/StreamEvents", opts...)
 	if err != nil {
@@ -514,11 +2034,22 @@ func (x *schedulerStreamEventsClient) Recv() (*StreamEventsResponse, error) {
 	return m, nil
 }
 
+func (c *schedulerClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, "/api.Scheduler/ListEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SchedulerServer is the server API for Scheduler service.
 type SchedulerServer interface {
 	Schedule(context.Context, *ScheduleRequest) (*ScheduleResponse, error)
 	Unschedule(context.Context, *UnscheduleRequest) (*UnscheduleResponse, error)
+	PreviewSchedule(context.Context, *PreviewScheduleRequest) (*PreviewScheduleResponse, error)
 	StreamEvents(*StreamEventsRequest, Scheduler_StreamEventsServer) error
+	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
 }
 
 // UnimplementedSchedulerServer can be embedded to have forward compatible implementations.
@@ -531,9 +2062,15 @@ func (*UnimplementedSchedulerServer) Schedule(ctx context.Context, req *Schedule
 func (*UnimplementedSchedulerServer) Unschedule(ctx context.Context, req *UnscheduleRequest) (*UnscheduleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Unschedule not implemented")
 }
+func (*UnimplementedSchedulerServer) PreviewSchedule(ctx context.Context, req *PreviewScheduleRequest) (*PreviewScheduleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewSchedule not implemented")
+}
 func (*UnimplementedSchedulerServer) StreamEvents(req *StreamEventsRequest, srv Scheduler_StreamEventsServer) error {
 	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
 }
+func (*UnimplementedSchedulerServer) ListEvents(ctx context.Context, req *ListEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
+}
 
 func RegisterSchedulerServer(s *grpc.Server, srv SchedulerServer) {
 	s.RegisterService(&_Scheduler_serviceDesc, srv)
@@ -575,6 +2112,24 @@ func _Scheduler_Unschedule_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Scheduler_PreviewSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).PreviewSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Scheduler/PreviewSchedule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).PreviewSchedule(ctx, req.(*PreviewScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Scheduler_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(StreamEventsRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -596,6 +2151,24 @@ func (x *schedulerStreamEventsServer) Send(m *StreamEventsResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Scheduler_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulerServer).ListEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Scheduler/ListEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulerServer).ListEvents(ctx, req.(*ListEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Scheduler_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "api.Scheduler",
 	HandlerType: (*SchedulerServer)(nil),
@@ -608,6 +2181,14 @@ var _Scheduler_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Unschedule",
 			Handler:    _Scheduler_Unschedule_Handler,
 		},
+		{
+			MethodName: "PreviewSchedule",
+			Handler:    _Scheduler_PreviewSchedule_Handler,
+		},
+		{
+			MethodName: "ListEvents",
+			Handler:    _Scheduler_ListEvents_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -618,3 +2199,291 @@ var _Scheduler_serviceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "api.proto",
 }
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error)
+	ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersResponse, error)
+	DisconnectSubscriber(ctx context.Context, in *DisconnectSubscriberRequest, opts ...grpc.CallOption) (*DisconnectSubscriberResponse, error)
+	SetCalendar(ctx context.Context, in *SetCalendarRequest, opts ...grpc.CallOption) (*SetCalendarResponse, error)
+	DeleteCalendar(ctx context.Context, in *DeleteCalendarRequest, opts ...grpc.CallOption) (*DeleteCalendarResponse, error)
+	ListCalendars(ctx context.Context, in *ListCalendarsRequest, opts ...grpc.CallOption) (*ListCalendarsResponse, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/GetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetConfig(ctx context.Context, in *SetConfigRequest, opts ...grpc.CallOption) (*SetConfigResponse, error) {
+	out := new(SetConfigResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/SetConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersResponse, error) {
+	out := new(ListSubscribersResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/ListSubscribers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DisconnectSubscriber(ctx context.Context, in *DisconnectSubscriberRequest, opts ...grpc.CallOption) (*DisconnectSubscriberResponse, error) {
+	out := new(DisconnectSubscriberResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/DisconnectSubscriber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetCalendar(ctx context.Context, in *SetCalendarRequest, opts ...grpc.CallOption) (*SetCalendarResponse, error) {
+	out := new(SetCalendarResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/SetCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DeleteCalendar(ctx context.Context, in *DeleteCalendarRequest, opts ...grpc.CallOption) (*DeleteCalendarResponse, error) {
+	out := new(DeleteCalendarResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/DeleteCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListCalendars(ctx context.Context, in *ListCalendarsRequest, opts ...grpc.CallOption) (*ListCalendarsResponse, error) {
+	out := new(ListCalendarsResponse)
+	err := c.cc.Invoke(ctx, "/api.Admin/ListCalendars", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	SetConfig(context.Context, *SetConfigRequest) (*SetConfigResponse, error)
+	ListSubscribers(context.Context, *ListSubscribersRequest) (*ListSubscribersResponse, error)
+	DisconnectSubscriber(context.Context, *DisconnectSubscriberRequest) (*DisconnectSubscriberResponse, error)
+	SetCalendar(context.Context, *SetCalendarRequest) (*SetCalendarResponse, error)
+	DeleteCalendar(context.Context, *DeleteCalendarRequest) (*DeleteCalendarResponse, error)
+	ListCalendars(context.Context, *ListCalendarsRequest) (*ListCalendarsResponse, error)
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (*UnimplementedAdminServer) GetConfig(ctx context.Context, req *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (*UnimplementedAdminServer) SetConfig(ctx context.Context, req *SetConfigRequest) (*SetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (*UnimplementedAdminServer) ListSubscribers(ctx context.Context, req *ListSubscribersRequest) (*ListSubscribersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscribers not implemented")
+}
+func (*UnimplementedAdminServer) DisconnectSubscriber(ctx context.Context, req *DisconnectSubscriberRequest) (*DisconnectSubscriberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisconnectSubscriber not implemented")
+}
+func (*UnimplementedAdminServer) SetCalendar(ctx context.Context, req *SetCalendarRequest) (*SetCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCalendar not implemented")
+}
+func (*UnimplementedAdminServer) DeleteCalendar(ctx context.Context, req *DeleteCalendarRequest) (*DeleteCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCalendar not implemented")
+}
+func (*UnimplementedAdminServer) ListCalendars(ctx context.Context, req *ListCalendarsRequest) (*ListCalendarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCalendars not implemented")
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/GetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetConfig(ctx, req.(*SetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListSubscribers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscribersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSubscribers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/ListSubscribers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSubscribers(ctx, req.(*ListSubscribersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DisconnectSubscriber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectSubscriberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DisconnectSubscriber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/DisconnectSubscriber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DisconnectSubscriber(ctx, req.(*DisconnectSubscriberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/SetCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetCalendar(ctx, req.(*SetCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DeleteCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DeleteCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/DeleteCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DeleteCalendar(ctx, req.(*DeleteCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCalendarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.Admin/ListCalendars",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListCalendars(ctx, req.(*ListCalendarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "api.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _Admin_GetConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _Admin_SetConfig_Handler,
+		},
+		{
+			MethodName: "ListSubscribers",
+			Handler:    _Admin_ListSubscribers_Handler,
+		},
+		{
+			MethodName: "DisconnectSubscriber",
+			Handler:    _Admin_DisconnectSubscriber_Handler,
+		},
+		{
+			MethodName: "SetCalendar",
+			Handler:    _Admin_SetCalendar_Handler,
+		},
+		{
+			MethodName: "DeleteCalendar",
+			Handler:    _Admin_DeleteCalendar_Handler,
+		},
+		{
+			MethodName: "ListCalendars",
+			Handler:    _Admin_ListCalendars_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
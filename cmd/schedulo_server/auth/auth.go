@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+	ErrUnknownKey      = errors.New("unknown API key")
+)
+
+// Principal is the identity of an authenticated caller
+type Principal string
+
+type principalKey struct{}
+
+// Authenticator resolves the principal owning a bearer token
+type Authenticator interface {
+	Authenticate(token string) (Principal, error)
+}
+
+// Chain tries each authenticator in turn, the first one accepting the token wins
+type Chain []Authenticator
+
+func (c Chain) Authenticate(token string) (Principal, error) {
+	for _, a := range c {
+		if p, err := a.Authenticate(token); err == nil {
+			return p, nil
+		}
+	}
+
+	return "", ErrUnauthenticated
+}
+
+// StaticKeys authenticates API keys, it maps each key to its principal
+type StaticKeys map[string]Principal
+
+// Authenticate compares the token with every key in constant time, so that the keys cannot be guessed
+// from the time taken to reject a token
+func (k StaticKeys) Authenticate(token string) (Principal, error) {
+	var p Principal
+	found := 0
+
+	for key, principal := range k {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			p = principal
+			found = 1
+		}
+	}
+
+	if found == 0 {
+		return "", ErrUnknownKey
+	}
+
+	return p, nil
+}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal authenticated for the request, if any
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+
+	return p, ok
+}
+
+// TokenFromHeader extracts the token of an `authorization: Bearer <token>` header value
+func TokenFromHeader(header string) (string, bool) {
+	const prefix = "bearer "
+
+	if len(header) <= len(prefix) || strings.ToLower(header[:len(prefix)]) != prefix {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[len(prefix):]), true
+}
+
+func authenticate(ctx context.Context, a Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, h := range md.Get("authorization") {
+		token, ok := TokenFromHeader(h)
+
+		if !ok {
+			continue
+		}
+
+		p, err := a.Authenticate(token)
+
+		if err != nil {
+			break
+		}
+
+		return NewContext(ctx, p), nil
+	}
+
+	return ctx, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+}
+
+// UnaryServerInterceptor rejects the calls which do not carry valid credentials,
+// the principal is then available through FromContext
+func UnaryServerInterceptor(a Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor
+func StreamServerInterceptor(a Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ss, ctx})
+	}
+}
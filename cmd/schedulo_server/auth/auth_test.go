@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+var policy = &Policy{
+	Rules: []Rule{
+		{
+			Principals: []string{"billing"},
+			Topics:     []string{"billing.*"},
+			Operations: []Operation{OpSchedule, OpStream},
+		},
+		{
+			Principals: []string{"ops"},
+			Topics:     []string{"*"},
+			Operations: []Operation{Wildcard},
+		},
+	},
+}
+
+func TestPolicy_Allowed(t *testing.T) {
+	cases := []struct {
+		principal Principal
+		op        Operation
+		topic     string
+		allowed   bool
+	}{
+		{"billing", OpSchedule, "billing.invoice", true},
+		{"billing", OpStream, "billing.invoice", true},
+		{"billing", OpUnschedule, "billing.invoice", false},
+		{"billing", OpStream, "shipping.parcel", false},
+		{"billing", OpStream, "", false},
+		{"billing", OpAdmin, "", false},
+		{"ops", OpAdmin, "", true},
+		{"ops", OpStream, "shipping.parcel", true},
+		{"unknown", OpStream, "billing.invoice", false},
+	}
+
+	for _, c := range cases {
+		if allowed := policy.Allowed(c.principal, c.op, c.topic); allowed != c.allowed {
+			t.Fatalf("%s %s %q: expected:%t, got:%t\n", c.principal, c.op, c.topic, c.allowed, allowed)
+		}
+	}
+
+	if err := (Rule{Operations: []Operation{"delete"}}).Validate(); err == nil {
+		t.Fatalf("Unknown operations must be rejected\n")
+	}
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	var nilPolicy *Policy
+
+	if err := nilPolicy.Authorize(context.Background(), OpAdmin, ""); err != nil {
+		t.Fatalf("A nil policy must allow everything: %v\n", err)
+	}
+
+	if err := policy.Authorize(context.Background(), OpStream, "billing.invoice"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("An unauthenticated request must be rejected: got %v\n", err)
+	}
+
+	ctx := NewContext(context.Background(), "billing")
+
+	if err := policy.Authorize(ctx, OpStream, "billing.invoice"); err != nil {
+		t.Fatalf("An allowed request must be accepted: %v\n", err)
+	}
+
+	if err := policy.Authorize(ctx, OpStream, "shipping.parcel"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("A forbidden request must be rejected: got %v\n", err)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(Chain{StaticKeys{"s3cr3t": "billing"}})
+
+	call := func(md metadata.MD) (Principal, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			p, _ := FromContext(ctx)
+			return p, nil
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		return resp.(Principal), nil
+	}
+
+	if p, err := call(metadata.Pairs("authorization", "Bearer s3cr3t")); err != nil || p != "billing" {
+		t.Fatalf("A valid API key must be authenticated: got %q, %v\n", p, err)
+	}
+
+	if _, err := call(metadata.Pairs("authorization", "Bearer wrong")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("An unknown API key must be rejected: got %v\n", err)
+	}
+
+	if _, err := call(metadata.MD{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("A call without credentials must be rejected: got %v\n", err)
+	}
+}
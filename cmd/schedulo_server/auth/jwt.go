@@ -0,0 +1,360 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const jwtLeeway = 30 * time.Second
+
+var (
+	ErrMalformedToken    = errors.New("malformed token")
+	ErrUnsupportedAlg    = errors.New("unsupported signing algorithm")
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+	ErrInvalidSignature  = errors.New("invalid token signature")
+	ErrExpiredToken      = errors.New("the token has expired")
+	ErrInvalidClaims     = errors.New("invalid token claims")
+)
+
+var jwtAlgs = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+type JWTConfig struct {
+	// JWKSFile is a local JSON Web Key Set, it is loaded again whenever it changes
+	JWKSFile string
+
+	// Issuer and Audience are only checked when they are not empty
+	Issuer   string
+	Audience string
+
+	// PrincipalClaim is the claim holding the principal, "sub" by default
+	PrincipalClaim string
+}
+
+// JWT authenticates JSON Web Tokens signed by one of the keys of a JWKS file, the tokens must have an exp claim
+type JWT struct {
+	conf    JWTConfig
+	keys    map[string]crypto.PublicKey
+	modTime time.Time
+	mu      *sync.Mutex
+	now     func() time.Time
+}
+
+func NewJWT(conf JWTConfig) (*JWT, error) {
+	if conf.PrincipalClaim == "" {
+		conf.PrincipalClaim = "sub"
+	}
+
+	j := &JWT{
+		conf: conf,
+		mu:   &sync.Mutex{},
+		now:  time.Now,
+	}
+
+	if _, err := j.signingKeys(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// signingKeys returns the keys of the JWKS file, if the file cannot be loaded again the previous keys are kept
+func (j *JWT) signingKeys() (map[string]crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fstat, err := os.Stat(j.conf.JWKSFile)
+
+	if err == nil && fstat.ModTime().Equal(j.modTime) && j.keys != nil {
+		return j.keys, nil
+	}
+
+	keys, err := loadJWKS(j.conf.JWKSFile)
+
+	if err != nil {
+		if j.keys != nil {
+			return j.keys, nil
+		}
+
+		return nil, err
+	}
+
+	j.keys = keys
+
+	if fstat != nil {
+		j.modTime = fstat.ModTime()
+	}
+
+	return j.keys, nil
+}
+
+func loadJWKS(file string) (map[string]crypto.PublicKey, error) {
+	buf, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(buf, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", file, k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+func (j *JWT) Authenticate(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return "", ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", err
+	}
+
+	hash, ok := jwtAlgs[header.Alg]
+
+	if !ok {
+		return "", ErrUnsupportedAlg
+	}
+
+	keys, err := j.signingKeys()
+
+	if err != nil {
+		return "", err
+	}
+
+	key, ok := keys[header.Kid]
+
+	if !ok {
+		return "", ErrUnknownSigningKey
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+
+	if err := verify(key, header.Alg, hash, h.Sum(nil), sig); err != nil {
+		return "", err
+	}
+
+	var claims map[string]interface{}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", err
+	}
+
+	return j.validate(claims)
+}
+
+func verify(key crypto.PublicKey, alg string, hash crypto.Hash, digest []byte, sig []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[:2] != "RS" || rsa.VerifyPKCS1v15(k, hash, digest, sig) != nil {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+
+		if alg[:2] != "ES" || len(sig) != 2*size {
+			return ErrInvalidSignature
+		}
+
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+
+		if !ecdsa.Verify(k, digest, r, s) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return ErrUnsupportedAlg
+}
+
+func (j *JWT) validate(claims map[string]interface{}) (Principal, error) {
+	now := j.now()
+
+	// The tokens without expiration would be valid forever
+	exp, ok := claims["exp"].(float64)
+
+	if !ok {
+		return "", ErrInvalidClaims
+	}
+
+	if now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return "", ErrExpiredToken
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return "", ErrInvalidClaims
+	}
+
+	if j.conf.Issuer != "" && claims["iss"] != j.conf.Issuer {
+		return "", ErrInvalidClaims
+	}
+
+	if j.conf.Audience != "" && !hasAudience(claims["aud"], j.conf.Audience) {
+		return "", ErrInvalidClaims
+	}
+
+	p, ok := claims[j.conf.PrincipalClaim].(string)
+
+	if !ok || p == "" {
+		return "", ErrInvalidClaims
+	}
+
+	return Principal(p), nil
+}
+
+func hasAudience(aud interface{}, expected string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == expected
+	case []interface{}:
+		for _, v := range a {
+			if v == expected {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(seg)
+
+	if err != nil {
+		return ErrMalformedToken
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return ErrMalformedToken
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+func b64(buf []byte) string {
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func sign(t *testing.T, alg string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sig = make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+
+	return signed + "." + b64(sig)
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa",
+				"use": "sig",
+				"n":   b64(rsaKey.N.Bytes()),
+				"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec",
+				"crv": "P-256",
+				"x":   b64(ecKey.X.Bytes()),
+				"y":   b64(ecKey.Y.Bytes()),
+			},
+		},
+	}
+
+	buf, _ := json.Marshal(set)
+
+	f, err := ioutil.TempFile("", "jwks.*.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	if _, err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.Remove(f.Name())
+	})
+
+	return f.Name()
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := NewJWT(JWTConfig{
+		JWKSFile: writeJWKS(t, rsaKey, ecKey),
+		Issuer:   "https://idp.example.com",
+		Audience: "schedulo",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub": "billing",
+			"iss": "https://idp.example.com",
+			"aud": []string{"schedulo", "other"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+
+		for k, v := range overrides {
+			c[k] = v
+		}
+
+		return c
+	}
+
+	for _, token := range []string{
+		sign(t, "RS256", "rsa", rsaKey, claims(nil)),
+		sign(t, "ES256", "ec", ecKey, claims(nil)),
+	} {
+		p, err := j.Authenticate(token)
+
+		if err != nil || p != "billing" {
+			t.Fatalf("A valid token must be authenticated: got %q, %v\n", p, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"expired":         sign(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"no expiration":   sign(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})),
+		"wrong issuer":    sign(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"wrong audience":  sign(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})),
+		"unknown key":     sign(t, "RS256", "unknown", rsaKey, claims(nil)),
+		"wrong signature": sign(t, "RS256", "rsa", otherKey, claims(nil)),
+		"alg mismatch":    sign(t, "ES256", "rsa", ecKey, claims(nil)),
+		"malformed":       "not.a.jwt",
+	}
+
+	for name, token := range invalid {
+		if _, err := j.Authenticate(token); err == nil {
+			t.Fatalf("An invalid token must be rejected: %s\n", name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"path"
+)
+
+// Operation is an action a principal can be allowed to perform on a topic
+type Operation string
+
+const (
+	OpSchedule   Operation = "schedule"
+	OpUnschedule Operation = "unschedule"
+	OpStream     Operation = "stream"
+	OpList       Operation = "list"
+	OpAdmin      Operation = "admin"
+)
+
+var Operations = []Operation{OpSchedule, OpUnschedule, OpStream, OpList, OpAdmin}
+
+// Wildcard matches any principal, topic or operation
+const Wildcard = "*"
+
+// Rule allows its principals to perform its operations on the topics matching its glob patterns
+type Rule struct {
+	Principals []string
+	Topics     []string
+	Operations []Operation
+}
+
+// Policy denies everything which is not explicitly allowed by one of its rules
+type Policy struct {
+	Rules []Rule
+}
+
+func (r Rule) Validate() error {
+	for _, op := range r.Operations {
+		known := op == Wildcard
+
+		for _, o := range Operations {
+			if op == o {
+				known = true
+			}
+		}
+
+		if !known {
+			return fmt.Errorf("unknown operation %q", op)
+		}
+	}
+
+	for _, t := range r.Topics {
+		if _, err := path.Match(t, ""); err != nil {
+			return fmt.Errorf("invalid topic pattern %q: %w", t, err)
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) allows(p Principal, op Operation, topic string) bool {
+	return r.hasPrincipal(p) && r.hasOperation(op) && r.hasTopic(topic)
+}
+
+func (r Rule) hasPrincipal(p Principal) bool {
+	for _, rp := range r.Principals {
+		if rp == Wildcard || Principal(rp) == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) hasOperation(op Operation) bool {
+	for _, o := range r.Operations {
+		if o == Wildcard || o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) hasTopic(topic string) bool {
+	for _, t := range r.Topics {
+		if ok, _ := path.Match(t, topic); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allowed tells whether the principal can perform the operation on the topic, the admin operations
+// are checked against the empty topic
+func (p *Policy) Allowed(principal Principal, op Operation, topic string) bool {
+	for _, r := range p.Rules {
+		if r.allows(principal, op, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize checks the principal of the request against the policy, a nil policy allows everything
+func (p *Policy) Authorize(ctx context.Context, op Operation, topic string) error {
+	if p == nil {
+		return nil
+	}
+
+	principal, ok := FromContext(ctx)
+
+	if !ok {
+		return status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+	}
+
+	if !p.Allowed(principal, op, topic) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s on topic %q", principal, op, topic)
+	}
+
+	return nil
+}
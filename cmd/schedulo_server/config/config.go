@@ -2,112 +2,477 @@ package config
 
 import (
 	"fmt"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
+	"github.com/yanishoss/schedulo/internal/core"
 	"gopkg.in/yaml.v2"
 	"io"
 	"os"
-	"strconv"
+	"regexp"
+	"strings"
+	"time"
 )
 
+var drivers = []string{"postgres", "mysql"}
+
 var defaultConfig = Config{
-	Dispatch: struct {
-		WorkersNumber        int `yaml:"workersNumber,omitempty"`
-		DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty"`
-		MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty"`
-	}{
+	Dispatch: DispatchConfig{
 		WorkersNumber:        120,
 		DefaultQueueCapacity: 1000,
 		MaxQueueCapacity:     1500,
+		RetryBackoff:         time.Second,
 	},
-	Database: struct {
-		Url    string `yaml:"url,omitempty"`
-		Driver string `yaml:"driver,omitempty"`
-	}{
-		Url:    envOrDefault("SCHEDULO_SQL_URL", fmt.Sprintf("host=%s port=%s dbname=%s user=%s password='%s' sslmode=%s", "localhost", "5432", "job_scheduler", "job_scheduler", "job_scheduler", "disable")),
-		Driver: envOrDefault("SCHEDULO_SQL_DRIVER", "postgres"),
+	Database: DatabaseConfig{
+		Url:    fmt.Sprintf("host=%s port=%s dbname=%s user=%s password='%s' sslmode=%s", "localhost", "5432", "job_scheduler", "job_scheduler", "job_scheduler", "disable"),
+		Driver: "postgres",
 	},
-	Cache: struct {
-		Addr string `yaml:"addr,omitempty"`
-		Pass string `yaml:"pass,omitempty"`
-		DB   int    `yaml:"db,omitempty"`
-	}{
-		Addr: envOrDefault("SCHEDULO_REDIS_ADDR", "localhost:6379"),
-		Pass: envOrDefault("SCHEDULO_REDIS_PASS", ""),
-		DB:   envOrDefaultInt("SCHEDULO_REDIS_DB", 0),
+	Cache: CacheConfig{
+		Addr: "localhost:6379",
+		Pass: "",
+		DB:   0,
 	},
-	Input: struct {
-		DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty"`
-		MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty"`
-		MaxBulkLimit         int `yaml:"maxBulkLimit,omitempty"`
-	}{
+	Input: InputConfig{
 		DefaultQueueCapacity: 2500,
 		MaxQueueCapacity:     3000,
 		MaxBulkLimit:         1500,
 	},
-	System: struct {
-		StacksNumber         int `yaml:"stacksNumber,omitempty"`
-		DefaultStackCapacity int `yaml:"defaultStackCapacity,omitempty"`
-		MaxStackCapacity     int `yaml:"maxStackCapacity,omitempty"`
-	}{
+	System: SystemConfig{
 		StacksNumber:         200,
 		DefaultStackCapacity: 1000,
 		MaxStackCapacity:     1500,
 	},
-	Network: struct {
-		Port int    `yaml:"port,omitempty"`
-		Addr string `yaml:"addr,omitempty"`
-	}{
-		Port: envOrDefaultInt("SCHEDULO_PORT", 9876),
-		Addr: envOrDefault("SCHEDULO_ADDR", "localhost"),
+	Network: NetworkConfig{
+		Port: 9876,
+		Addr: "localhost",
+	},
+	REST: RESTConfig{
+		Port: 9877,
+		Addr: "localhost",
+	},
+	Subscribers: SubscribersConfig{
+		BufferSize:     subscription.DefaultBufferSize,
+		OverflowPolicy: string(subscription.Block),
+		ResumeLogSize:  subscription.DefaultResumeLogSize,
 	},
 }
 
+// Every field is a knob which can be set from the file, an environment variable and a flag.
+// The `desc` tag is the help text, the `env` tag overrides the generated variable name
+// and the `flag` tag adds a short alias to the generated flag name.
+
+type DispatchConfig struct {
+	WorkersNumber        int `yaml:"workersNumber,omitempty" desc:"number of workers dispatching the due events"`
+	DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty" desc:"default capacity of the dispatch queue"`
+	MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty" desc:"capacity up to which the dispatch queue can grow"`
+
+	MaxRetries   int           `yaml:"maxRetries,omitempty" desc:"number of times a failed dispatch is attempted again"`
+	RetryBackoff time.Duration `yaml:"retryBackoff,omitempty" desc:"delay before the first retry, it doubles with each attempt"`
+}
+
+type DatabaseConfig struct {
+	Url    string `yaml:"url,omitempty" env:"SCHEDULO_SQL_URL" desc:"connection string of the SQL database"`
+	Driver string `yaml:"driver,omitempty" env:"SCHEDULO_SQL_DRIVER" desc:"SQL driver, postgres or mysql"`
+}
+
+type CacheConfig struct {
+	Addr string `yaml:"addr,omitempty" env:"SCHEDULO_REDIS_ADDR" desc:"address of the Redis cache"`
+	Pass string `yaml:"pass,omitempty" env:"SCHEDULO_REDIS_PASS" desc:"password of the Redis cache"`
+	DB   int    `yaml:"db,omitempty" env:"SCHEDULO_REDIS_DB" desc:"Redis database number"`
+}
+
+type InputConfig struct {
+	DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty" desc:"default capacity of the input queue"`
+	MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty" desc:"capacity up to which the input queue can grow"`
+	MaxBulkLimit         int `yaml:"maxBulkLimit,omitempty" desc:"maximum number of events persisted at once"`
+}
+
+type SystemConfig struct {
+	StacksNumber         int `yaml:"stacksNumber,omitempty" desc:"number of stacks holding the scheduled events"`
+	DefaultStackCapacity int `yaml:"defaultStackCapacity,omitempty" desc:"default capacity of each stack"`
+	MaxStackCapacity     int `yaml:"maxStackCapacity,omitempty" desc:"capacity up to which each stack can grow"`
+}
+
+type NetworkConfig struct {
+	Port int    `yaml:"port,omitempty" env:"SCHEDULO_PORT" flag:"port" desc:"tcp port on which the server listens"`
+	Addr string `yaml:"addr,omitempty" env:"SCHEDULO_ADDR" flag:"addr" desc:"tcp address on which the server listens"`
+}
+
+// RESTConfig shares the TLS and authentication settings of the gRPC server
+type RESTConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty" desc:"serves the REST gateway alongside gRPC"`
+	Port    int    `yaml:"port,omitempty" desc:"tcp port on which the REST gateway listens"`
+	Addr    string `yaml:"addr,omitempty" desc:"tcp address on which the REST gateway listens"`
+}
+
+// SubscribersConfig is only applied at startup
+type SubscribersConfig struct {
+	BufferSize     int    `yaml:"bufferSize,omitempty" desc:"number of events buffered for each stream subscriber"`
+	OverflowPolicy string `yaml:"overflowPolicy,omitempty" desc:"what happens when the buffer of a subscriber is full: block, drop-oldest, disconnect or retry"`
+	ResumeLogSize  int    `yaml:"resumeLogSize,omitempty" desc:"number of recent dispatches replayed to the subscribers resuming their stream"`
+}
+
+func (c SubscribersConfig) Options() subscription.Options {
+	return subscription.Options{
+		BufferSize: c.BufferSize,
+		Overflow:   subscription.OverflowPolicy(c.OverflowPolicy),
+	}
+}
+
+// CalendarConfig declares a calendar the recurring events can refer to by its name. The calendars are
+// applied on top of the ones managed through the API whenever the configuration is reloaded
+type CalendarConfig struct {
+	Name          string   `yaml:"name"`
+	TimeZone      string   `yaml:"timeZone,omitempty"`
+	ExcludedDates []string `yaml:"excludedDates,omitempty"`
+	WorkingDays   []string `yaml:"workingDays,omitempty"`
+	WorkingHours  string   `yaml:"workingHours,omitempty"`
+}
+
+func (c CalendarConfig) Calendar() (core.Calendar, error) {
+	cal := core.Calendar{
+		Name:          c.Name,
+		TimeZone:      c.TimeZone,
+		ExcludedDates: c.ExcludedDates,
+		WorkingHours:  c.WorkingHours,
+	}
+
+	for _, name := range c.WorkingDays {
+		d, err := core.ParseWeekday(name)
+
+		if err != nil {
+			return cal, err
+		}
+
+		cal.WorkingDays = append(cal.WorkingDays, d)
+	}
+
+	return cal, core.ValidateCalendar(cal)
+}
+
+// CoreCalendars returns the calendars declared by the configuration
+func (c Config) CoreCalendars() ([]core.Calendar, error) {
+	cals := make([]core.Calendar, len(c.Calendars))
+
+	for i, cc := range c.Calendars {
+		cal, err := cc.Calendar()
+
+		if err != nil {
+			return nil, err
+		}
+
+		cals[i] = cal
+	}
+
+	return cals, nil
+}
+
+// JitterConfig is the default jitter of the events of the topics matching one of its glob patterns,
+// the first one matching applies
+type JitterConfig struct {
+	Topics []string      `yaml:"topics"`
+	Window time.Duration `yaml:"window"`
+	Mode   string        `yaml:"mode,omitempty"`
+}
+
+var jitterModes = map[string]core.JitterMode{
+	"":       core.JitterHash,
+	"hash":   core.JitterHash,
+	"random": core.JitterRandom,
+}
+
+type TLSConfig struct {
+	Cert     string `yaml:"cert,omitempty" desc:"PEM certificate served by the server, enables TLS"`
+	Key      string `yaml:"key,omitempty" desc:"PEM key of the server certificate"`
+	ClientCA string `yaml:"clientCA,omitempty" desc:"PEM authorities the client certificates must be signed by, enables mutual TLS"`
+}
+
+type APIKeyConfig struct {
+	Key       string `yaml:"key"`
+	Principal string `yaml:"principal"`
+}
+
+type JWTConfig struct {
+	JWKSFile       string `yaml:"jwksFile,omitempty" desc:"local JWKS file the JWT signatures are verified against, enables JWT authentication"`
+	Issuer         string `yaml:"issuer,omitempty" desc:"expected issuer of the JWT, not checked if empty"`
+	Audience       string `yaml:"audience,omitempty" desc:"expected audience of the JWT, not checked if empty"`
+	PrincipalClaim string `yaml:"principalClaim,omitempty" desc:"JWT claim holding the principal"`
+}
+
+// PolicyConfig allows its principals to perform its operations on the topics matching its glob patterns,
+// "*" matches any principal, topic or operation
+type PolicyConfig struct {
+	Principals []string `yaml:"principals"`
+	Topics     []string `yaml:"topics"`
+	Operations []string `yaml:"operations"`
+}
+
+// AuthConfig is only applied at startup
+type AuthConfig struct {
+	Enabled  bool           `yaml:"enabled,omitempty" desc:"requires every call to be authenticated and authorized"`
+	APIKeys  []APIKeyConfig `yaml:"apiKeys,omitempty"`
+	JWT      JWTConfig      `yaml:"jwt,omitempty"`
+	Policies []PolicyConfig `yaml:"policies,omitempty"`
+}
+
+// Authenticator returns the authenticators enabled by the configuration
+func (c AuthConfig) Authenticator() (auth.Authenticator, error) {
+	var chain auth.Chain
+
+	if len(c.APIKeys) > 0 {
+		keys := make(auth.StaticKeys, len(c.APIKeys))
+
+		for _, k := range c.APIKeys {
+			keys[k.Key] = auth.Principal(k.Principal)
+		}
+
+		chain = append(chain, keys)
+	}
+
+	if c.JWT.JWKSFile != "" {
+		jwt, err := auth.NewJWT(auth.JWTConfig{
+			JWKSFile:       c.JWT.JWKSFile,
+			Issuer:         c.JWT.Issuer,
+			Audience:       c.JWT.Audience,
+			PrincipalClaim: c.JWT.PrincipalClaim,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, jwt)
+	}
+
+	return chain, nil
+}
+
+func (c AuthConfig) Policy() *auth.Policy {
+	p := &auth.Policy{}
+
+	for _, pc := range c.Policies {
+		ops := make([]auth.Operation, len(pc.Operations))
+
+		for i, op := range pc.Operations {
+			ops[i] = auth.Operation(op)
+		}
+
+		p.Rules = append(p.Rules, auth.Rule{
+			Principals: pc.Principals,
+			Topics:     pc.Topics,
+			Operations: ops,
+		})
+	}
+
+	return p
+}
+
 type Config struct {
-	Dispatch struct {
-		WorkersNumber        int `yaml:"workersNumber,omitempty"`
-		DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty"`
-		MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty"`
+	Dispatch DispatchConfig
+
+	Database DatabaseConfig
+
+	Cache CacheConfig
+
+	Input InputConfig
+
+	System SystemConfig
+
+	Network NetworkConfig
+
+	REST RESTConfig
+
+	Subscribers SubscribersConfig
+
+	TLS TLSConfig
+
+	Auth AuthConfig
+
+	Sinks []SinkConfig
+
+	Routes []RouteConfig
+
+	Calendars []CalendarConfig
+
+	Jitter []JitterConfig
+}
+
+// SchedulerConfig returns the part of the configuration used by the scheduler, it is the only part
+// which can be applied without restarting the server
+func (c Config) SchedulerConfig() core.SchedulerConfig {
+	return core.SchedulerConfig{
+		StackManagerConfig: core.StackManagerConfig{
+			StacksNumber:         c.System.StacksNumber,
+			DefaultStackCapacity: c.System.DefaultStackCapacity,
+			MaxStackCapacity:     c.System.MaxStackCapacity,
+		},
+		DispatchManagerConfig: core.DispatchManagerConfig{
+			WorkerNumber:         c.Dispatch.WorkersNumber,
+			DefaultQueueCapacity: c.Dispatch.DefaultQueueCapacity,
+			MaxQueueCapacity:     c.Dispatch.MaxQueueCapacity,
+			MaxRetries:           c.Dispatch.MaxRetries,
+			RetryBackoff:         c.Dispatch.RetryBackoff,
+		},
+		DefaultInputQueueCapacity: c.Input.DefaultQueueCapacity,
+		MaxInputQueueCapacity:     c.Input.MaxQueueCapacity,
+		MaxBulkLimit:              c.Input.MaxBulkLimit,
+		TopicJitters:              c.topicJitters(),
 	}
+}
 
-	Database struct {
-		Url    string `yaml:"url,omitempty"`
-		Driver string `yaml:"driver,omitempty"`
+func (c Config) topicJitters() []core.TopicJitter {
+	if len(c.Jitter) == 0 {
+		return nil
 	}
 
-	Cache struct {
-		Addr string `yaml:"addr,omitempty"`
-		Pass string `yaml:"pass,omitempty"`
-		DB   int    `yaml:"db,omitempty"`
+	jitters := make([]core.TopicJitter, len(c.Jitter))
+
+	for i, j := range c.Jitter {
+		jitters[i] = core.TopicJitter{Topics: j.Topics, Jitter: j.Window, Mode: jitterModes[j.Mode]}
+	}
+
+	return jitters
+}
+
+// Errors aggregates every problem found in a configuration
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+
+	for i, err := range e {
+		msgs[i] = err.Error()
 	}
 
-	Input struct {
-		DefaultQueueCapacity int `yaml:"defaultQueueCapacity,omitempty"`
-		MaxQueueCapacity     int `yaml:"maxQueueCapacity,omitempty"`
-		MaxBulkLimit         int `yaml:"maxBulkLimit,omitempty"`
+	return fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(msgs, "\n  - "))
+}
+
+// Validate checks the semantic of the configuration, every error found is reported at once
+func (c Config) Validate() error {
+	var errs Errors
+
+	positive := func(name string, v int) {
+		if v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0, got %d", name, v))
+		}
+	}
+
+	errs = append(errs, c.SchedulerConfig().Validate()...)
+
+	knownDriver := false
+
+	for _, d := range drivers {
+		if c.Database.Driver == d {
+			knownDriver = true
+		}
+	}
+
+	if !knownDriver {
+		errs = append(errs, fmt.Errorf("database.driver must be one of %s, got %q", strings.Join(drivers, ", "), c.Database.Driver))
+	}
+
+	if c.Database.Url == "" {
+		errs = append(errs, fmt.Errorf("database.url must not be empty"))
+	}
+
+	if c.Cache.Addr == "" {
+		errs = append(errs, fmt.Errorf("cache.addr must not be empty"))
+	}
+
+	if c.Network.Port <= 0 || c.Network.Port > 65535 {
+		errs = append(errs, fmt.Errorf("network.port must be between 1 and 65535, got %d", c.Network.Port))
+	}
+
+	if c.REST.Enabled && (c.REST.Port <= 0 || c.REST.Port > 65535) {
+		errs = append(errs, fmt.Errorf("rest.port must be between 1 and 65535, got %d", c.REST.Port))
+	}
+
+	positive("subscribers.bufferSize", c.Subscribers.BufferSize)
+	positive("subscribers.resumeLogSize", c.Subscribers.ResumeLogSize)
+
+	knownOverflow := false
+
+	for _, p := range subscription.OverflowPolicies {
+		if c.Subscribers.OverflowPolicy == string(p) {
+			knownOverflow = true
+		}
 	}
 
-	System struct {
-		StacksNumber         int `yaml:"stacksNumber,omitempty"`
-		DefaultStackCapacity int `yaml:"defaultStackCapacity,omitempty"`
-		MaxStackCapacity     int `yaml:"maxStackCapacity,omitempty"`
+	if !knownOverflow {
+		errs = append(errs, fmt.Errorf("subscribers.overflowPolicy must be block, drop-oldest, disconnect or retry, got %q", c.Subscribers.OverflowPolicy))
 	}
 
-	Network struct {
-		Port int    `yaml:"port,omitempty"`
-		Addr string `yaml:"addr,omitempty"`
+	if (c.TLS.Cert == "") != (c.TLS.Key == "") {
+		errs = append(errs, fmt.Errorf("tls.cert and tls.key must be set together"))
 	}
+
+	if c.TLS.ClientCA != "" && c.TLS.Cert == "" {
+		errs = append(errs, fmt.Errorf("tls.clientCA requires tls.cert and tls.key"))
+	}
+
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 && c.Auth.JWT.JWKSFile == "" {
+		errs = append(errs, fmt.Errorf("auth.enabled requires auth.apiKeys or auth.jwt.jwksFile"))
+	}
+
+	for i, k := range c.Auth.APIKeys {
+		if k.Key == "" || k.Principal == "" {
+			errs = append(errs, fmt.Errorf("auth.apiKeys[%d] must have a key and a principal", i))
+		}
+	}
+
+	for i, r := range c.Auth.Policy().Rules {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("auth.policies[%d]: %w", i, err))
+		}
+	}
+
+	errs = append(errs, c.sinkErrors()...)
+
+	names := make(map[string]bool, len(c.Calendars))
+
+	for i, cc := range c.Calendars {
+		if _, err := cc.Calendar(); err != nil {
+			errs = append(errs, fmt.Errorf("calendars[%d]: %w", i, err))
+		} else if names[cc.Name] {
+			errs = append(errs, fmt.Errorf("calendars[%d].name %q is already used", i, cc.Name))
+		}
+
+		names[cc.Name] = true
+	}
+
+	for i, j := range c.Jitter {
+		if _, ok := jitterModes[j.Mode]; !ok {
+			errs = append(errs, fmt.Errorf("jitter[%d].mode must be hash or random, got %q", i, j.Mode))
+		} else if err := core.ValidateTopicJitter(core.TopicJitter{Topics: j.Topics, Jitter: j.Window}); err != nil {
+			errs = append(errs, fmt.Errorf("jitter[%d]: %w", i, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// GetConfig resolves the configuration from the defaults, the file and the environment
+func GetConfig(path string) (Config, error) {
+	return Loader{Path: path}.Load()
 }
 
-func GetConfig(path string) Config {
+// LoadFile reads the configuration file on top of the defaults, unknown keys are rejected
+func LoadFile(path string) (Config, error) {
 	f, err := os.Open(path)
 
 	if err != nil {
-		return DefaultConfig()
+		return Config{}, fmt.Errorf("failed to open configuration file: %w", err)
 	}
 
+	defer f.Close()
+
 	fstat, err := f.Stat()
 
 	if err != nil {
-		return DefaultConfig()
+		return Config{}, err
 	}
 
 	buf := make([]byte, fstat.Size())
@@ -115,46 +480,83 @@ func GetConfig(path string) Config {
 	_, err = io.ReadFull(f, buf)
 
 	if err != nil {
-		return DefaultConfig()
+		return Config{}, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
 	var cfg = defaultConfig
 
-	err = yaml.Unmarshal(buf, &cfg)
+	err = yaml.UnmarshalStrict(buf, &cfg)
 
 	if err != nil {
-		return DefaultConfig()
+		return Config{}, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
 
-	return cfg
+	return cfg, nil
 }
 
 func DefaultConfig() Config {
 	return defaultConfig
 }
 
-func envOrDefault(key string, def string) string {
-	v, ok := os.LookupEnv(key)
+// redacted replaces the secrets of a printed configuration
+const redacted = "***"
 
-	if !ok {
-		return def
-	}
+var (
+	// dsnPassword matches the password of a key/value connection string, quoted or not
+	dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
 
-	return v
-}
+	// userinfoPassword matches the password of the user information of a URL or of a MySQL DSN
+	userinfoPassword = regexp.MustCompile(`^([^:/@\s]+://)?([^:/@\s]*):[^@\s]*@`)
+)
 
-func envOrDefaultInt(key string, def int) int {
-	v, ok := os.LookupEnv(key)
+// Redacted returns a copy of the configuration whose passwords, keys and secrets are masked, so that it
+// can be printed
+func (c Config) Redacted() Config {
+	c.Database.Url = redactConnection(c.Database.Url)
 
-	if !ok {
-		return def
+	if c.Cache.Pass != "" {
+		c.Cache.Pass = redacted
 	}
 
-	vInt, err := strconv.Atoi(v)
+	keys := make([]APIKeyConfig, len(c.Auth.APIKeys))
 
-	if err != nil {
-		return def
+	for i, k := range c.Auth.APIKeys {
+		k.Key = redacted
+		keys[i] = k
 	}
 
-	return vInt
+	c.Auth.APIKeys = keys
+
+	sinks := make([]SinkConfig, len(c.Sinks))
+
+	for i, s := range c.Sinks {
+		s.Webhook.URL = redactConnection(s.Webhook.URL)
+
+		if s.Webhook.Secret != "" {
+			s.Webhook.Secret = redacted
+		}
+
+		if s.Redis.Pass != "" {
+			s.Redis.Pass = redacted
+		}
+
+		env := make([]string, len(s.Command.Env))
+
+		for j, kv := range s.Command.Env {
+			env[j] = strings.SplitN(kv, "=", 2)[0] + "=" + redacted
+		}
+
+		s.Command.Env = env
+		sinks[i] = s
+	}
+
+	c.Sinks = sinks
+
+	return c
+}
+
+func redactConnection(conn string) string {
+	conn = dsnPassword.ReplaceAllString(conn, "${1}"+redacted)
+
+	return userinfoPassword.ReplaceAllString(conn, "${1}${2}:"+redacted+"@")
 }
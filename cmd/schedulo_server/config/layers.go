@@ -0,0 +1,430 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const envPrefix = "SCHEDULO_"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// knob is a scalar field of the configuration
+type knob struct {
+	// Path is the dotted yaml path of the field, it is also the name of its flag
+	Path  string
+	Env   string
+	Alias string
+	Desc  string
+	index []int
+	typ   reflect.Type
+}
+
+func (k knob) value(cfg *Config) reflect.Value {
+	return reflect.ValueOf(cfg).Elem().FieldByIndex(k.index)
+}
+
+func (k knob) set(cfg *Config, raw string) error {
+	v := k.value(cfg)
+
+	if k.typ == durationType {
+		d, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch k.typ.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, k.typ.Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(raw, 10, k.typ.Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, k.typ.Bits())
+
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+
+		if raw != "" {
+			items = strings.Split(raw, ",")
+		}
+
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+
+		v.Set(reflect.ValueOf(items))
+	}
+
+	return nil
+}
+
+func (k knob) typeName() string {
+	if k.typ == durationType {
+		return "duration"
+	}
+
+	if k.typ.Kind() == reflect.Slice {
+		return "list"
+	}
+
+	return k.typ.Kind().String()
+}
+
+func (k knob) format(cfg *Config) string {
+	v := k.value(cfg)
+
+	if k.typ.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// knobs lists every scalar field of the configuration, the lists of structures and the maps
+// can only be set from the file
+func knobs() []knob {
+	var out []knob
+
+	var walk func(t reflect.Type, index []int, path []string)
+
+	walk = func(t reflect.Type, index []int, path []string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := yamlName(f)
+
+			if name == "-" {
+				continue
+			}
+
+			idx := append(append([]int{}, index...), i)
+			p := append(append([]string{}, path...), name)
+
+			if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+				walk(f.Type, idx, p)
+				continue
+			}
+
+			if !isScalar(f.Type) {
+				continue
+			}
+
+			env := f.Tag.Get("env")
+
+			if env == "" {
+				env = envName(p)
+			}
+
+			out = append(out, knob{
+				Path:  strings.Join(p, "."),
+				Env:   env,
+				Alias: f.Tag.Get("flag"),
+				Desc:  f.Tag.Get("desc"),
+				index: idx,
+				typ:   f.Type,
+			})
+		}
+	}
+
+	walk(reflect.TypeOf(Config{}), nil, nil)
+
+	return out
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+
+	return false
+}
+
+func yamlName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+
+	return name
+}
+
+// envName converts a path such as system.stacksNumber into SCHEDULO_SYSTEM_STACKS_NUMBER
+func envName(path []string) string {
+	var b strings.Builder
+
+	b.WriteString(envPrefix)
+
+	for i, part := range path {
+		if i > 0 {
+			b.WriteRune('_')
+		}
+
+		for j, r := range part {
+			if unicode.IsUpper(r) && j > 0 {
+				b.WriteRune('_')
+			}
+
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+
+	return b.String()
+}
+
+// applyEnv overrides the configuration with the environment variables which are set
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	var errs Errors
+
+	for _, k := range knobs() {
+		raw, ok := lookup(k.Env)
+
+		if !ok {
+			continue
+		}
+
+		if err := k.set(cfg, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q for %s: %v", k.Env, raw, k.Path, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Flags holds one command line flag per knob, only the flags which are explicitly set
+// override the other layers
+type Flags struct {
+	fs     *flag.FlagSet
+	knobs  []knob
+	values map[string]*flagValue
+}
+
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+
+	return v.raw
+}
+
+func (v *flagValue) Set(raw string) error {
+	v.raw = raw
+
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// RegisterFlags defines the flags of every knob on the flag set and replaces its usage
+// with the generated help text
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		fs:     fs,
+		knobs:  knobs(),
+		values: make(map[string]*flagValue),
+	}
+
+	def := DefaultConfig()
+
+	for _, k := range f.knobs {
+		v := &flagValue{raw: k.format(&def), isBool: k.typ.Kind() == reflect.Bool}
+		f.values[k.Path] = v
+
+		fs.Var(v, k.Path, k.Desc)
+
+		if k.Alias != "" {
+			fs.Var(v, k.Alias, k.Desc)
+		}
+	}
+
+	fs.Usage = func() {
+		f.PrintUsage(fs.Output())
+	}
+
+	return f
+}
+
+func (f *Flags) apply(cfg *Config) error {
+	set := make(map[string]bool)
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+
+	var errs Errors
+
+	for _, k := range f.knobs {
+		if !set[k.Path] && !(k.Alias != "" && set[k.Alias]) {
+			continue
+		}
+
+		raw := f.values[k.Path].raw
+
+		if err := k.set(cfg, raw); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: invalid value %q: %v", k.Path, raw, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// PrintUsage writes the help text listing every knob with its flag, environment variable and default value
+func (f *Flags) PrintUsage(w io.Writer) {
+	name := filepath.Base(os.Args[0])
+
+	fmt.Fprintf(w, "Usage:\n  %s [flags]\n  %s config validate [flags]\n\n", name, name)
+
+	knobFlags := make(map[string]bool)
+
+	for _, k := range f.knobs {
+		knobFlags[k.Path] = true
+
+		if k.Alias != "" {
+			knobFlags[k.Alias] = true
+		}
+	}
+
+	var others []*flag.Flag
+
+	f.fs.VisitAll(func(fl *flag.Flag) {
+		if !knobFlags[fl.Name] {
+			others = append(others, fl)
+		}
+	})
+
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].Name < others[j].Name
+	})
+
+	if len(others) > 0 {
+		fmt.Fprintln(w, "Flags:")
+
+		for _, fl := range others {
+			fmt.Fprintf(w, "  -%s\n    \t%s (default %q)\n", fl.Name, fl.Usage, fl.DefValue)
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "Configuration (defaults < file < environment < flags):")
+
+	def := DefaultConfig()
+
+	for _, k := range f.knobs {
+		flagName := "-" + k.Path
+
+		if k.Alias != "" {
+			flagName += ", -" + k.Alias
+		}
+
+		fmt.Fprintf(w, "  %s %s\n    \t%s\n    \tenv %s, default %q\n", flagName, k.typeName(), k.Desc, k.Env, k.format(&def))
+	}
+}
+
+// Loader resolves the configuration by layering the defaults, the file, the environment and the flags,
+// each layer overriding the previous ones
+type Loader struct {
+	Path string
+
+	// Optional allows the file to be missing, in which case the defaults are used instead
+	Optional bool
+
+	Flags *Flags
+
+	// LookupEnv defaults to os.LookupEnv
+	LookupEnv func(string) (string, bool)
+}
+
+// Load returns the effective configuration, or the configuration along with its validation errors
+func (l Loader) Load() (Config, error) {
+	cfg, err := LoadFile(l.Path)
+
+	if errors.Is(err, os.ErrNotExist) && l.Optional {
+		cfg, err = DefaultConfig(), nil
+	}
+
+	if err != nil {
+		return cfg, err
+	}
+
+	lookup := l.LookupEnv
+
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	if err := applyEnv(&cfg, lookup); err != nil {
+		return cfg, err
+	}
+
+	if l.Flags != nil {
+		if err := l.Flags.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestLoader_Layers(t *testing.T) {
+	path := writeConfig(t, `
+system:
+  stacksNumber: 10
+  defaultStackCapacity: 100
+dispatch:
+  workersNumber: 4
+`)
+
+	env := map[string]string{
+		"SCHEDULO_SYSTEM_STACKS_NUMBER":    "20",
+		"SCHEDULO_DISPATCH_WORKERS_NUMBER": "8",
+		"SCHEDULO_PORT":                    "7000",
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-system.stacksNumber", "30", "-port", "8000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Loader{
+		Path:  path,
+		Flags: flags,
+		LookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	}.Load()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.System.DefaultStackCapacity != 100 {
+		t.Fatalf("The file must override the defaults: expected:%d, got:%d\n", 100, cfg.System.DefaultStackCapacity)
+	}
+
+	if cfg.Dispatch.WorkersNumber != 8 {
+		t.Fatalf("The environment must override the file: expected:%d, got:%d\n", 8, cfg.Dispatch.WorkersNumber)
+	}
+
+	if cfg.System.StacksNumber != 30 {
+		t.Fatalf("The flags must override the environment: expected:%d, got:%d\n", 30, cfg.System.StacksNumber)
+	}
+
+	if cfg.Network.Port != 8000 {
+		t.Fatalf("The flag aliases must override the environment: expected:%d, got:%d\n", 8000, cfg.Network.Port)
+	}
+
+	if cfg.Input.MaxBulkLimit != defaultConfig.Input.MaxBulkLimit {
+		t.Fatalf("The unset knobs must keep their default: expected:%d, got:%d\n", defaultConfig.Input.MaxBulkLimit, cfg.Input.MaxBulkLimit)
+	}
+}
+
+func TestLoader_InvalidEnv(t *testing.T) {
+	_, err := Loader{
+		Path:     "/nonexistent/schedulo.config.yaml",
+		Optional: true,
+		LookupEnv: func(key string) (string, bool) {
+			if key == "SCHEDULO_INPUT_MAX_BULK_LIMIT" {
+				return "a lot", true
+			}
+
+			return "", false
+		},
+	}.Load()
+
+	if err == nil {
+		t.Fatalf("An invalid environment variable must be reported\n")
+	}
+}
+
+func TestKnobs(t *testing.T) {
+	expected := map[string]string{
+		"system.stacksNumber":    "SCHEDULO_SYSTEM_STACKS_NUMBER",
+		"input.maxBulkLimit":     "SCHEDULO_INPUT_MAX_BULK_LIMIT",
+		"database.url":           "SCHEDULO_SQL_URL",
+		"network.addr":           "SCHEDULO_ADDR",
+		"dispatch.workersNumber": "SCHEDULO_DISPATCH_WORKERS_NUMBER",
+	}
+
+	found := 0
+
+	for _, k := range knobs() {
+		if k.Desc == "" {
+			t.Fatalf("Every knob must be documented: %s has no description\n", k.Path)
+		}
+
+		if env, ok := expected[k.Path]; ok {
+			found++
+
+			if k.Env != env {
+				t.Fatalf("Wrong environment variable for %s: expected:%s, got:%s\n", k.Path, env, k.Env)
+			}
+		}
+	}
+
+	if found != len(expected) {
+		t.Fatalf("Some knobs are missing: expected:%d, got:%d\n", len(expected), found)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	RegisterFlags(fs)
+
+	if fs.Lookup("system.stacksNumber") == nil || fs.Lookup("port") == nil {
+		t.Fatalf("Every knob must have a flag\n")
+	}
+}
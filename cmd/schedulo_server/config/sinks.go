@@ -0,0 +1,332 @@
+package config
+
+import (
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/sink"
+	"github.com/yanishoss/schedulo/internal/webhook"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SinkConfig declares a sink which the routes refer to by its name, only the section of its type is used.
+// The sinks are only applied at startup
+type SinkConfig struct {
+	Name          string `yaml:"name"`
+	Type          string `yaml:"type"`
+	Concurrency   int    `yaml:"concurrency,omitempty"`
+	FailurePolicy string `yaml:"failurePolicy,omitempty"`
+
+	Webhook WebhookSinkConfig `yaml:"webhook,omitempty"`
+	File    FileSinkConfig    `yaml:"file,omitempty"`
+	Redis   RedisSinkConfig   `yaml:"redis,omitempty"`
+	Outbox  OutboxSinkConfig  `yaml:"outbox,omitempty"`
+	Command CommandSinkConfig `yaml:"command,omitempty"`
+}
+
+type WebhookSinkConfig struct {
+	URL     string        `yaml:"url,omitempty"`
+	Secret  string        `yaml:"secret,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+type FileSinkConfig struct {
+	Path string `yaml:"path,omitempty"`
+}
+
+// RedisSinkConfig uses the cache settings when its address is empty
+type RedisSinkConfig struct {
+	Addr   string `yaml:"addr,omitempty"`
+	Pass   string `yaml:"pass,omitempty"`
+	DB     int    `yaml:"db,omitempty"`
+	Mode   string `yaml:"mode,omitempty"`
+	Prefix string `yaml:"prefix,omitempty"`
+	MaxLen int64  `yaml:"maxLen,omitempty"`
+}
+
+// OutboxSinkConfig writes to a table of the database section, in the transaction completing the dispatch
+type OutboxSinkConfig struct {
+	Table string `yaml:"table,omitempty"`
+}
+
+// CommandSinkConfig runs a process per event, its arguments are templates over the event fields
+// (e.g. "{{.Topic}}") and the payload is written on its standard input
+type CommandSinkConfig struct {
+	Args         []string      `yaml:"args,omitempty"`
+	Dir          string        `yaml:"dir,omitempty"`
+	Env          []string      `yaml:"env,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+	MaxProcesses int           `yaml:"maxProcesses,omitempty"`
+}
+
+// RouteConfig sends the events of the topics matching its glob patterns to its sinks, the first matching
+// route wins and the events matched by no route are sent to the gRPC subscribers
+type RouteConfig struct {
+	Topics []string `yaml:"topics"`
+	Sinks  []string `yaml:"sinks"`
+}
+
+type sinkType struct {
+	validate func(s SinkConfig) []string
+	build    func(c Config, s SinkConfig) (sink.Sink, error)
+}
+
+var sinkTypes = map[string]sinkType{
+	"webhook": {
+		validate: func(s SinkConfig) []string {
+			var msgs []string
+
+			if u, err := url.Parse(s.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				msgs = append(msgs, fmt.Sprintf("webhook.url must be an absolute http or https URL, got %q", s.Webhook.URL))
+			}
+
+			if s.Webhook.Timeout < 0 {
+				msgs = append(msgs, fmt.Sprintf("webhook.timeout must not be negative, got %s", s.Webhook.Timeout))
+			}
+
+			return msgs
+		},
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			return webhook.New(webhook.Endpoint{
+				URL:     s.Webhook.URL,
+				Secret:  s.Webhook.Secret,
+				Timeout: s.Webhook.Timeout,
+			}), nil
+		},
+	},
+	"stdout": {
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			return sink.NewWriter(os.Stdout), nil
+		},
+	},
+	"file": {
+		validate: func(s SinkConfig) []string {
+			if s.File.Path == "" {
+				return []string{"file.path must not be empty"}
+			}
+
+			return nil
+		},
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			return sink.NewFile(s.File.Path)
+		},
+	},
+	"redis": {
+		validate: func(s SinkConfig) []string {
+			var msgs []string
+
+			knownMode := s.Redis.Mode == ""
+
+			for _, m := range sink.RedisModes {
+				if s.Redis.Mode == string(m) {
+					knownMode = true
+				}
+			}
+
+			if !knownMode {
+				msgs = append(msgs, fmt.Sprintf("redis.mode must be stream or pubsub, got %q", s.Redis.Mode))
+			}
+
+			if s.Redis.MaxLen < 0 {
+				msgs = append(msgs, fmt.Sprintf("redis.maxLen must not be negative, got %d", s.Redis.MaxLen))
+			}
+
+			return msgs
+		},
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			conf := sink.RedisConfig{
+				Addr:   s.Redis.Addr,
+				Pass:   s.Redis.Pass,
+				DB:     s.Redis.DB,
+				Mode:   sink.RedisMode(s.Redis.Mode),
+				Prefix: s.Redis.Prefix,
+				MaxLen: s.Redis.MaxLen,
+			}
+
+			if conf.Addr == "" {
+				conf.Addr, conf.Pass, conf.DB = c.Cache.Addr, c.Cache.Pass, c.Cache.DB
+			}
+
+			return sink.NewRedis(conf)
+		},
+	},
+	"outbox": {
+		validate: func(s SinkConfig) []string {
+			if s.Outbox.Table != "" && !sink.ValidTableName(s.Outbox.Table) {
+				return []string{fmt.Sprintf("outbox.table must be a plain SQL identifier, got %q", s.Outbox.Table)}
+			}
+
+			return nil
+		},
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			return sink.NewOutbox(sink.OutboxConfig{
+				Url:    c.Database.Url,
+				Driver: c.Database.Driver,
+				Table:  s.Outbox.Table,
+			})
+		},
+	},
+	"command": {
+		validate: func(s SinkConfig) []string {
+			var msgs []string
+
+			if len(s.Command.Args) == 0 {
+				msgs = append(msgs, "command.args must not be empty")
+			}
+
+			for i, arg := range s.Command.Args {
+				if _, err := template.New("").Parse(arg); err != nil {
+					msgs = append(msgs, fmt.Sprintf("command.args[%d] is not a valid template: %v", i, err))
+				}
+			}
+
+			for i, env := range s.Command.Env {
+				if !strings.Contains(env, "=") {
+					msgs = append(msgs, fmt.Sprintf("command.env[%d] must be of the form KEY=VALUE, got %q", i, env))
+				}
+			}
+
+			if s.Command.Timeout < 0 {
+				msgs = append(msgs, fmt.Sprintf("command.timeout must not be negative, got %s", s.Command.Timeout))
+			}
+
+			if s.Command.MaxProcesses < 0 {
+				msgs = append(msgs, fmt.Sprintf("command.maxProcesses must not be negative, got %d", s.Command.MaxProcesses))
+			}
+
+			return msgs
+		},
+		build: func(c Config, s SinkConfig) (sink.Sink, error) {
+			return sink.NewCommand(sink.CommandConfig{
+				Args:         s.Command.Args,
+				Dir:          s.Command.Dir,
+				Env:          s.Command.Env,
+				Timeout:      s.Command.Timeout,
+				MaxProcesses: s.Command.MaxProcesses,
+			})
+		},
+	},
+}
+
+func sinkTypeNames() string {
+	names := make([]string, 0, len(sinkTypes))
+
+	for name := range sinkTypes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func (c Config) sinkErrors() []error {
+	var errs []error
+
+	names := map[string]bool{sink.Subscribers: true}
+
+	for i, s := range c.Sinks {
+		prefix := fmt.Sprintf("sinks[%d]", i)
+
+		if s.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name must not be empty", prefix))
+		} else if names[s.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is already used", prefix, s.Name))
+		}
+
+		names[s.Name] = true
+
+		if s.Concurrency < 0 {
+			errs = append(errs, fmt.Errorf("%s.concurrency must not be negative, got %d", prefix, s.Concurrency))
+		}
+
+		knownPolicy := s.FailurePolicy == ""
+
+		for _, p := range sink.FailurePolicies {
+			if s.FailurePolicy == string(p) {
+				knownPolicy = true
+			}
+		}
+
+		if !knownPolicy {
+			errs = append(errs, fmt.Errorf("%s.failurePolicy must be retry or ignore, got %q", prefix, s.FailurePolicy))
+		}
+
+		typ, ok := sinkTypes[s.Type]
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s.type must be one of %s, got %q", prefix, sinkTypeNames(), s.Type))
+			continue
+		}
+
+		if typ.validate != nil {
+			for _, msg := range typ.validate(s) {
+				errs = append(errs, fmt.Errorf("%s.%s", prefix, msg))
+			}
+		}
+	}
+
+	for i, r := range c.Routes {
+		if len(r.Topics) == 0 {
+			errs = append(errs, fmt.Errorf("routes[%d].topics must not be empty", i))
+		}
+
+		for _, t := range r.Topics {
+			if _, err := path.Match(t, ""); err != nil {
+				errs = append(errs, fmt.Errorf("routes[%d]: invalid topic pattern %q: %w", i, t, err))
+			}
+		}
+
+		if len(r.Sinks) == 0 {
+			errs = append(errs, fmt.Errorf("routes[%d].sinks must not be empty", i))
+		}
+
+		for _, name := range r.Sinks {
+			if !names[name] {
+				errs = append(errs, fmt.Errorf("routes[%d]: unknown sink %q", i, name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// SinkRegistry builds the configured sinks along with their routes, the built-in gRPC subscribers sink
+// is registered by the server
+func (c Config) SinkRegistry() (*sink.Registry, []sink.Route, error) {
+	reg := sink.NewRegistry()
+
+	for _, s := range c.Sinks {
+		snk, err := sinkTypes[s.Type].build(c, s)
+
+		if err != nil {
+			reg.Close()
+			return nil, nil, fmt.Errorf("sink %s: %w", s.Name, err)
+		}
+
+		err = reg.Register(s.Name, snk, sink.Options{
+			Concurrency:   s.Concurrency,
+			FailurePolicy: sink.FailurePolicy(s.FailurePolicy),
+		})
+
+		if err != nil {
+			reg.Close()
+			return nil, nil, err
+		}
+	}
+
+	routes := make([]sink.Route, len(c.Routes))
+
+	for i, r := range c.Routes {
+		routes[i] = sink.Route{
+			Topics: r.Topics,
+			Sinks:  r.Sinks,
+		}
+	}
+
+	return reg, routes, nil
+}
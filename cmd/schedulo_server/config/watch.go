@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const DefaultWatchInterval = 5 * time.Second
+
+// Watcher reloads the configuration whenever its file is modified or a reload is requested,
+// the environment and the flags keep overriding the file
+type Watcher struct {
+	loader   Loader
+	interval time.Duration
+	reload   chan struct{}
+	onChange func(Config)
+	onError  func(error)
+	modTime  time.Time
+	size     int64
+}
+
+func NewWatcher(loader Loader, interval time.Duration, onChange func(Config), onError func(error)) *Watcher {
+	w := &Watcher{
+		loader:   loader,
+		interval: interval,
+		reload:   make(chan struct{}, 1),
+		onChange: onChange,
+		onError:  onError,
+	}
+
+	w.modTime, w.size = w.stat()
+
+	return w
+}
+
+// Reload requests the configuration to be read again, even if the file has not changed
+func (w *Watcher) Reload() {
+	select {
+	case w.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Run blocks until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.reload:
+			w.modTime, w.size = w.stat()
+			w.apply()
+		case <-ticker.C:
+			modTime, size := w.stat()
+
+			if modTime.Equal(w.modTime) && size == w.size {
+				break
+			}
+
+			w.modTime, w.size = modTime, size
+			w.apply()
+		}
+	}
+}
+
+func (w *Watcher) apply() {
+	cfg, err := w.loader.Load()
+
+	if err != nil {
+		// The current configuration is kept rather than falling back to the defaults
+		w.onError(err)
+		return
+	}
+
+	w.onChange(cfg)
+}
+
+func (w *Watcher) stat() (time.Time, int64) {
+	fstat, err := os.Stat(w.loader.Path)
+
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	return fstat.ModTime(), fstat.Size()
+}
@@ -0,0 +1,263 @@
+// Package gateway serves the Scheduler service as a JSON REST API, the events are streamed as Server-Sent Events
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var marshaler = &jsonpb.Marshaler{EmitDefaults: true}
+
+// Gateway translates the REST calls to the gRPC handlers, so they share the same scheduler and authorization
+type Gateway struct {
+	srv  api.SchedulerServer
+	auth auth.Authenticator
+	mux  *http.ServeMux
+}
+
+// New returns the REST gateway of srv, the calls must carry a bearer token accepted by a if it is not nil
+func New(srv api.SchedulerServer, a auth.Authenticator) *Gateway {
+	g := &Gateway{
+		srv:  srv,
+		auth: a,
+		mux:  http.NewServeMux(),
+	}
+
+	g.mux.HandleFunc("/openapi.json", g.openAPI)
+	g.mux.Handle("/events", g.authenticated(g.events))
+	g.mux.Handle("/events/stream", g.authenticated(g.stream))
+	g.mux.Handle("/events/preview", g.authenticated(g.preview))
+	g.mux.Handle("/events/", g.authenticated(g.event))
+
+	return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *Gateway) authenticated(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.auth == nil {
+			h(w, r)
+			return
+		}
+
+		token, ok := auth.TokenFromHeader(r.Header.Get("Authorization"))
+
+		if !ok {
+			writeError(w, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error()))
+			return
+		}
+
+		p, err := g.auth.Authenticate(token)
+
+		if err != nil {
+			writeError(w, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error()))
+			return
+		}
+
+		h(w, r.WithContext(auth.NewContext(r.Context(), p)))
+	})
+}
+
+// events handles GET /events and POST /events
+func (g *Gateway) events(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := g.srv.ListEvents(r.Context(), &api.ListEventsRequest{
+			Topic: r.URL.Query().Get("topic"),
+		})
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeMessage(w, http.StatusOK, resp)
+	case http.MethodPost:
+		e := &api.Event{}
+
+		if err := jsonpb.Unmarshal(r.Body, e); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid event: %v", err))
+			return
+		}
+
+		resp, err := g.srv.Schedule(r.Context(), &api.ScheduleRequest{Event: e})
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeMessage(w, http.StatusCreated, resp)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// event handles DELETE /events/{id}
+func (g *Gateway) event(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/events/")
+
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		methodNotAllowed(w, http.MethodDelete)
+		return
+	}
+
+	_, err := g.srv.Unschedule(r.Context(), &api.UnscheduleRequest{
+		Id: &api.Event_ID{Id: id},
+	})
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// preview handles POST /events/preview
+func (g *Gateway) preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	req := &api.PreviewScheduleRequest{}
+
+	if err := jsonpb.Unmarshal(r.Body, req); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid request: %v", err))
+		return
+	}
+
+	resp, err := g.srv.PreviewSchedule(r.Context(), req)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeMessage(w, http.StatusOK, resp)
+}
+
+// stream handles GET /events/stream
+func (g *Gateway) stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	f, ok := w.(http.Flusher)
+
+	if !ok {
+		writeError(w, status.Error(codes.Unimplemented, "streaming is not supported"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// The subscribers are listed with the address of their client
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+
+	// EventSource resumes the stream with the Last-Event-ID header, the other clients may use the query
+	token := r.Header.Get("Last-Event-ID")
+
+	if token == "" {
+		token = r.URL.Query().Get("resumeToken")
+	}
+
+	stream := newEventStream(ctx, w, f)
+	err := g.srv.StreamEvents(&api.StreamEventsRequest{
+		Topic:       r.URL.Query().Get("topic"),
+		Group:       r.URL.Query().Get("group"),
+		Filter:      r.URL.Query().Get("filter"),
+		ResumeToken: token,
+	}, stream)
+
+	// Nothing can be written once the handler has returned
+	if !stream.close() && err != nil {
+		writeError(w, err)
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeStatus(w, http.StatusMethodNotAllowed, status.New(codes.Unimplemented, "method not allowed"))
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	buf := &bytes.Buffer{}
+
+	if err := marshaler.Marshal(buf, msg); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
+
+// writeError writes the gRPC status of err as a JSON body
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+
+	writeStatus(w, httpStatus(st.Code()), st)
+}
+
+func writeStatus(w http.ResponseWriter, code int, st *status.Status) {
+	buf := &bytes.Buffer{}
+	marshaler.Marshal(buf, st.Proto())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
+
+func httpStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
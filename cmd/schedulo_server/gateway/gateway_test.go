@@ -0,0 +1,298 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type _schedulerServerMock struct {
+	api.UnimplementedSchedulerServer
+	mu     *sync.Mutex
+	events map[string]*api.Event
+}
+
+func (s *_schedulerServerMock) Schedule(ctx context.Context, req *api.ScheduleRequest) (*api.ScheduleResponse, error) {
+	if p, _ := auth.FromContext(ctx); p != "billing" {
+		return nil, status.Error(codes.PermissionDenied, "unexpected principal")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req.Event.Id = "1"
+	s.events[req.Event.Id] = req.Event
+
+	return &api.ScheduleResponse{Id: &api.Event_ID{Id: req.Event.Id}}, nil
+}
+
+func (s *_schedulerServerMock) PreviewSchedule(ctx context.Context, req *api.PreviewScheduleRequest) (*api.PreviewScheduleResponse, error) {
+	if req.Event.CronExpression != "0 8 * * *" {
+		return &api.PreviewScheduleResponse{Error: "invalid cron expression"}, nil
+	}
+
+	return &api.PreviewScheduleResponse{Valid: true, Description: "at 08:00 every day (UTC)", Occurrences: make([]int64, req.Count)}, nil
+}
+
+func (s *_schedulerServerMock) Unschedule(ctx context.Context, req *api.UnscheduleRequest) (*api.UnscheduleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[req.Id.Id]; !ok {
+		return nil, status.Error(codes.NotFound, "unknown event")
+	}
+
+	delete(s.events, req.Id.Id)
+
+	return &api.UnscheduleResponse{}, nil
+}
+
+func (s *_schedulerServerMock) ListEvents(ctx context.Context, req *api.ListEventsRequest) (*api.ListEventsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &api.ListEventsResponse{}
+
+	for _, e := range s.events {
+		if req.Topic == "" || e.Topic == req.Topic {
+			resp.Events = append(resp.Events, e)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *_schedulerServerMock) StreamEvents(req *api.StreamEventsRequest, stream api.Scheduler_StreamEventsServer) error {
+	if req.Topic == "forbidden" {
+		return status.Error(codes.PermissionDenied, "forbidden topic")
+	}
+
+	md := metadata.MD{}
+
+	if req.ResumeToken != "" {
+		md.Set("schedulo-resume-gap", "true")
+	}
+
+	if err := stream.SendHeader(md); err != nil {
+		return err
+	}
+
+	if err := stream.Send(&api.StreamEventsResponse{Event: &api.Event{Id: "1", Topic: req.Topic}, Sequence: 2, ResumeToken: req.ResumeToken + ".2"}); err != nil {
+		return err
+	}
+
+	<-stream.Context().Done()
+
+	return stream.Context().Err()
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	srv := &_schedulerServerMock{
+		mu:     &sync.Mutex{},
+		events: make(map[string]*api.Event),
+	}
+
+	ts := httptest.NewServer(New(srv, auth.StaticKeys{"s3cr3t": "billing"}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func do(t *testing.T, method string, url string, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		resp.Body.Close()
+	})
+
+	return resp
+}
+
+func TestGateway_Events(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := do(t, http.MethodPost, ts.URL+"/events", `{"topic": "billing", "shouldExecuteAt": 1600000000, "payload": "aGVsbG8="}`)
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusCreated, resp.StatusCode)
+	}
+
+	resp = do(t, http.MethodGet, ts.URL+"/events?topic=billing", "")
+
+	var list struct {
+		Events []struct {
+			ID              string `json:"id"`
+			Topic           string `json:"topic"`
+			ShouldExecuteAt string `json:"shouldExecuteAt"`
+			Mode            string `json:"mode"`
+			Payload         []byte `json:"payload"`
+		} `json:"events"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list.Events) != 1 {
+		t.Fatalf("Wrong number of events: expected:%d, got:%d\n", 1, len(list.Events))
+	}
+
+	if e := list.Events[0]; e.ID != "1" || e.Mode != "TIMESTAMP" || e.ShouldExecuteAt != "1600000000" || string(e.Payload) != "hello" {
+		t.Fatalf("Wrong event: %+v\n", e)
+	}
+
+	if resp := do(t, http.MethodDelete, ts.URL+"/events/1", ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusNoContent, resp.StatusCode)
+	}
+
+	if resp := do(t, http.MethodDelete, ts.URL+"/events/1", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusNotFound, resp.StatusCode)
+	}
+
+	if resp := do(t, http.MethodPost, ts.URL+"/events", `{"topic": 42}`); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	if resp := do(t, http.MethodPut, ts.URL+"/events", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestGateway_Preview(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := do(t, http.MethodPost, ts.URL+"/events/preview", `{"event": {"mode": "CRON", "cronExpression": "0 8 * * *"}, "count": 3}`)
+
+	var preview struct {
+		Valid       bool     `json:"valid"`
+		Error       string   `json:"error"`
+		Description string   `json:"description"`
+		Occurrences []string `json:"occurrences"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&preview); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !preview.Valid || preview.Description == "" || len(preview.Occurrences) != 3 {
+		t.Fatalf("Wrong preview: %d %+v\n", resp.StatusCode, preview)
+	}
+
+	resp = do(t, http.MethodPost, ts.URL+"/events/preview", `{"event": {"mode": "CRON", "cronExpression": "0 8 * * MON-FRY"}}`)
+
+	preview.Valid = true
+
+	if err := json.NewDecoder(resp.Body).Decode(&preview); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || preview.Valid || preview.Error == "" {
+		t.Fatalf("An invalid event must be reported in the response: %d %+v\n", resp.StatusCode, preview)
+	}
+
+	if resp := do(t, http.MethodGet, ts.URL+"/events/preview", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestGateway_Unauthenticated(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/events")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	// The OpenAPI document is public
+	resp, err = http.Get(ts.URL + "/openapi.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	var doc map[string]interface{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("The OpenAPI document is not valid JSON: %v\n", err)
+	}
+}
+
+func TestGateway_Stream(t *testing.T) {
+	ts := newTestServer(t)
+
+	if resp := do(t, http.MethodGet, ts.URL+"/events/stream?topic=forbidden", ""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Wrong status code: expected:%d, got:%d\n", http.StatusForbidden, resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events/stream?topic=billing", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	req.Header.Set("Last-Event-ID", "abc")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Wrong content type: expected:%s, got:%s\n", "text/event-stream", ct)
+	}
+
+	if gap := resp.Header.Get("Schedulo-Resume-Gap"); gap != "true" {
+		t.Fatalf("The gap of the resumed stream must be reported: got %q\n", gap)
+	}
+
+	r := bufio.NewReader(resp.Body)
+
+	var lines []string
+
+	for len(lines) < 3 {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	if lines[0] != "id: abc.2" || lines[1] != "event: event" || !strings.HasPrefix(lines[2], "data: {") || !strings.Contains(lines[2], `"topic":"billing"`) {
+		t.Fatalf("Wrong message: %q\n", lines)
+	}
+}
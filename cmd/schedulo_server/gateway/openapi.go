@@ -0,0 +1,431 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+)
+
+// openAPIDocument describes the REST gateway, the messages are the JSON mapping of api/api.proto
+const openAPIDocument = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Schedulo",
+    "description": "REST gateway of the Schedulo Scheduler service",
+    "version": "1.0.0"
+  },
+  "components": {
+    "securitySchemes": {
+      "bearer": {
+        "type": "http",
+        "scheme": "bearer",
+        "description": "API key or JWT, only required when authentication is enabled"
+      }
+    },
+    "schemas": {
+      "Event": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "readOnly": true
+          },
+          "cronExpression": {
+            "type": "string",
+            "description": "Cron expression of the CRON events, seconds are optional"
+          },
+          "shouldExecuteAt": {
+            "type": "string",
+            "format": "int64",
+            "description": "Unix timestamp, numbers are accepted as well"
+          },
+          "mode": {
+            "type": "string",
+            "enum": ["TIMESTAMP", "CRON", "INTERVAL", "RRULE"],
+            "default": "TIMESTAMP"
+          },
+          "topic": {
+            "type": "string",
+            "description": "Dot-separated levels, e.g. billing.invoice.due. Events without topic are only streamed to the subscribers of >"
+          },
+          "payload": {
+            "type": "string",
+            "format": "byte"
+          },
+          "labels": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            },
+            "description": "Key/value pairs the subscribers can filter the events of a topic on"
+          },
+          "timeZone": {
+            "type": "string",
+            "description": "IANA time zone the cron expression is evaluated in, e.g. Europe/Paris, the zone of the server by default. A CRON_TZ= prefix of the cron expression is used as well"
+          },
+          "startAt": {
+            "type": "string",
+            "format": "int64",
+            "description": "Unix timestamp of the first possible occurrence of a recurring event"
+          },
+          "endAt": {
+            "type": "string",
+            "format": "int64",
+            "description": "Unix timestamp of the last possible occurrence of a recurring event"
+          },
+          "maxOccurrences": {
+            "type": "integer",
+            "description": "The recurring event is unscheduled after this number of occurrences, it recurs until endAt if 0"
+          },
+          "fireCount": {
+            "type": "integer",
+            "readOnly": true,
+            "description": "Number of occurrences already dispatched"
+          },
+          "misfirePolicy": {
+            "type": "string",
+            "enum": ["FIRE_ONCE", "FIRE_ALL", "SKIP"],
+            "default": "FIRE_ONCE",
+            "description": "What happens to the occurrences of a recurring event missed while the scheduler was down: a single one is dispatched, every one up to the 100 most recent, or none"
+          },
+          "lastFiredAt": {
+            "type": "string",
+            "format": "int64",
+            "readOnly": true,
+            "description": "Unix timestamp of the last dispatched occurrence"
+          },
+          "intervalSeconds": {
+            "type": "string",
+            "format": "int64",
+            "description": "Duration between two occurrences of an INTERVAL event, at least 1 second"
+          },
+          "anchor": {
+            "type": "string",
+            "format": "int64",
+            "description": "Unix timestamp the occurrences of an INTERVAL event are aligned on, the first occurrence if 0. It is the start of the rules of a RRULE event which have no DTSTART"
+          },
+          "rrule": {
+            "type": "string",
+            "description": "RFC 5545 RRULE, EXRULE, RDATE and EXDATE lines of a RRULE event, e.g. RRULE:FREQ=MONTHLY;BYDAY=2TU. The rules start at their DTSTART line if any, at the anchor otherwise"
+          },
+          "calendar": {
+            "type": "string",
+            "description": "Name of the calendar restricting the occurrences of a recurring event to business days and hours"
+          },
+          "calendarPolicy": {
+            "type": "string",
+            "enum": ["CALENDAR_SKIP", "CALENDAR_SHIFT"],
+            "default": "CALENDAR_SKIP",
+            "description": "What happens to the occurrences outside the calendar: they are dropped, or moved to the next business day or opening time"
+          },
+          "jitterMs": {
+            "type": "string",
+            "format": "int64",
+            "description": "Window in milliseconds within which each occurrence is delayed to spread the load, the default jitter of the topic if 0 and no jitter at all if -1"
+          },
+          "jitterMode": {
+            "type": "string",
+            "enum": ["JITTER_HASH", "JITTER_RANDOM"],
+            "default": "JITTER_HASH",
+            "description": "Whether every occurrence is delayed by the same offset derived from the id, or each by its own pseudo-random offset"
+          }
+        }
+      },
+      "PreviewScheduleRequest": {
+        "type": "object",
+        "properties": {
+          "event": {
+            "$ref": "#/components/schemas/Event"
+          },
+          "count": {
+            "type": "integer",
+            "description": "Number of occurrences to list, at most 100, 10 if 0"
+          }
+        }
+      },
+      "PreviewScheduleResponse": {
+        "type": "object",
+        "properties": {
+          "valid": {
+            "type": "boolean"
+          },
+          "error": {
+            "type": "string",
+            "description": "Reason why the event would be rejected by Schedule"
+          },
+          "description": {
+            "type": "string",
+            "description": "English description of the schedule, e.g. at 08:00 on Monday to Friday (Europe/Paris)"
+          },
+          "occurrences": {
+            "type": "array",
+            "description": "Unix timestamps of the next occurrences, without the jitter",
+            "items": {
+              "type": "string",
+              "format": "int64"
+            }
+          }
+        }
+      },
+      "ScheduleResponse": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "object",
+            "properties": {
+              "id": {
+                "type": "string"
+              }
+            }
+          }
+        }
+      },
+      "ListEventsResponse": {
+        "type": "object",
+        "properties": {
+          "events": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Event"
+            }
+          }
+        }
+      },
+      "Status": {
+        "type": "object",
+        "description": "gRPC status of the failed call",
+        "properties": {
+          "code": {
+            "type": "integer"
+          },
+          "message": {
+            "type": "string"
+          },
+          "details": {
+            "type": "array",
+            "items": {
+              "type": "object"
+            }
+          }
+        }
+      }
+    },
+    "responses": {
+      "Error": {
+        "description": "The call failed",
+        "content": {
+          "application/json": {
+            "schema": {
+              "$ref": "#/components/schemas/Status"
+            }
+          }
+        }
+      }
+    }
+  },
+  "security": [
+    {
+      "bearer": []
+    }
+  ],
+  "paths": {
+    "/events": {
+      "get": {
+        "summary": "Lists the scheduled events",
+        "operationId": "ListEvents",
+        "parameters": [
+          {
+            "name": "topic",
+            "in": "query",
+            "description": "Only lists the events of this topic",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The scheduled events",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ListEventsResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "$ref": "#/components/responses/Error"
+          }
+        }
+      },
+      "post": {
+        "summary": "Schedules an event",
+        "operationId": "Schedule",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/Event"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "The ID of the scheduled event",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ScheduleResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "$ref": "#/components/responses/Error"
+          }
+        }
+      }
+    },
+    "/events/preview": {
+      "post": {
+        "summary": "Validates an event without scheduling it and lists its next occurrences",
+        "operationId": "PreviewSchedule",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/PreviewScheduleRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "Whether the event is valid, and its schedule if it is",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PreviewScheduleResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "$ref": "#/components/responses/Error"
+          }
+        }
+      }
+    },
+    "/events/{id}": {
+      "delete": {
+        "summary": "Unschedules an event",
+        "operationId": "Unschedule",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "The event is no longer scheduled"
+          },
+          "default": {
+            "$ref": "#/components/responses/Error"
+          }
+        }
+      }
+    },
+    "/events/stream": {
+      "get": {
+        "summary": "Streams the dispatched events as Server-Sent Events",
+        "operationId": "StreamEvents",
+        "description": "Each dispatched event is sent as an SSE message named event whose data is the JSON event",
+        "parameters": [
+          {
+            "name": "topic",
+            "in": "query",
+            "description": "Topic pattern, * matches one level and > the trailing levels, > alone streams every event",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "group",
+            "in": "query",
+            "description": "The subscribers of a topic sharing a group receive each event once, in turn",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "filter",
+            "in": "query",
+            "description": "Only streams the events whose labels match the expression, e.g. tenant = acme and region in (eu, us)",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "resumeToken",
+            "in": "query",
+            "description": "ID of the last message received, the events dispatched since are streamed first",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "Last-Event-ID",
+            "in": "header",
+            "description": "Sent by EventSource when it reconnects, it takes precedence over resumeToken",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The stream of the dispatched events, the ID of each message is its resume token",
+            "headers": {
+              "Schedulo-Resume-Gap": {
+                "description": "Set to true when some of the events dispatched since the resume token can not be replayed anymore",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "text/event-stream": {
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "default": {
+            "$ref": "#/components/responses/Error"
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+func (g *Gateway) openAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, openAPIDocument)
+}
@@ -0,0 +1,157 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/api"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// keepAliveInterval is the interval of the comments sent to keep idle streams open through proxies
+const keepAliveInterval = 15 * time.Second
+
+var errNotSupported = errors.New("not supported by the REST gateway")
+
+// eventStream adapts a Server-Sent Events response to the StreamEvents handler
+type eventStream struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	f       http.Flusher
+	mu      *sync.Mutex
+	started bool
+	closed  bool
+}
+
+func newEventStream(ctx context.Context, w http.ResponseWriter, f http.Flusher) *eventStream {
+	return &eventStream{
+		ctx: ctx,
+		w:   w,
+		f:   f,
+		mu:  &sync.Mutex{},
+	}
+}
+
+func (s *eventStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *eventStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *eventStream) SetTrailer(metadata.MD) {
+}
+
+// SendHeader starts the response, it is called once the subscription is registered.
+// The metadata are sent as HTTP headers
+func (s *eventStream) SendHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return io.EOF
+	}
+
+	if s.started {
+		return nil
+	}
+
+	s.started = true
+
+	h := s.w.Header()
+
+	for k, v := range md {
+		h[http.CanonicalHeaderKey(k)] = v
+	}
+
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+
+	s.w.WriteHeader(http.StatusOK)
+	s.f.Flush()
+
+	go s.keepAlive()
+
+	return nil
+}
+
+func (s *eventStream) keepAlive() {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(":\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *eventStream) Send(resp *api.StreamEventsResponse) error {
+	buf := &bytes.Buffer{}
+
+	if err := marshaler.Marshal(buf, resp.Event); err != nil {
+		return err
+	}
+
+	// The browsers send the ID of the last message in the Last-Event-ID header when they reconnect
+	id := resp.ResumeToken
+
+	if id == "" {
+		id = resp.Event.Id
+	}
+
+	return s.write(fmt.Sprintf("id: %s\nevent: event\ndata: %s\n\n", id, buf))
+}
+
+func (s *eventStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || !s.started || s.ctx.Err() != nil {
+		return io.EOF
+	}
+
+	if _, err := io.WriteString(s.w, msg); err != nil {
+		return err
+	}
+
+	s.f.Flush()
+
+	return nil
+}
+
+func (s *eventStream) SendMsg(m interface{}) error {
+	resp, ok := m.(*api.StreamEventsResponse)
+
+	if !ok {
+		return errNotSupported
+	}
+
+	return s.Send(resp)
+}
+
+func (s *eventStream) RecvMsg(m interface{}) error {
+	return errNotSupported
+}
+
+// close stops the writes to the response and tells whether it was started
+func (s *eventStream) close() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return s.started
+}
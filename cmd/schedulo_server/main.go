@@ -2,53 +2,93 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	circuit "github.com/rubyist/circuitbreaker"
 	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
 	"github.com/yanishoss/schedulo/cmd/schedulo_server/config"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/gateway"
 	"github.com/yanishoss/schedulo/cmd/schedulo_server/server"
 	"github.com/yanishoss/schedulo/internal/core"
+	"github.com/yanishoss/schedulo/internal/tlsutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gopkg.in/yaml.v2"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
 )
 
 func main() {
-	home, err := os.UserHomeDir()
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "validate" {
+		os.Exit(validateConfig(os.Args[3:]))
+	}
+
+	cfg, loader, err := loadConfig(flag.CommandLine, os.Args[1:])
 
 	if err != nil {
 		log.Fatalln(err)
-		return
 	}
 
-	confPath := flag.String("path-to-config", home+"/schedulo.config.yaml", "path to the Schedulo configuration file")
-	port := flag.Int("port", 9876, "port is the tcp port on which the server will be listening")
-	addr := flag.String("addr", "0.0.0.0", "addr is the tcp address the server will be listening on")
-	flag.Parse()
+	cb := circuit.NewConsecutiveBreaker(50)
 
-	cfg := config.GetConfig(*confPath)
+	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Network.Addr, cfg.Network.Port))
 
-	if *port != 9876 {
-		cfg.Network.Port = *port
+	if err != nil {
+		log.Fatalf("failed to listen: %v\n", err)
 	}
 
-	if *addr != "0.0.0.0" {
-		cfg.Network.Addr = *addr
+	ctx := context.Background()
+
+	var opts []grpc.ServerOption
+	var tlsConf *tls.Config
+
+	if cfg.TLS.Cert != "" {
+		tlsConf, err = tlsutil.ServerConfig(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.ClientCA)
+
+		if err != nil {
+			log.Fatalf("failed to load TLS certificates: %v\n", err)
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
 	}
 
-	cb := circuit.NewConsecutiveBreaker(50)
+	var srvOpts []server.Option
+	var authenticator auth.Authenticator
 
-	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Network.Addr, cfg.Network.Port))
+	if cfg.Auth.Enabled {
+		authenticator, err = cfg.Auth.Authenticator()
+
+		if err != nil {
+			log.Fatalf("failed to initialize authentication: %v\n", err)
+		}
+
+		opts = append(opts,
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(authenticator)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(authenticator)),
+		)
+
+		srvOpts = append(srvOpts, server.WithPolicy(cfg.Auth.Policy()))
+	}
+
+	sinks, routes, err := cfg.SinkRegistry()
 
 	if err != nil {
-		log.Fatalf("failed to listen: %v\n", err)
+		log.Fatalf("failed to initialize sinks: %v\n", err)
 	}
 
-	ctx := context.Background()
+	defer sinks.Close()
 
-	grpcServer := grpc.NewServer()
+	srvOpts = append(srvOpts, server.WithSinks(sinks, routes), server.WithSubscriberOptions(cfg.Subscribers.Options()), server.WithResumeLog(cfg.Subscribers.ResumeLogSize))
+
+	grpcServer := grpc.NewServer(opts...)
 
 	var cache core.CacheManager
 
@@ -81,29 +121,142 @@ func main() {
 		log.Fatalf("failed to initialize SQL: %v\n", err)
 	}
 
-	srv, err := server.New(ctx, core.SchedulerConfig{
-		StackManagerConfig: core.StackManagerConfig{
-			StacksNumber:         cfg.System.StacksNumber,
-			DefaultStackCapacity: cfg.System.DefaultStackCapacity,
-			MaxStackCapacity:     cfg.System.MaxStackCapacity,
-		},
-		DispatchManagerConfig: core.DispatchManagerConfig{
-			WorkerNumber:         cfg.Dispatch.WorkersNumber,
-			DefaultQueueCapacity: cfg.Dispatch.DefaultQueueCapacity,
-			MaxQueueCapacity:     cfg.Dispatch.MaxQueueCapacity,
-		},
-		DefaultInputQueueCapacity: cfg.Input.DefaultQueueCapacity,
-		MaxInputQueueCapacity:     cfg.Input.MaxQueueCapacity,
-		MaxBulkLimit:              cfg.Input.MaxBulkLimit,
-	}, pers, cache)
+	srv, err := server.New(ctx, cfg.SchedulerConfig(), pers, cache, srvOpts...)
 
 	if err != nil {
 		log.Fatalf("failed to initialize server: %v\n", err)
 	}
 
+	calendars, err := cfg.CoreCalendars()
+
+	if err != nil {
+		log.Fatalf("failed to load calendars: %v\n", err)
+	}
+
+	if err := srv.ReloadCalendars(calendars); err != nil {
+		log.Fatalf("failed to apply calendars: %v\n", err)
+	}
+
 	api.RegisterSchedulerServer(grpcServer, srv)
+	api.RegisterAdminServer(grpcServer, srv)
+
+	if cfg.REST.Enabled {
+		go serveREST(cfg.REST, gateway.New(srv, authenticator), tlsConf)
+	}
+
+	watcher := config.NewWatcher(loader, config.DefaultWatchInterval, func(newCfg config.Config) {
+		if newCfg.Database != cfg.Database || newCfg.Cache != cfg.Cache || newCfg.Network != cfg.Network || newCfg.REST != cfg.REST || newCfg.Subscribers != cfg.Subscribers || newCfg.TLS != cfg.TLS {
+			log.Println("the database, cache, network, REST, subscribers and TLS settings are only applied at startup")
+		}
+
+		if !reflect.DeepEqual(newCfg.Auth, cfg.Auth) || !reflect.DeepEqual(newCfg.Sinks, cfg.Sinks) || !reflect.DeepEqual(newCfg.Routes, cfg.Routes) {
+			log.Println("the authentication, sink and route settings are only applied at startup")
+		}
+
+		if err := srv.Reconfigure(newCfg.SchedulerConfig()); err != nil {
+			log.Printf("failed to apply configuration: %v\n", err)
+			return
+		}
+
+		// The calendars are read again from the database as well, for the changes made by other instances
+		calendars, err := newCfg.CoreCalendars()
+
+		if err == nil {
+			err = srv.ReloadCalendars(calendars)
+		}
+
+		if err != nil {
+			log.Printf("failed to apply calendars: %v\n", err)
+			return
+		}
+
+		log.Printf("configuration reloaded from %s\n", loader.Path)
+	}, func(err error) {
+		log.Printf("failed to reload configuration: %v\n", err)
+	})
+
+	go watcher.Run(ctx)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			watcher.Reload()
+		}
+	}()
 
 	log.Printf("listening to tcp://%s:%d\n", cfg.Network.Addr, cfg.Network.Port)
 
 	log.Fatalf("failed to serve: %v\n", grpcServer.Serve(lis))
 }
+
+// serveREST serves the REST gateway, over TLS if tlsConf is not nil
+func serveREST(conf config.RESTConfig, h http.Handler, tlsConf *tls.Config) {
+	httpServer := &http.Server{
+		Addr:      fmt.Sprintf("%s:%d", conf.Addr, conf.Port),
+		Handler:   h,
+		TLSConfig: tlsConf,
+	}
+
+	log.Printf("REST gateway listening to %s\n", httpServer.Addr)
+
+	if tlsConf != nil {
+		log.Fatalf("failed to serve REST gateway: %v\n", httpServer.ListenAndServeTLS("", ""))
+	}
+
+	log.Fatalf("failed to serve REST gateway: %v\n", httpServer.ListenAndServe())
+}
+
+// loadConfig parses the command line and returns the effective configuration along with its loader
+func loadConfig(fs *flag.FlagSet, args []string) (config.Config, config.Loader, error) {
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return config.Config{}, config.Loader{}, err
+	}
+
+	defaultPath := home + "/schedulo.config.yaml"
+
+	confPath := fs.String("path-to-config", defaultPath, "path to the Schedulo configuration file")
+	flags := config.RegisterFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return config.Config{}, config.Loader{}, err
+	}
+
+	loader := config.Loader{
+		Path: *confPath,
+		// The configuration file is optional as long as its path is not given explicitly
+		Optional: *confPath == defaultPath,
+		Flags:    flags,
+	}
+
+	cfg, err := loader.Load()
+
+	return cfg, loader, err
+}
+
+// validateConfig implements the `config validate` subcommand, it prints the effective configuration
+// without its secrets and returns the exit code
+func validateConfig(args []string) int {
+	cfg, loader, err := loadConfig(flag.NewFlagSet("config validate", flag.ExitOnError), args)
+
+	var invalid config.Errors
+
+	// The configuration is only printed once it has been read successfully
+	if err == nil || errors.As(err, &invalid) {
+		if out, mErr := yaml.Marshal(cfg.Redacted()); mErr == nil {
+			fmt.Printf("# effective configuration (%s)\n%s", loader.Path, out)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Println("# the configuration is valid")
+
+	return 0
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"github.com/yanishoss/schedulo/internal/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// Reconfigure applies a new configuration to the running scheduler
+func (s *Server) Reconfigure(conf core.SchedulerConfig) error {
+	return s.scheduler.SetConfig(conf)
+}
+
+// ReloadCalendars reads the persisted calendars again and replaces the ones of the configuration,
+// which are kept in memory only and cannot be changed through the API
+func (s *Server) ReloadCalendars(cals []core.Calendar) error {
+	if err := s.scheduler.ReloadCalendars(); err != nil {
+		return err
+	}
+
+	return s.scheduler.ConfigureCalendars(cals)
+}
+
+func (s *Server) GetConfig(ctx context.Context, req *api.GetConfigRequest) (*api.GetConfigResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+		return &api.GetConfigResponse{}, err
+	}
+
+	conf := coreConfigToApiConfig(s.scheduler.Config())
+
+	return &api.GetConfigResponse{Config: &conf}, nil
+}
+
+func (s *Server) SetConfig(ctx context.Context, req *api.SetConfigRequest) (*api.SetConfigResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+		return &api.SetConfigResponse{}, err
+	}
+
+	conf := mergeApiConfig(s.scheduler.Config(), req.Config)
+
+	// The configuration set through the API is checked against the rules of the configuration file
+	if errs := conf.Validate(); len(errs) > 0 {
+		msgs := make([]string, len(errs))
+
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+
+		return &api.SetConfigResponse{}, status.Error(codes.InvalidArgument, strings.Join(msgs, ", "))
+	}
+
+	if err := s.Reconfigure(conf); err != nil {
+		if errors.Is(err, core.ErrMaxStackCapacity) {
+			return &api.SetConfigResponse{}, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return &api.SetConfigResponse{}, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := coreConfigToApiConfig(s.scheduler.Config())
+
+	return &api.SetConfigResponse{Config: &resp}, nil
+}
+
+func (s *Server) ListSubscribers(ctx context.Context, req *api.ListSubscribersRequest) (*api.ListSubscribersResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, req.Topic); err != nil {
+		return &api.ListSubscribersResponse{}, err
+	}
+
+	subs := s.subs.list(req.Topic)
+
+	resp := &api.ListSubscribersResponse{Subscribers: make([]*api.Subscriber, len(subs))}
+
+	for i, sub := range subs {
+		st := sub.stats()
+
+		resp.Subscribers[i] = &api.Subscriber{
+			Id:             sub.id,
+			Topic:          sub.topic,
+			Group:          sub.group,
+			Peer:           sub.peer,
+			Principal:      string(sub.principal),
+			ConnectedSince: sub.connectedAt.Unix(),
+			Sent:           st.Sent,
+			Dropped:        st.Dropped,
+			Overflows:      st.Overflows,
+			Buffered:       int32(st.Buffered),
+			Filter:         sub.filterExpr,
+		}
+	}
+
+	return resp, nil
+}
+
+// DisconnectSubscriber ends the stream of the subscriber with ErrDisconnected
+func (s *Server) DisconnectSubscriber(ctx context.Context, req *api.DisconnectSubscriberRequest) (*api.DisconnectSubscriberResponse, error) {
+	sub, ok := s.subs.get(req.Id)
+
+	if !ok {
+		// The subscribers of the topics the principal cannot administrate are not revealed
+		if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+			return &api.DisconnectSubscriberResponse{}, err
+		}
+
+		return &api.DisconnectSubscriberResponse{}, status.Errorf(codes.NotFound, "no subscriber %d", req.Id)
+	}
+
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, sub.topic); err != nil {
+		return &api.DisconnectSubscriberResponse{}, err
+	}
+
+	sub.close(ErrDisconnected)
+
+	return &api.DisconnectSubscriberResponse{}, nil
+}
+
+func (s *Server) SetCalendar(ctx context.Context, req *api.SetCalendarRequest) (*api.SetCalendarResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+		return &api.SetCalendarResponse{}, err
+	}
+
+	if req.Calendar == nil {
+		return &api.SetCalendarResponse{}, status.Error(codes.InvalidArgument, "no calendar")
+	}
+
+	c, err := apiCalendarToCoreCalendar(*req.Calendar)
+
+	if err == nil {
+		err = core.ValidateCalendar(c)
+	}
+
+	if err != nil {
+		return &api.SetCalendarResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.scheduler.SetCalendar(c); err != nil {
+		if err == core.ErrConfiguredCalendar {
+			return &api.SetCalendarResponse{}, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return &api.SetCalendarResponse{}, err
+	}
+
+	return &api.SetCalendarResponse{}, nil
+}
+
+// DeleteCalendar deletes the calendar, the events referring to it are no longer restricted
+func (s *Server) DeleteCalendar(ctx context.Context, req *api.DeleteCalendarRequest) (*api.DeleteCalendarResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+		return &api.DeleteCalendarResponse{}, err
+	}
+
+	err := s.scheduler.DeleteCalendar(req.Name)
+
+	if err == core.ErrUnknownCalendar {
+		return &api.DeleteCalendarResponse{}, status.Errorf(codes.NotFound, "no calendar %q", req.Name)
+	}
+
+	if err == core.ErrConfiguredCalendar {
+		return &api.DeleteCalendarResponse{}, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return &api.DeleteCalendarResponse{}, err
+}
+
+func (s *Server) ListCalendars(ctx context.Context, req *api.ListCalendarsRequest) (*api.ListCalendarsResponse, error) {
+	if err := s.policy.Authorize(ctx, auth.OpAdmin, ""); err != nil {
+		return &api.ListCalendarsResponse{}, err
+	}
+
+	cals := s.scheduler.Calendars()
+
+	resp := &api.ListCalendarsResponse{Calendars: make([]*api.Calendar, len(cals))}
+
+	for i, c := range cals {
+		days := make([]string, len(c.WorkingDays))
+
+		for j, d := range c.WorkingDays {
+			days[j] = d.String()
+		}
+
+		resp.Calendars[i] = &api.Calendar{
+			Name:          c.Name,
+			TimeZone:      c.TimeZone,
+			ExcludedDates: c.ExcludedDates,
+			WorkingDays:   days,
+			WorkingHours:  c.WorkingHours,
+		}
+	}
+
+	return resp, nil
+}
+
+func apiCalendarToCoreCalendar(c api.Calendar) (core.Calendar, error) {
+	cal := core.Calendar{
+		Name:          c.Name,
+		TimeZone:      c.TimeZone,
+		ExcludedDates: c.ExcludedDates,
+		WorkingHours:  c.WorkingHours,
+	}
+
+	for _, name := range c.WorkingDays {
+		d, err := core.ParseWeekday(name)
+
+		if err != nil {
+			return cal, err
+		}
+
+		cal.WorkingDays = append(cal.WorkingDays, d)
+	}
+
+	return cal, nil
+}
+
+func coreConfigToApiConfig(c core.SchedulerConfig) api.Config {
+	return api.Config{
+		System: &api.Config_System{
+			StacksNumber:         int32(c.StacksNumber),
+			DefaultStackCapacity: int32(c.DefaultStackCapacity),
+			MaxStackCapacity:     int32(c.MaxStackCapacity),
+		},
+		Dispatch: &api.Config_Dispatch{
+			WorkersNumber:        int32(c.WorkerNumber),
+			DefaultQueueCapacity: int32(c.DefaultQueueCapacity),
+			MaxQueueCapacity:     int32(c.MaxQueueCapacity),
+		},
+		Input: &api.Config_Input{
+			DefaultQueueCapacity: int32(c.DefaultInputQueueCapacity),
+			MaxQueueCapacity:     int32(c.MaxInputQueueCapacity),
+			MaxBulkLimit:         int32(c.MaxBulkLimit),
+		},
+	}
+}
+
+// mergeApiConfig overrides the current configuration with the non-zero values of the request
+func mergeApiConfig(c core.SchedulerConfig, req *api.Config) core.SchedulerConfig {
+	if req == nil {
+		return c
+	}
+
+	set := func(dst *int, v int32) {
+		if v != 0 {
+			*dst = int(v)
+		}
+	}
+
+	if sys := req.System; sys != nil {
+		set(&c.StacksNumber, sys.StacksNumber)
+		set(&c.DefaultStackCapacity, sys.DefaultStackCapacity)
+		set(&c.MaxStackCapacity, sys.MaxStackCapacity)
+	}
+
+	if dp := req.Dispatch; dp != nil {
+		set(&c.WorkerNumber, dp.WorkersNumber)
+		set(&c.DefaultQueueCapacity, dp.DefaultQueueCapacity)
+		set(&c.MaxQueueCapacity, dp.MaxQueueCapacity)
+	}
+
+	if in := req.Input; in != nil {
+		set(&c.DefaultInputQueueCapacity, in.DefaultQueueCapacity)
+		set(&c.MaxInputQueueCapacity, in.MaxQueueCapacity)
+		set(&c.MaxBulkLimit, in.MaxBulkLimit)
+	}
+
+	return c
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/internal/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+type _configSchedulerMock struct {
+	core.Scheduler
+	conf core.SchedulerConfig
+	set  int
+}
+
+func (s *_configSchedulerMock) Config() core.SchedulerConfig {
+	return s.conf
+}
+
+func (s *_configSchedulerMock) SetConfig(conf core.SchedulerConfig) error {
+	s.conf = conf
+	s.set++
+
+	return nil
+}
+
+func TestServer_SetConfig(t *testing.T) {
+	sch := &_configSchedulerMock{conf: core.SchedulerConfig{
+		StackManagerConfig:        core.StackManagerConfig{StacksNumber: 10, DefaultStackCapacity: 100, MaxStackCapacity: 150},
+		DispatchManagerConfig:     core.DispatchManagerConfig{WorkerNumber: 4, DefaultQueueCapacity: 100, MaxQueueCapacity: 150},
+		DefaultInputQueueCapacity: 100,
+		MaxInputQueueCapacity:     150,
+		MaxBulkLimit:              50,
+	}}
+	s := &Server{scheduler: sch}
+
+	for _, conf := range []*api.Config{
+		{Dispatch: &api.Config_Dispatch{WorkersNumber: -1}},
+		{System: &api.Config_System{DefaultStackCapacity: 200}},
+	} {
+		if _, err := s.SetConfig(context.Background(), &api.SetConfigRequest{Config: conf}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("An invalid configuration must be rejected: expected:%s, got:%v\n", codes.InvalidArgument, err)
+		}
+	}
+
+	if sch.set != 0 {
+		t.Fatalf("An invalid configuration must not be applied: got %d\n", sch.set)
+	}
+
+	resp, err := s.SetConfig(context.Background(), &api.SetConfigRequest{Config: &api.Config{Dispatch: &api.Config_Dispatch{WorkersNumber: 8}}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Config.Dispatch.WorkersNumber != 8 || sch.set != 1 {
+		t.Fatalf("A valid configuration must be applied: got %+v\n", resp.Config.Dispatch)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"github.com/yanishoss/schedulo/internal/core"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// registry holds the subscribers, it is safe for concurrent use by the streams and the dispatch workers
+type registry struct {
+	// lastID is first to be 64-bit aligned, the IDs are never reused
+	lastID int64
+
+	mu     *sync.RWMutex
+	byID   map[int64]*subscriber
+	topics *topicTrie
+
+	// turns counts the events delivered to each group of a topic pattern, to pick its members in turn
+	turns map[groupKey]*uint64
+}
+
+type groupKey struct {
+	topic string
+	group string
+}
+
+func newRegistry() *registry {
+	return &registry{
+		mu:     &sync.RWMutex{},
+		byID:   make(map[int64]*subscriber),
+		topics: newTopicTrie(),
+		turns:  make(map[groupKey]*uint64),
+	}
+}
+
+// add registers the subscriber under a new ID, its topic must be a valid pattern
+func (r *registry) add(s *subscriber) int64 {
+	s.id = atomic.AddInt64(&r.lastID, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[s.id] = s
+	r.topics.add(s)
+
+	if k := (groupKey{s.topic, s.group}); s.group != "" && r.turns[k] == nil {
+		r.turns[k] = new(uint64)
+	}
+
+	return s.id
+}
+
+func (r *registry) remove(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.byID[id]
+
+	if !ok {
+		return
+	}
+
+	delete(r.byID, id)
+	r.topics.remove(s)
+
+	if s.group == "" {
+		return
+	}
+
+	for _, sub := range r.byID {
+		if sub.topic == s.topic && sub.group == s.group {
+			return
+		}
+	}
+
+	delete(r.turns, groupKey{s.topic, s.group})
+}
+
+func (r *registry) get(id int64) (*subscriber, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.byID[id]
+
+	return s, ok
+}
+
+// list returns the subscribers to the topic pattern ordered by ID, every subscriber if it is empty
+func (r *registry) list(topic string) []*subscriber {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []*subscriber
+
+	for _, s := range r.byID {
+		if topic == "" || s.topic == topic {
+			subs = append(subs, s)
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].id < subs[j].id
+	})
+
+	return subs
+}
+
+// targets returns the subscribers the event is delivered to, a single member of each group is picked
+// in turn among the members allowed to read the event and whose filter selects it
+func (r *registry) targets(e core.Event) []*subscriber {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var targets []*subscriber
+
+	groups := make(map[groupKey][]*subscriber)
+
+	for _, s := range r.topics.match(e.Topic) {
+		if !s.selects(e) {
+			continue
+		}
+
+		if s.group == "" {
+			targets = append(targets, s)
+			continue
+		}
+
+		k := groupKey{s.topic, s.group}
+		groups[k] = append(groups[k], s)
+	}
+
+	for k, members := range groups {
+		turn := atomic.AddUint64(r.turns[k], 1)
+		targets = append(targets, members[turn%uint64(len(members))])
+	}
+
+	return targets
+}
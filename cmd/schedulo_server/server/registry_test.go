@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
+	"github.com/yanishoss/schedulo/internal/core"
+	"github.com/yanishoss/schedulo/internal/filter"
+	"sync"
+	"testing"
+)
+
+func newTestSubscriber(topic string, group string) *subscriber {
+	sub := newSubscriber(subscription.Options{BufferSize: 10, Overflow: subscription.DropOldest})
+	sub.topic = topic
+	sub.group = group
+
+	return sub
+}
+
+func TestRegistry_Targets(t *testing.T) {
+	reg := newRegistry()
+
+	a, b := newTestSubscriber("billing", "workers"), newTestSubscriber("billing", "workers")
+	solo, other := newTestSubscriber("billing", ""), newTestSubscriber("shipping", "")
+
+	for _, sub := range []*subscriber{a, b, solo, other} {
+		reg.add(sub)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := notify(context.Background(), message{Event: core.Event{Topic: "billing"}}, reg.targets(core.Event{Topic: "billing"})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(a.events) != 2 || len(b.events) != 2 || len(solo.events) != 4 || len(other.events) != 0 {
+		t.Fatalf("The members of a group must receive the events in turn: expected:%d/%d/%d/%d, got:%d/%d/%d/%d\n", 2, 2, 4, 0, len(a.events), len(b.events), len(solo.events), len(other.events))
+	}
+
+	reg.remove(a.id)
+
+	c := newTestSubscriber("billing", "")
+
+	if reg.add(c) <= other.id {
+		t.Fatalf("The subscriber IDs must not be reused: got %d\n", c.id)
+	}
+
+	if targets := reg.targets(core.Event{Topic: "billing"}); len(targets) != 3 {
+		t.Fatalf("Wrong number of targets: expected:%d, got:%d\n", 3, len(targets))
+	}
+}
+
+func TestRegistry_Filter(t *testing.T) {
+	reg := newRegistry()
+
+	acme, other, all := newTestSubscriber("billing", "workers"), newTestSubscriber("billing", "workers"), newTestSubscriber("billing", "")
+	acme.filter, _ = filter.Parse("tenant = acme")
+	other.filter, _ = filter.Parse("tenant != acme")
+
+	for _, sub := range []*subscriber{acme, other, all} {
+		reg.add(sub)
+	}
+
+	for i := 0; i < 3; i++ {
+		e := core.Event{Topic: "billing", Labels: map[string]string{"tenant": "acme"}}
+
+		if err := notify(context.Background(), message{Event: e}, reg.targets(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(acme.events) != 3 || len(other.events) != 0 || len(all.events) != 3 {
+		t.Fatalf("The group members must be picked among the ones selecting the event: expected:%d/%d/%d, got:%d/%d/%d\n", 3, 0, 3, len(acme.events), len(other.events), len(all.events))
+	}
+}
+
+func TestRegistry_Policy(t *testing.T) {
+	reg := newRegistry()
+	policy := &auth.Policy{Rules: []auth.Rule{
+		{Principals: []string{"billing"}, Topics: []string{"billing.*"}, Operations: []auth.Operation{auth.OpStream}},
+		{Principals: []string{"audit"}, Topics: []string{"billing.audit"}, Operations: []auth.Operation{auth.OpStream}},
+	}}
+
+	billing, audit := newTestSubscriber("billing.*", "workers"), newTestSubscriber("billing.*", "workers")
+
+	for sub, principal := range map[*subscriber]auth.Principal{billing: "billing", audit: "audit"} {
+		principal := principal
+		sub.allowed = func(e core.Event) bool {
+			return policy.Allowed(principal, auth.OpStream, e.Topic)
+		}
+
+		reg.add(sub)
+	}
+
+	for i := 0; i < 4; i++ {
+		e := core.Event{Topic: "billing.invoices"}
+
+		if err := notify(context.Background(), message{Event: e}, reg.targets(e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(billing.events) != 4 || len(audit.events) != 0 {
+		t.Fatalf("The group members must be picked among the ones allowed to read the event: expected:%d/%d, got:%d/%d\n", 4, 0, len(billing.events), len(audit.events))
+	}
+}
+
+func TestRegistry_Concurrency(t *testing.T) {
+	reg := newRegistry()
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			sub := newTestSubscriber("billing", "")
+			reg.remove(reg.add(sub))
+		}()
+
+		go func() {
+			defer wg.Done()
+			notify(context.Background(), message{Event: core.Event{Topic: "billing"}}, reg.targets(core.Event{Topic: "billing"}))
+		}()
+	}
+
+	wg.Wait()
+
+	if subs := reg.list(""); len(subs) != 0 {
+		t.Fatalf("Every subscriber must have been removed: got %d\n", len(subs))
+	}
+}
+
+func TestServer_DisconnectSubscriber(t *testing.T) {
+	s := &Server{subs: newRegistry()}
+
+	sub := newTestSubscriber("billing", "workers")
+	sub.peer = "127.0.0.1:4242"
+	s.subs.add(sub)
+
+	resp, err := s.ListSubscribers(context.Background(), &api.ListSubscribersRequest{Topic: "billing"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Subscribers) != 1 || resp.Subscribers[0].Group != "workers" || resp.Subscribers[0].Peer != sub.peer || resp.Subscribers[0].ConnectedSince == 0 {
+		t.Fatalf("Wrong subscribers: %+v\n", resp.Subscribers)
+	}
+
+	if _, err := s.DisconnectSubscriber(context.Background(), &api.DisconnectSubscriberRequest{Id: sub.id}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sub.run(func(m message) error { return nil }); err != ErrDisconnected {
+		t.Fatalf("The stream of a disconnected subscriber must end: got %v\n", err)
+	}
+
+	if _, err := s.DisconnectSubscriber(context.Background(), &api.DisconnectSubscriberRequest{Id: 42}); err == nil {
+		t.Fatalf("An unknown subscriber must be reported\n")
+	}
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
+	"github.com/yanishoss/schedulo/internal/core"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResumeGapHeader is set in the headers of a resumed stream when some of the events dispatched since
+// the resume token are not in the log anymore, or were dispatched by another instance of the server
+const ResumeGapHeader = "schedulo-resume-gap"
+
+var ErrInvalidResumeToken = errors.New("the resume token is invalid")
+
+// message is a dispatched event along with its position in the dispatch log
+type message struct {
+	core.Event
+
+	seq uint64
+
+	// token is the sequence up to which every dispatch was pushed when the event was dispatched
+	token uint64
+}
+
+// occurrence identifies a dispatch, the attempts of a dispatch share its sequence
+type occurrence struct {
+	id core.ID
+	at int64
+}
+
+func occurrenceOf(e core.Event) occurrence {
+	return occurrence{id: e.ID, at: e.ShouldExecuteAt.UnixNano()}
+}
+
+// dispatchLog keeps the most recent dispatches so that a subscriber reconnecting with a resume token
+// receives the events it missed. The sequences are only meaningful during the epoch of the log,
+// which starts with the server
+type dispatchLog struct {
+	mu      *sync.Mutex
+	epoch   int64
+	last    uint64
+	entries []message
+
+	// seqs are the sequences of the logged occurrences
+	seqs map[occurrence]uint64
+
+	// inflight are the sequences still being pushed to the subscribers
+	inflight map[uint64]struct{}
+}
+
+func newDispatchLog(size int) *dispatchLog {
+	if size <= 0 {
+		size = subscription.DefaultResumeLogSize
+	}
+
+	return &dispatchLog{
+		mu:       &sync.Mutex{},
+		epoch:    time.Now().UnixNano(),
+		entries:  make([]message, size),
+		seqs:     make(map[occurrence]uint64),
+		inflight: make(map[uint64]struct{}),
+	}
+}
+
+// append records the dispatch of the event, done must be called with its sequence once
+// it is pushed to every subscriber. The attempts of a logged occurrence return its message
+func (l *dispatchLog) append(e core.Event) message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if seq, ok := l.seqs[occurrenceOf(e)]; ok {
+		l.inflight[seq] = struct{}{}
+
+		return l.entries[(seq-1)%uint64(len(l.entries))]
+	}
+
+	i := l.last % uint64(len(l.entries))
+
+	// The evicted dispatch cannot be resumed anymore, so it does not hold back the committed sequence
+	if old := l.entries[i]; old.seq != 0 {
+		delete(l.seqs, occurrenceOf(old.Event))
+		delete(l.inflight, old.seq)
+	}
+
+	m := message{Event: e, seq: l.last + 1, token: l.committed()}
+
+	l.last = m.seq
+	l.entries[i] = m
+	l.seqs[occurrenceOf(e)] = m.seq
+	l.inflight[m.seq] = struct{}{}
+
+	return m
+}
+
+func (l *dispatchLog) done(seq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.inflight, seq)
+}
+
+// committed returns the sequence up to which every dispatch was pushed, l.mu must be held
+func (l *dispatchLog) committed() uint64 {
+	c := l.last
+
+	for seq := range l.inflight {
+		if seq <= c {
+			c = seq - 1
+		}
+	}
+
+	return c
+}
+
+// head returns the sequence of the last dispatch
+func (l *dispatchLog) head() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.last
+}
+
+// since returns the dispatches following the token, the sequence of the last dispatch and whether
+// some of the dispatches following the token were lost
+func (l *dispatchLog) since(token string) ([]message, uint64, bool, error) {
+	epoch, after, err := parseResumeToken(token)
+
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	gap := false
+
+	// The token was issued before the server restarted, every logged dispatch was missed
+	if epoch != l.epoch {
+		after = 0
+		gap = true
+	}
+
+	if after > l.last {
+		return nil, 0, false, ErrInvalidResumeToken
+	}
+
+	oldest := uint64(1)
+
+	if size := uint64(len(l.entries)); l.last > size {
+		oldest = l.last - size + 1
+	}
+
+	if after+1 < oldest {
+		after = oldest - 1
+		gap = true
+	}
+
+	msgs := make([]message, 0, l.last-after)
+
+	for seq := after + 1; seq <= l.last; seq++ {
+		msgs = append(msgs, l.entries[(seq-1)%uint64(len(l.entries))])
+	}
+
+	return msgs, l.last, gap, nil
+}
+
+// token returns the resume token of the sequence
+func (l *dispatchLog) token(seq uint64) string {
+	return fmt.Sprintf("%x.%x", l.epoch, seq)
+}
+
+func parseResumeToken(token string) (int64, uint64, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 2 {
+		return 0, 0, ErrInvalidResumeToken
+	}
+
+	epoch, err := strconv.ParseInt(parts[0], 16, 64)
+
+	if err != nil {
+		return 0, 0, ErrInvalidResumeToken
+	}
+
+	seq, err := strconv.ParseUint(parts[1], 16, 64)
+
+	if err != nil {
+		return 0, 0, ErrInvalidResumeToken
+	}
+
+	return epoch, seq, nil
+}
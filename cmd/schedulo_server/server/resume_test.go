@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/internal/core"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+	"time"
+)
+
+type _streamMock struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	headers chan metadata.MD
+	sent    chan *api.StreamEventsResponse
+}
+
+func newStreamMock(ctx context.Context) *_streamMock {
+	return &_streamMock{
+		ctx:     ctx,
+		headers: make(chan metadata.MD, 1),
+		sent:    make(chan *api.StreamEventsResponse, 10),
+	}
+}
+
+func (s *_streamMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *_streamMock) SendHeader(md metadata.MD) error {
+	s.headers <- md
+	return nil
+}
+
+func (s *_streamMock) Send(resp *api.StreamEventsResponse) error {
+	s.sent <- resp
+	return nil
+}
+
+func (s *_streamMock) recv(t *testing.T) *api.StreamEventsResponse {
+	select {
+	case resp := <-s.sent:
+		return resp
+	case <-time.After(time.Second):
+		t.Fatalf("No event was streamed\n")
+		return nil
+	}
+}
+
+func TestDispatchLog(t *testing.T) {
+	l := newDispatchLog(3)
+
+	a, b := l.append(core.Event{ID: "a"}), l.append(core.Event{ID: "b"})
+	l.done(b.seq)
+
+	if c := l.append(core.Event{ID: "c"}); c.token != 0 {
+		t.Fatalf("The token must not cover the dispatches in flight: expected:%d, got:%d\n", 0, c.token)
+	}
+
+	l.done(a.seq)
+
+	if d := l.append(core.Event{ID: "d"}); d.token != 2 {
+		t.Fatalf("Wrong token: expected:%d, got:%d\n", 2, d.token)
+	}
+
+	msgs, last, gap, err := l.since(l.token(2))
+
+	if err != nil || gap || last != 4 || len(msgs) != 2 || msgs[0].ID != "c" || msgs[1].ID != "d" {
+		t.Fatalf("Wrong dispatches since 2: %v, %d, %t, %v\n", msgs, last, gap, err)
+	}
+
+	if msgs, _, gap, _ := l.since(l.token(0)); !gap || len(msgs) != 3 || msgs[0].ID != "b" {
+		t.Fatalf("The evicted dispatches must be reported: %v, %t\n", msgs, gap)
+	}
+
+	if msgs, _, gap, _ := l.since(fmt.Sprintf("%x.%x", l.epoch-1, 4)); !gap || len(msgs) != 3 {
+		t.Fatalf("The tokens of another epoch must replay the whole log: %v, %t\n", msgs, gap)
+	}
+
+	for _, token := range []string{"", "1", "x.1", "1.y", l.token(5)} {
+		if _, _, _, err := l.since(token); err != ErrInvalidResumeToken {
+			t.Fatalf("The token %q must be rejected: got %v\n", token, err)
+		}
+	}
+}
+
+func TestDispatchLog_Retry(t *testing.T) {
+	l := newDispatchLog(2)
+	at := time.Now()
+
+	a := l.append(core.Event{ID: "a", ShouldExecuteAt: at})
+
+	if retried := l.append(core.Event{ID: "a", ShouldExecuteAt: at}); retried.seq != a.seq || l.head() != 1 {
+		t.Fatalf("The attempts of a dispatch must be logged once: expected:%d, got:%d\n", a.seq, retried.seq)
+	}
+
+	if next := l.append(core.Event{ID: "a", ShouldExecuteAt: at.Add(time.Minute)}); next.seq != 2 || next.token != 0 {
+		t.Fatalf("The next occurrence must be logged after the pending one: got %d, %d\n", next.seq, next.token)
+	}
+
+	// The pending dispatch is evicted from the log
+	l.append(core.Event{ID: "b"})
+
+	if c := l.append(core.Event{ID: "c"}); c.token != 2 {
+		t.Fatalf("An evicted dispatch must not hold back the token: expected:%d, got:%d\n", 2, c.token)
+	}
+}
+
+func TestServer_DispatchRetry(t *testing.T) {
+	s := &Server{subs: newRegistry(), log: newDispatchLog(10)}
+	e := core.Event{ID: "1", Topic: "billing", ShouldExecuteAt: time.Now()}
+
+	for i := 0; i < 3; i++ {
+		if err := s.onDispatch(context.Background(), e); err != ErrUnknownTopic {
+			t.Fatalf("The event has no subscriber: got %v\n", err)
+		}
+	}
+
+	if head := s.log.head(); head != 1 {
+		t.Fatalf("The retried dispatch must be logged once: expected:%d, got:%d\n", 1, head)
+	}
+
+	m := s.log.append(core.Event{ID: "2", Topic: "billing"})
+
+	err := s.settle(m, &core.RetryError{Err: errors.New("unavailable"), Retry: func(ctx context.Context, e core.Event) error {
+		return nil
+	}})
+
+	if c := s.log.append(core.Event{ID: "3"}); c.token != 1 {
+		t.Fatalf("The dispatch must be in flight until every subscriber received it: expected:%d, got:%d\n", 1, c.token)
+	}
+
+	if err := err.(*core.RetryError).Retry(context.Background(), m.Event); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := s.log.append(core.Event{ID: "4"}); d.token != 2 {
+		t.Fatalf("The retried dispatch must be done once delivered: expected:%d, got:%d\n", 2, d.token)
+	}
+}
+
+func TestServer_ResumeStream(t *testing.T) {
+	s := &Server{subs: newRegistry(), log: newDispatchLog(10)}
+
+	for _, e := range []core.Event{{ID: "1", Topic: "billing"}, {ID: "2", Topic: "shipping"}, {ID: "3", Topic: "billing"}} {
+		s.onDispatch(context.Background(), e)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newStreamMock(ctx)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- s.StreamEvents(&api.StreamEventsRequest{Topic: "billing", ResumeToken: s.log.token(1)}, stream)
+	}()
+
+	if md := <-stream.headers; len(md.Get(ResumeGapHeader)) != 0 {
+		t.Fatalf("No dispatch was lost: got %v\n", md)
+	}
+
+	if resp := stream.recv(t); resp.Event.Id != "3" || resp.Sequence != 3 || resp.ResumeToken != s.log.token(3) {
+		t.Fatalf("The missed event must be replayed: got %+v\n", resp)
+	}
+
+	if err := s.onDispatch(context.Background(), core.Event{ID: "4", Topic: "billing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := stream.recv(t); resp.Event.Id != "4" || resp.Sequence != 4 || resp.ResumeToken != s.log.token(3) {
+		t.Fatalf("The live events must follow the replay: got %+v\n", resp)
+	}
+
+	cancel()
+	<-errs
+
+	if err := s.StreamEvents(&api.StreamEventsRequest{Topic: "billing", ResumeToken: "nope"}, stream); err == nil {
+		t.Fatalf("An invalid token must be rejected\n")
+	}
+}
@@ -4,8 +4,17 @@ import (
 	"context"
 	"errors"
 	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
 	"github.com/yanishoss/schedulo/internal/core"
-	"io"
+	"github.com/yanishoss/schedulo/internal/filter"
+	"github.com/yanishoss/schedulo/internal/sink"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,90 +22,149 @@ var (
 	ErrUnknownTopic = errors.New("this topic is unknown")
 )
 
-type listener struct {
-	core.DispatchFunc
-	id int64
-}
-type listenerMap map[string][]listener
-
 type Server struct {
 	scheduler core.Scheduler
-	listeners listenerMap
+	subs      *registry
+	policy    *auth.Policy
+	sinks     *sink.Registry
+	routes    []sink.Route
+	subOpts   subscription.Options
+	log       *dispatchLog
 }
 
-func New(ctx context.Context, config core.SchedulerConfig, pers core.PersistenceManager, cache core.CacheManager) (api.SchedulerServer, error) {
-	s := &Server{listeners: make(listenerMap)}
+type Option func(*Server)
 
-	sch := core.NewScheduler(ctx, config, pers, cache, s.onDispatch)
+// WithPolicy restricts the operations of the authenticated principals, every operation is allowed by default
+func WithPolicy(p *auth.Policy) Option {
+	return func(s *Server) {
+		s.policy = p
+	}
+}
 
-	s.scheduler = sch
+// WithSinks routes the events to the sinks of the registry, the events matched by no route
+// are streamed to the subscribers
+func WithSinks(reg *sink.Registry, routes []sink.Route) Option {
+	return func(s *Server) {
+		s.sinks = reg
+		s.routes = routes
+	}
+}
 
-	if err := sch.Start(); err != nil {
-		return nil, err
+// WithSubscriberOptions sets the buffer of each stream and what happens when it overflows
+func WithSubscriberOptions(opts subscription.Options) Option {
+	return func(s *Server) {
+		s.subOpts = opts
 	}
+}
 
-	return s, nil
+// WithResumeLog keeps the given number of dispatches for the subscribers resuming their stream,
+// subscription.DefaultResumeLogSize by default
+func WithResumeLog(size int) Option {
+	return func(s *Server) {
+		s.log = newDispatchLog(size)
+	}
 }
 
-func (s *Server) onDispatch(e core.Event) error {
-	if e.Topic == "" {
-		var err error
+func New(ctx context.Context, config core.SchedulerConfig, pers core.PersistenceManager, cache core.CacheManager, opts ...Option) (*Server, error) {
+	s := &Server{subs: newRegistry()}
 
-		for _, v := range s.listeners {
-			for _, l := range v {
-				if errL := l.DispatchFunc(e); errL != nil {
-					err = errL
-				}
-			}
-		}
+	for _, opt := range opts {
+		opt(s)
+	}
 
-		return err
+	if s.log == nil {
+		s.log = newDispatchLog(subscription.DefaultResumeLogSize)
 	}
 
-	topic, ok := s.listeners[e.Topic]
+	if s.sinks == nil {
+		s.sinks = sink.NewRegistry()
+	}
 
-	if !ok {
-		return ErrUnknownTopic
+	if err := s.sinks.Register(sink.Subscribers, sink.Func(s.onDispatch), sink.Options{}); err != nil {
+		return nil, err
 	}
 
-	var err error
+	router, err := sink.NewRouter(s.sinks, s.routes, sink.Subscribers)
 
-	for _, l := range topic {
-		if errL := l.DispatchFunc(e); errL != nil {
-			err = errL
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return err
+	sch := core.NewScheduler(ctx, config, pers, cache, router.Dispatch)
+
+	s.scheduler = sch
+
+	if err := sch.Start(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
-func (s *Server) registerListener(topic string, l core.DispatchFunc) int64 {
-	if _, ok := s.listeners[topic]; !ok {
-		s.listeners[topic] = make([]listener, 0, 10)
+func (s *Server) onDispatch(ctx context.Context, e core.Event) error {
+	// The event is logged even without subscribers, for the ones about to resume their stream
+	m := s.log.append(e)
+
+	targets := s.subs.targets(e)
+
+	if len(targets) == 0 {
+		s.log.done(m.seq)
+		return ErrUnknownTopic
 	}
 
-	var id int64 = 0
+	return s.settle(m, notify(ctx, m, targets))
+}
+
+// settle marks the dispatch done once every subscriber received it, the failed ones are retried until then
+func (s *Server) settle(m message, err error) error {
+	var retryErr *core.RetryError
 
-	if ln := len(s.listeners[topic]); ln > 0 {
-		id = s.listeners[topic][ln-1].id + 1
+	if err == nil {
+		s.log.done(m.seq)
+		return nil
 	}
 
-	s.listeners[topic] = append(s.listeners[topic], listener{l, id})
+	if !errors.As(err, &retryErr) {
+		return err
+	}
 
-	return id
+	return &core.RetryError{Err: retryErr.Err, Retry: func(ctx context.Context, e core.Event) error {
+		return s.settle(m, retryErr.Retry(ctx, e))
+	}}
 }
 
-func (s *Server) unregisterListener(topic string, id int64) {
-	for i, lis := range s.listeners[topic] {
-		if lis.id == id {
-			s.listeners[topic] = append(s.listeners[topic][:i], s.listeners[topic][i+1:]...)
+// notify sends the event to the subscribers, only the failed ones are retried
+func notify(ctx context.Context, m message, subs []*subscriber) error {
+	var failed []*subscriber
+	var err error
+
+	for _, sub := range subs {
+		if errS := sub.push(ctx, m); errS != nil {
+			failed = append(failed, sub)
+			err = errS
 		}
 	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &core.RetryError{Err: err, Retry: func(ctx context.Context, e core.Event) error {
+		return notify(ctx, m, failed)
+	}}
 }
 
 func (s *Server) Schedule(ctx context.Context, req *api.ScheduleRequest) (*api.ScheduleResponse, error) {
 	e := apiEventToCoreEvent(*req.Event)
 
+	if err := ValidateTopic(e.Topic); err != nil {
+		return &api.ScheduleResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.policy.Authorize(ctx, auth.OpSchedule, e.Topic); err != nil {
+		return &api.ScheduleResponse{}, err
+	}
+
 	id, err := s.scheduler.Schedule(e)
 
 	if err != nil {
@@ -110,40 +178,246 @@ func (s *Server) Schedule(ctx context.Context, req *api.ScheduleRequest) (*api.S
 	}, nil
 }
 
+// DefaultPreviewCount is the number of occurrences listed by PreviewSchedule if the request sets none
+const DefaultPreviewCount = 10
+
+// PreviewSchedule tells whether the event would be accepted by Schedule and lists its next occurrences,
+// an invalid event is not an error of the call
+func (s *Server) PreviewSchedule(ctx context.Context, req *api.PreviewScheduleRequest) (*api.PreviewScheduleResponse, error) {
+	if req.Event == nil {
+		return &api.PreviewScheduleResponse{}, status.Error(codes.InvalidArgument, "no event")
+	}
+
+	e := apiEventToCoreEvent(*req.Event)
+
+	if err := s.policy.Authorize(ctx, auth.OpSchedule, e.Topic); err != nil {
+		return &api.PreviewScheduleResponse{}, err
+	}
+
+	if err := ValidateTopic(e.Topic); err != nil {
+		return &api.PreviewScheduleResponse{Error: err.Error()}, nil
+	}
+
+	n := int(req.Count)
+
+	if n == 0 {
+		n = DefaultPreviewCount
+	}
+
+	if n > core.MaxPreviewOccurrences {
+		n = core.MaxPreviewOccurrences
+	}
+
+	p, err := s.scheduler.Preview(e, n)
+
+	if err != nil {
+		return &api.PreviewScheduleResponse{Error: err.Error()}, nil
+	}
+
+	resp := &api.PreviewScheduleResponse{
+		Valid:       true,
+		Description: p.Description,
+		Occurrences: make([]int64, len(p.Occurrences)),
+	}
+
+	for i, o := range p.Occurrences {
+		resp.Occurrences[i] = o.Unix()
+	}
+
+	return resp, nil
+}
+
 func (s *Server) Unschedule(ctx context.Context, req *api.UnscheduleRequest) (*api.UnscheduleResponse, error) {
-	return &api.UnscheduleResponse{}, s.scheduler.Unschedule(core.ID(req.Id.Id))
+	id := core.ID(req.Id.Id)
+
+	if s.policy != nil {
+		e, err := s.scheduler.Get(id)
+
+		// There is nothing to unschedule
+		if err == core.ErrNotFound {
+			return &api.UnscheduleResponse{}, nil
+		}
+
+		if err != nil {
+			return &api.UnscheduleResponse{}, err
+		}
+
+		if err := s.policy.Authorize(ctx, auth.OpUnschedule, e.Topic); err != nil {
+			return &api.UnscheduleResponse{}, err
+		}
+	}
+
+	return &api.UnscheduleResponse{}, s.scheduler.Unschedule(id)
+}
+
+func (s *Server) ListEvents(ctx context.Context, req *api.ListEventsRequest) (*api.ListEventsResponse, error) {
+	if req.Topic != "" {
+		if err := s.policy.Authorize(ctx, auth.OpList, req.Topic); err != nil {
+			return &api.ListEventsResponse{}, err
+		}
+	}
+
+	evs, err := s.scheduler.List()
+
+	if err != nil {
+		return &api.ListEventsResponse{}, err
+	}
+
+	principal, _ := auth.FromContext(ctx)
+
+	resp := &api.ListEventsResponse{Events: make([]*api.Event, 0, len(evs))}
+
+	for _, e := range evs {
+		if req.Topic != "" && e.Topic != req.Topic {
+			continue
+		}
+
+		// Without topic, only the events the principal is allowed to list are returned
+		if s.policy != nil && !s.policy.Allowed(principal, auth.OpList, e.Topic) {
+			continue
+		}
+
+		ev := coreEventToApiEvent(e)
+		resp.Events = append(resp.Events, &ev)
+	}
+
+	return resp, nil
 }
 
 func (s *Server) StreamEvents(req *api.StreamEventsRequest, stream api.Scheduler_StreamEventsServer) error {
-	var id int64
+	ctx := stream.Context()
+
+	if err := ValidateTopicPattern(req.Topic); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.policy.Authorize(ctx, auth.OpStream, req.Topic); err != nil {
+		return err
+	}
 
-	d := func(e core.Event) error {
-		resp := coreEventToApiEvent(e)
+	f, err := filter.Parse(req.Filter)
 
-		err := stream.Send(&api.StreamEventsResponse{Event: &resp})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 
-		if err == io.EOF {
-			s.unregisterListener(req.Topic, id)
+	if req.ResumeToken != "" {
+		if _, _, err := parseResumeToken(req.ResumeToken); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
 		}
+	}
+
+	principal, _ := auth.FromContext(ctx)
 
+	sub := newSubscriber(s.subOpts)
+	sub.filter = f
+	sub.filterExpr = req.Filter
+	sub.topic = req.Topic
+	sub.group = req.Group
+	sub.principal = principal
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		sub.peer = p.Addr.String()
+	}
+
+	// A wildcard subscription only receives the events of the topics the principal is allowed to read, the members
+	// of a group are picked among the ones allowed to read the event
+	if s.policy != nil {
+		sub.allowed = func(e core.Event) bool {
+			return s.policy.Allowed(principal, auth.OpStream, e.Topic)
+		}
+	}
+
+	id := s.subs.add(sub)
+	defer s.subs.remove(id)
+
+	// The log is read once the subscriber is registered, so that every later dispatch is delivered live
+	var replay []message
+	var gap bool
+	boundary := s.log.head()
+
+	if req.ResumeToken != "" {
+		replay, boundary, gap, err = s.log.since(req.ResumeToken)
+
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	md := metadata.MD{}
+
+	if gap {
+		md.Set(ResumeGapHeader, "true")
+	}
+
+	// The headers tell the subscriber that it will not miss the events dispatched from now on
+	if err := stream.SendHeader(md); err != nil {
 		return err
 	}
 
-	id = s.registerListener(req.Topic, d)
+	// The groups are ignored by the replay, the members of a group may receive the same missed event
+	for _, m := range replay {
+		if !matchTopic(sub.topic, m.Topic) || !sub.selects(m.Event) {
+			continue
+		}
+
+		if err := stream.Send(s.streamResponse(m, m.seq)); err != nil {
+			return err
+		}
+
+		atomic.AddUint64(&sub.sent, 1)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.close(ctx.Err())
+		case <-sub.done:
+		}
+	}()
+
+	token := boundary
+
+	err = sub.run(func(m message) error {
+		// The replay already sent the events dispatched before it
+		if req.ResumeToken != "" && m.seq <= boundary {
+			return nil
+		}
+
+		if m.token > token {
+			token = m.token
+		}
 
-	<-stream.Context().Done()
-	err := stream.Context().Err()
+		return stream.Send(s.streamResponse(m, token))
+	})
+
+	st := sub.stats()
+	log.Printf("subscriber %d of topic %q (group %q, peer %s) disconnected: %d sent, %d dropped, %d overflows, %d buffered: %v\n", id, req.Topic, req.Group, sub.peer, st.Sent, st.Dropped, st.Overflows, st.Buffered, err)
 
-	s.unregisterListener(req.Topic, id)
 	return err
 }
 
+func (s *Server) streamResponse(m message, token uint64) *api.StreamEventsResponse {
+	e := coreEventToApiEvent(m.Event)
+
+	return &api.StreamEventsResponse{
+		Event:       &e,
+		Sequence:    m.seq,
+		ResumeToken: s.log.token(token),
+	}
+}
+
 func coreEventToApiEvent(e core.Event) api.Event {
 	var mode api.Event_Mode
 
-	if e.Mode == core.TimestampMode {
+	switch e.Mode {
+	case core.TimestampMode:
 		mode = api.Event_TIMESTAMP
-	} else {
+	case core.IntervalMode:
+		mode = api.Event_INTERVAL
+	case core.RRuleMode:
+		mode = api.Event_RRULE
+	default:
 		mode = api.Event_CRON
 	}
 
@@ -154,15 +428,35 @@ func coreEventToApiEvent(e core.Event) api.Event {
 		Mode:            mode,
 		Topic:           e.Topic,
 		Payload:         e.Payload,
+		Labels:          e.Labels,
+		TimeZone:        e.TimeZone,
+		StartAt:         unixTimestamp(e.StartAt),
+		EndAt:           unixTimestamp(e.EndAt),
+		MaxOccurrences:  uint32(e.MaxOccurrences),
+		FireCount:       uint32(e.FireCount),
+		MisfirePolicy:   api.Event_MisfirePolicy(e.Misfire),
+		LastFiredAt:     unixTimestamp(e.LastFiredAt),
+		IntervalSeconds: int64(e.Interval / time.Second),
+		Anchor:          unixTimestamp(e.Anchor),
+		Rrule:           e.RRule,
+		Calendar:        e.Calendar,
+		CalendarPolicy:  api.Event_CalendarPolicy(e.CalendarPolicy),
+		JitterMs:        int64(e.Jitter / time.Millisecond),
+		JitterMode:      api.Event_JitterMode(e.JitterMode),
 	}
 }
 
 func apiEventToCoreEvent(e api.Event) core.Event {
 	var mode core.EventMode
 
-	if e.Mode == api.Event_TIMESTAMP {
+	switch e.Mode {
+	case api.Event_TIMESTAMP:
 		mode = core.TimestampMode
-	} else {
+	case api.Event_INTERVAL:
+		mode = core.IntervalMode
+	case api.Event_RRULE:
+		mode = core.RRuleMode
+	default:
 		mode = core.CronMode
 	}
 
@@ -173,5 +467,37 @@ func apiEventToCoreEvent(e api.Event) core.Event {
 		Mode:            mode,
 		Topic:           e.Topic,
 		Payload:         e.Payload,
+		Labels:          e.Labels,
+		TimeZone:        e.TimeZone,
+		StartAt:         fromUnixTimestamp(e.StartAt),
+		EndAt:           fromUnixTimestamp(e.EndAt),
+		MaxOccurrences:  int(e.MaxOccurrences),
+		FireCount:       int(e.FireCount),
+		Misfire:         core.MisfirePolicy(e.MisfirePolicy),
+		LastFiredAt:     fromUnixTimestamp(e.LastFiredAt),
+		Interval:        time.Duration(e.IntervalSeconds) * time.Second,
+		Anchor:          fromUnixTimestamp(e.Anchor),
+		RRule:           e.Rrule,
+		Calendar:        e.Calendar,
+		CalendarPolicy:  core.CalendarPolicy(e.CalendarPolicy),
+		Jitter:          time.Duration(e.JitterMs) * time.Millisecond,
+		JitterMode:      core.JitterMode(e.JitterMode),
 	}
 }
+
+// unixTimestamp converts the optional times, the zero time is 0
+func unixTimestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+func fromUnixTimestamp(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(ts, 0)
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/auth"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
+	"github.com/yanishoss/schedulo/internal/core"
+	"github.com/yanishoss/schedulo/internal/filter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrBufferFull = errors.New("the buffer of the subscriber is full")
+
+	ErrSlowSubscriber = status.Error(codes.ResourceExhausted, "the subscriber could not keep up with the events")
+
+	ErrDisconnected = status.Error(codes.Aborted, "the subscriber was disconnected by an administrator")
+)
+
+// SubscriberStats are the counters of a subscriber
+type SubscriberStats struct {
+	Sent      uint64
+	Dropped   uint64
+	Overflows uint64
+	Buffered  int
+}
+
+// subscriber buffers the events of a stream, they are sent by the goroutine of the stream so that
+// a slow consumer does not hold the dispatch workers
+type subscriber struct {
+	// The counters are first to be 64-bit aligned
+	sent      uint64
+	dropped   uint64
+	overflows uint64
+
+	id          int64
+	topic       string
+	group       string
+	peer        string
+	principal   auth.Principal
+	connectedAt time.Time
+
+	// filter selects the events on their labels, every event is selected if it is nil
+	filter     filter.Filter
+	filterExpr string
+
+	// allowed tells whether the principal of the subscriber may read the event, every event is allowed if it is nil
+	allowed func(e core.Event) bool
+
+	events chan message
+	opts   subscription.Options
+
+	// mu serializes the pushes dropping the oldest events
+	mu *sync.Mutex
+
+	done      chan struct{}
+	closeOnce *sync.Once
+	err       error
+}
+
+func newSubscriber(opts subscription.Options) *subscriber {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = subscription.DefaultBufferSize
+	}
+
+	if opts.Overflow == "" {
+		opts.Overflow = subscription.Block
+	}
+
+	return &subscriber{
+		events:    make(chan message, opts.BufferSize),
+		opts:      opts,
+		mu:        &sync.Mutex{},
+		done:      make(chan struct{}),
+		closeOnce: &sync.Once{},
+
+		connectedAt: time.Now(),
+	}
+}
+
+// push buffers the event, a closed subscriber silently ignores it
+func (s *subscriber) push(ctx context.Context, m message) error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	select {
+	case s.events <- m:
+		return nil
+	default:
+	}
+
+	atomic.AddUint64(&s.overflows, 1)
+
+	switch s.opts.Overflow {
+	case subscription.DropOldest:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for {
+			select {
+			case s.events <- m:
+				return nil
+			default:
+			}
+
+			select {
+			case <-s.events:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	case subscription.Disconnect:
+		atomic.AddUint64(&s.dropped, 1)
+		s.close(ErrSlowSubscriber)
+
+		return nil
+	case subscription.RetryLater:
+		return ErrBufferFull
+	default:
+		select {
+		case s.events <- m:
+			return nil
+		case <-s.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// run sends the buffered events until the subscriber is closed or send fails
+func (s *subscriber) run(send func(m message) error) error {
+	for {
+		select {
+		case m := <-s.events:
+			if err := send(m); err != nil {
+				s.close(err)
+				return err
+			}
+
+			atomic.AddUint64(&s.sent, 1)
+		case <-s.done:
+			return s.err
+		}
+	}
+}
+
+// close ends the subscriber, err is returned by run
+func (s *subscriber) close(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// selects tells whether the subscriber is allowed to read the event and whether its filter selects it
+func (s *subscriber) selects(e core.Event) bool {
+	if s.allowed != nil && !s.allowed(e) {
+		return false
+	}
+
+	return s.filter == nil || s.filter.Match(e.Labels)
+}
+
+func (s *subscriber) stats() SubscriberStats {
+	return SubscriberStats{
+		Sent:      atomic.LoadUint64(&s.sent),
+		Dropped:   atomic.LoadUint64(&s.dropped),
+		Overflows: atomic.LoadUint64(&s.overflows),
+		Buffered:  len(s.events),
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/yanishoss/schedulo/cmd/schedulo_server/subscription"
+	"github.com/yanishoss/schedulo/internal/core"
+	"testing"
+	"time"
+)
+
+func TestSubscriber_Overflow(t *testing.T) {
+	ctx := context.Background()
+
+	drop := newSubscriber(subscription.Options{BufferSize: 2, Overflow: subscription.DropOldest})
+
+	for _, id := range []core.ID{"1", "2", "3"} {
+		if err := drop.push(ctx, message{Event: core.Event{ID: id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if st := drop.stats(); st.Dropped != 1 || st.Overflows != 1 || st.Buffered != 2 {
+		t.Fatalf("The oldest event must be dropped: %+v\n", st)
+	}
+
+	if e := <-drop.events; e.ID != "2" {
+		t.Fatalf("Wrong oldest event: expected:%s, got:%s\n", "2", e.ID)
+	}
+
+	retry := newSubscriber(subscription.Options{BufferSize: 1, Overflow: subscription.RetryLater})
+	retry.push(ctx, message{Event: core.Event{}})
+
+	if err := retry.push(ctx, message{Event: core.Event{}}); !errors.Is(err, ErrBufferFull) {
+		t.Fatalf("A full subscriber must fail the dispatch: got %v\n", err)
+	}
+
+	disconnect := newSubscriber(subscription.Options{BufferSize: 1, Overflow: subscription.Disconnect})
+	disconnect.push(ctx, message{Event: core.Event{}})
+	disconnect.push(ctx, message{Event: core.Event{}})
+
+	if err := disconnect.run(func(m message) error { return nil }); err != ErrSlowSubscriber {
+		t.Fatalf("A full subscriber must be disconnected: got %v\n", err)
+	}
+
+	if err := disconnect.push(ctx, message{Event: core.Event{}}); err != nil {
+		t.Fatalf("A disconnected subscriber must ignore the events: got %v\n", err)
+	}
+}
+
+func TestSubscriber_Block(t *testing.T) {
+	sub := newSubscriber(subscription.Options{BufferSize: 1})
+	sub.push(context.Background(), message{Event: core.Event{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := sub.push(ctx, message{Event: core.Event{}}); err != context.DeadlineExceeded {
+		t.Fatalf("A full subscriber must block the dispatch: got %v\n", err)
+	}
+
+	sent := make(chan core.Event, 2)
+
+	go sub.run(func(m message) error {
+		sent <- m.Event
+		return nil
+	})
+
+	if err := sub.push(context.Background(), message{Event: core.Event{ID: "2"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	<-sent
+
+	if e := <-sent; e.ID != "2" {
+		t.Fatalf("The blocked event must be sent once there is room: got %s\n", e.ID)
+	}
+
+	sub.close(nil)
+}
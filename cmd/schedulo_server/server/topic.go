@@ -0,0 +1,168 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// TopicSeparator separates the levels of the hierarchical topics, e.g. billing.invoice.due
+	TopicSeparator = "."
+
+	// AnyLevel matches exactly one level of a topic, e.g. billing.*.due
+	AnyLevel = "*"
+
+	// AnyLevels matches one or more trailing levels, e.g. billing.> matches billing.invoice.due.
+	// Subscribing to > alone receives every event, including the events without topic
+	AnyLevels = ">"
+)
+
+// ValidateTopicPattern checks a subscription topic, the wildcards must be whole levels
+// and AnyLevels must be the last one
+func ValidateTopicPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("the topic must not be empty, subscribe to %q to receive every event", AnyLevels)
+	}
+
+	levels := strings.Split(pattern, TopicSeparator)
+
+	for i, l := range levels {
+		switch {
+		case l == "":
+			return fmt.Errorf("the topic %q has an empty level", pattern)
+		case l == AnyLevels && i != len(levels)-1:
+			return fmt.Errorf("%q must be the last level of the topic %q", AnyLevels, pattern)
+		case l != AnyLevel && l != AnyLevels && strings.ContainsAny(l, AnyLevel+AnyLevels):
+			return fmt.Errorf("the wildcards of the topic %q must be whole levels", pattern)
+		}
+	}
+
+	return nil
+}
+
+// ValidateTopic checks the topic of an event, it must not contain wildcards
+func ValidateTopic(topic string) error {
+	for _, l := range strings.Split(topic, TopicSeparator) {
+		if l == AnyLevel || l == AnyLevels {
+			return fmt.Errorf("the topic %q of an event must not contain wildcards", topic)
+		}
+	}
+
+	return nil
+}
+
+// topicTrie indexes the subscribers by the levels of their topic pattern, so that the subscribers
+// of a topic are found without going through every subscription
+type topicTrie struct {
+	children map[string]*topicTrie
+	subs     []*subscriber
+}
+
+func newTopicTrie() *topicTrie {
+	return &topicTrie{children: make(map[string]*topicTrie)}
+}
+
+func (t *topicTrie) add(s *subscriber) {
+	n := t
+
+	for _, l := range strings.Split(s.topic, TopicSeparator) {
+		child, ok := n.children[l]
+
+		if !ok {
+			child = newTopicTrie()
+			n.children[l] = child
+		}
+
+		n = child
+	}
+
+	n.subs = append(n.subs, s)
+}
+
+// remove deletes the subscriber and prunes the branches left empty
+func (t *topicTrie) remove(s *subscriber) {
+	t.removeLevels(s, strings.Split(s.topic, TopicSeparator))
+}
+
+func (t *topicTrie) removeLevels(s *subscriber, levels []string) {
+	if len(levels) == 0 {
+		for i, sub := range t.subs {
+			if sub == s {
+				t.subs = append(t.subs[:i:i], t.subs[i+1:]...)
+				break
+			}
+		}
+
+		return
+	}
+
+	child, ok := t.children[levels[0]]
+
+	if !ok {
+		return
+	}
+
+	child.removeLevels(s, levels[1:])
+
+	if len(child.subs) == 0 && len(child.children) == 0 {
+		delete(t.children, levels[0])
+	}
+}
+
+// match returns the subscribers whose pattern matches the topic, the events without topic
+// only match AnyLevels
+func (t *topicTrie) match(topic string) []*subscriber {
+	if topic == "" {
+		if all, ok := t.children[AnyLevels]; ok {
+			return append([]*subscriber(nil), all.subs...)
+		}
+
+		return nil
+	}
+
+	var subs []*subscriber
+
+	t.matchLevels(strings.Split(topic, TopicSeparator), &subs)
+
+	return subs
+}
+
+func (t *topicTrie) matchLevels(levels []string, subs *[]*subscriber) {
+	if len(levels) == 0 {
+		*subs = append(*subs, t.subs...)
+		return
+	}
+
+	if all, ok := t.children[AnyLevels]; ok {
+		*subs = append(*subs, all.subs...)
+	}
+
+	if child, ok := t.children[levels[0]]; ok && levels[0] != AnyLevel {
+		child.matchLevels(levels[1:], subs)
+	}
+
+	if child, ok := t.children[AnyLevel]; ok {
+		child.matchLevels(levels[1:], subs)
+	}
+}
+
+// matchTopic tells whether the pattern matches the topic, like the trie does
+func matchTopic(pattern string, topic string) bool {
+	if topic == "" {
+		return pattern == AnyLevels
+	}
+
+	levels := strings.Split(topic, TopicSeparator)
+
+	for i, l := range strings.Split(pattern, TopicSeparator) {
+		if l == AnyLevels {
+			return i < len(levels)
+		}
+
+		if i >= len(levels) || (l != AnyLevel && l != levels[i]) {
+			return false
+		}
+	}
+
+	return len(strings.Split(pattern, TopicSeparator)) == len(levels)
+}
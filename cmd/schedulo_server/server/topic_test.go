@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"github.com/yanishoss/schedulo/api"
+	"testing"
+)
+
+func TestTopicTrie_Match(t *testing.T) {
+	trie := newTopicTrie()
+
+	patterns := []string{"billing.invoice.due", "billing.*.due", "billing.>", "*.invoice.*", ">", "shipping"}
+	subs := make(map[*subscriber]string)
+
+	for _, p := range patterns {
+		if err := ValidateTopicPattern(p); err != nil {
+			t.Fatal(err)
+		}
+
+		s := &subscriber{topic: p}
+		subs[s] = p
+		trie.add(s)
+	}
+
+	expected := map[string]int{
+		"billing.invoice.due":  5,
+		"billing.invoice":      2,
+		"billing":              1,
+		"shipping":             2,
+		"shipping.invoice.new": 2,
+		"":                     1,
+	}
+
+	for topic, n := range expected {
+		if matched := trie.match(topic); len(matched) != n {
+			var got []string
+
+			for _, s := range matched {
+				got = append(got, subs[s])
+			}
+
+			t.Fatalf("Wrong subscriptions matching %q: expected:%d, got:%v\n", topic, n, got)
+		}
+
+		matched := 0
+
+		for _, p := range patterns {
+			if matchTopic(p, topic) {
+				matched++
+			}
+		}
+
+		if matched != n {
+			t.Fatalf("matchTopic must agree with the trie on %q: expected:%d, got:%d\n", topic, n, matched)
+		}
+	}
+
+	for s := range subs {
+		trie.remove(s)
+	}
+
+	if len(trie.children) != 0 {
+		t.Fatalf("The empty branches must be pruned: got %d\n", len(trie.children))
+	}
+}
+
+func TestValidateTopicPattern(t *testing.T) {
+	for pattern, valid := range map[string]bool{
+		"billing.*.due": true,
+		"billing.>":     true,
+		">":             true,
+		"":              false,
+		"billing..due":  false,
+		"billing.>.due": false,
+		"billing.inv*":  false,
+	} {
+		if err := ValidateTopicPattern(pattern); (err == nil) != valid {
+			t.Fatalf("Wrong validation of %q: expected:%t, got %v\n", pattern, valid, err)
+		}
+	}
+
+	if err := ValidateTopic("billing.*"); err == nil {
+		t.Fatalf("The topic of an event must not contain wildcards\n")
+	}
+
+	s := &Server{}
+	resp, err := s.PreviewSchedule(context.Background(), &api.PreviewScheduleRequest{Event: &api.Event{Topic: "billing.*"}})
+
+	if err != nil || resp.Valid || resp.Error == "" {
+		t.Fatalf("The preview of an event must validate its topic: %+v, %v\n", resp, err)
+	}
+}
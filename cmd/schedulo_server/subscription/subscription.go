@@ -0,0 +1,35 @@
+package subscription
+
+const (
+	DefaultBufferSize = 100
+
+	DefaultResumeLogSize = 1000
+)
+
+// OverflowPolicy tells what happens to an event dispatched to a subscriber whose buffer is full
+type OverflowPolicy string
+
+const (
+	// Block waits for the subscriber to make room, holding the dispatch worker
+	Block OverflowPolicy = "block"
+
+	// DropOldest drops the oldest buffered event to make room
+	DropOldest OverflowPolicy = "drop-oldest"
+
+	// Disconnect ends the stream of the subscriber
+	Disconnect OverflowPolicy = "disconnect"
+
+	// RetryLater fails the dispatch to the subscriber so that it is retried by the dispatch manager
+	RetryLater OverflowPolicy = "retry"
+)
+
+var OverflowPolicies = []OverflowPolicy{Block, DropOldest, Disconnect, RetryLater}
+
+// Options are the options of each stream subscriber
+type Options struct {
+	// BufferSize is DefaultBufferSize by default
+	BufferSize int
+
+	// Overflow is Block by default
+	Overflow OverflowPolicy
+}
@@ -47,14 +47,13 @@ func (cache *redisCacheManager) Add(ctx context.Context, e Event) error {
 	conn := cache.c.Conn()
 	defer conn.Close()
 
-	cmd := conn.HMSet(
-		"schedulo_ns:"+string(e.ID),
-		"cron_expression", e.CronExpression,
-		"should_execute_at", e.ShouldExecuteAt.Format(time.RFC3339Nano),
-		"mode", int(e.Mode),
-		"topic", e.Topic,
-		"payload", e.Payload,
-	)
+	fields, err := eventFields(e)
+
+	if err != nil {
+		return err
+	}
+
+	cmd := conn.HMSet("schedulo_ns:"+string(e.ID), fields...)
 
 	if err := cmd.Err(); err != nil {
 		return err
@@ -69,6 +68,55 @@ func (cache *redisCacheManager) Add(ctx context.Context, e Event) error {
 	return nil
 }
 
+// eventFields returns the fields of the hash of the event
+func eventFields(e Event) ([]interface{}, error) {
+	labels, err := encodeLabels(e.Labels)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		"cron_expression", e.CronExpression,
+		"should_execute_at", e.ShouldExecuteAt.Format(time.RFC3339Nano),
+		"mode", int(e.Mode),
+		"topic", e.Topic,
+		"payload", e.Payload,
+		"labels", labels,
+		"time_zone", e.TimeZone,
+		"start_at", formatTime(e.StartAt),
+		"end_at", formatTime(e.EndAt),
+		"max_occurrences", e.MaxOccurrences,
+		"fire_count", e.FireCount,
+		"misfire_policy", int(e.Misfire),
+		"last_fired_at", formatTime(e.LastFiredAt),
+		"interval", int64(e.Interval),
+		"anchor", formatTime(e.Anchor),
+		"rrule", e.RRule,
+		"calendar", e.Calendar,
+		"calendar_policy", int(e.CalendarPolicy),
+		"jitter", int64(e.Jitter),
+		"jitter_mode", int(e.JitterMode),
+	}, nil
+}
+
+// formatTime stores the zero time as an empty string
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, s)
+}
+
 func (cache *redisCacheManager) AddBulk(ctx context.Context, evs []Event) error {
 	conn := cache.c.Conn()
 	defer conn.Close()
@@ -77,14 +125,13 @@ func (cache *redisCacheManager) AddBulk(ctx context.Context, evs []Event) error
 	defer pip.Close()
 
 	for _, e := range evs {
-		cmd := pip.HMSet(
-			"schedulo_ns:"+string(e.ID),
-			"cron_expression", e.CronExpression,
-			"should_execute_at", e.ShouldExecuteAt.Format(time.RFC3339Nano),
-			"mode", int(e.Mode),
-			"topic", e.Topic,
-			"payload", e.Payload,
-		)
+		fields, err := eventFields(e)
+
+		if err != nil {
+			return err
+		}
+
+		cmd := pip.HMSet("schedulo_ns:"+string(e.ID), fields...)
 
 		if err := cmd.Err(); err != nil {
 			return err
@@ -151,7 +198,106 @@ func (cache *redisCacheManager) Get(ctx context.Context, id ID) (Event, error) {
 
 	e.Payload = []byte(obj["payload"])
 
-	return e, nil
+	e.TimeZone = obj["time_zone"]
+
+	e.StartAt, err = parseTime(obj["start_at"])
+
+	if err != nil {
+		return e, err
+	}
+
+	e.EndAt, err = parseTime(obj["end_at"])
+
+	if err != nil {
+		return e, err
+	}
+
+	// The hashes written before the bounds were added have no counters
+	if v, ok := obj["max_occurrences"]; ok {
+		e.MaxOccurrences, err = strconv.Atoi(v)
+
+		if err != nil {
+			return e, err
+		}
+	}
+
+	if v, ok := obj["fire_count"]; ok {
+		e.FireCount, err = strconv.Atoi(v)
+
+		if err != nil {
+			return e, err
+		}
+	}
+
+	if v, ok := obj["misfire_policy"]; ok {
+		misfire, err := strconv.Atoi(v)
+
+		if err != nil {
+			return e, err
+		}
+
+		e.Misfire = MisfirePolicy(misfire)
+	}
+
+	e.LastFiredAt, err = parseTime(obj["last_fired_at"])
+
+	if err != nil {
+		return e, err
+	}
+
+	if v, ok := obj["interval"]; ok {
+		interval, err := strconv.ParseInt(v, 10, 64)
+
+		if err != nil {
+			return e, err
+		}
+
+		e.Interval = time.Duration(interval)
+	}
+
+	e.Anchor, err = parseTime(obj["anchor"])
+
+	if err != nil {
+		return e, err
+	}
+
+	e.RRule = obj["rrule"]
+
+	e.Calendar = obj["calendar"]
+
+	if v, ok := obj["calendar_policy"]; ok {
+		policy, err := strconv.Atoi(v)
+
+		if err != nil {
+			return e, err
+		}
+
+		e.CalendarPolicy = CalendarPolicy(policy)
+	}
+
+	if v, ok := obj["jitter"]; ok {
+		jitter, err := strconv.ParseInt(v, 10, 64)
+
+		if err != nil {
+			return e, err
+		}
+
+		e.Jitter = time.Duration(jitter)
+	}
+
+	if v, ok := obj["jitter_mode"]; ok {
+		mode, err := strconv.Atoi(v)
+
+		if err != nil {
+			return e, err
+		}
+
+		e.JitterMode = JitterMode(mode)
+	}
+
+	e.Labels, err = decodeLabels(obj["labels"])
+
+	return e, err
 }
 
 func (cache *redisCacheManager) Delete(ctx context.Context, id ID) error {
@@ -167,6 +313,27 @@ func (cache *redisCacheManager) Delete(ctx context.Context, id ID) error {
 	return nil
 }
 
+// Complete cannot run handoffs as the cache is not transactional
+func (cache *redisCacheManager) Complete(ctx context.Context, e Event, next time.Time, handoffs []Handoff) error {
+	if len(handoffs) > 0 {
+		return ErrNotImplemented
+	}
+
+	if next.IsZero() {
+		return cache.Delete(ctx, e.ID)
+	}
+
+	e.ShouldExecuteAt = next
+	e.FireCount++
+
+	return cache.Add(ctx, e)
+}
+
+// Move drops the cached event, it is read again from the database
+func (cache *redisCacheManager) Move(ctx context.Context, id ID, next time.Time) error {
+	return cache.Delete(ctx, id)
+}
+
 func (cache *redisCacheManager) GetAll(ctx context.Context) (out []Event, err error) {
 	return out, ErrNotImplemented
 }
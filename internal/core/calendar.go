@@ -0,0 +1,469 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CalendarPolicy tells what happens to the occurrences of a recurring event falling outside the business
+// days and hours of its calendar
+type CalendarPolicy uint
+
+const (
+	// CalendarSkip drops the occurrences, the event resumes at its next business occurrence
+	CalendarSkip CalendarPolicy = iota
+
+	// CalendarShift moves the occurrences to the next business day, or to the opening time if they fall
+	// outside the working hours. The occurrences shifted onto the same time are dispatched once
+	CalendarShift
+)
+
+var (
+	ErrUnknownCalendar       = errors.New("the calendar is unknown")
+	ErrInvalidCalendarPolicy = errors.New("the calendar policy is unknown")
+	ErrCalendarNotRecurring  = errors.New("only the recurring events can follow a calendar")
+	ErrConfiguredCalendar    = errors.New("the calendar is defined by the configuration")
+)
+
+// maxCalendarSkips ends the events whose occurrences all fall outside their calendar
+const maxCalendarSkips = 10000
+
+// Calendar lists the business days and hours the recurring events referring to it by name are restricted to
+type Calendar struct {
+	Name string
+
+	// TimeZone is the IANA name of the zone of the dates and hours, the local zone of the server if empty
+	TimeZone string
+
+	// ExcludedDates are the YYYY-MM-DD dates which are not business days, e.g. the bank holidays
+	ExcludedDates []string
+
+	// WorkingDays are the business days of the week, Monday to Friday if empty
+	WorkingDays []time.Weekday
+
+	// WorkingHours are the HH:MM-HH:MM business hours of the working days, e.g. 09:00-17:30,
+	// the business days are open all day if empty
+	WorkingHours string
+}
+
+// calendar is a validated Calendar
+type calendar struct {
+	def      Calendar
+	loc      *time.Location
+	excluded map[string]bool
+	days     [7]bool
+	opens    time.Duration
+	closes   time.Duration
+}
+
+// ValidateCalendar checks the name, the time zone, the dates and the hours of the calendar
+func ValidateCalendar(c Calendar) error {
+	_, err := compileCalendar(c)
+
+	return err
+}
+
+func compileCalendar(c Calendar) (*calendar, error) {
+	if c.Name == "" {
+		return nil, errors.New("the calendar has no name")
+	}
+
+	loc, err := LoadTimeZone(c.TimeZone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &calendar{def: c, loc: loc, excluded: make(map[string]bool, len(c.ExcludedDates))}
+
+	for _, d := range c.ExcludedDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, fmt.Errorf("invalid excluded date %q of the calendar %q, expected YYYY-MM-DD", d, c.Name)
+		}
+
+		cal.excluded[d] = true
+	}
+
+	days := c.WorkingDays
+
+	if len(days) == 0 {
+		days = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+
+	for _, d := range days {
+		if d < time.Sunday || d > time.Saturday {
+			return nil, fmt.Errorf("invalid working day %d of the calendar %q", d, c.Name)
+		}
+
+		cal.days[d] = true
+	}
+
+	if c.WorkingHours != "" {
+		cal.opens, cal.closes, err = parseWorkingHours(c.WorkingHours)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid working hours %q of the calendar %q: %v", c.WorkingHours, c.Name, err)
+		}
+	}
+
+	return cal, nil
+}
+
+// parseWorkingHours parses HH:MM-HH:MM, the hours are returned as durations since midnight
+func parseWorkingHours(s string) (time.Duration, time.Duration, error) {
+	parts := strings.Split(s, "-")
+
+	if len(parts) != 2 {
+		return 0, 0, errors.New("expected HH:MM-HH:MM")
+	}
+
+	opens, err := parseClock(parts[0])
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	closes, err := parseClock(parts[1])
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if opens >= closes {
+		return 0, 0, errors.New("the opening time must precede the closing time")
+	}
+
+	return opens, closes, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses the English name of a day of the week, e.g. Monday or mon
+func ParseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) || strings.EqualFold(s, d.String()[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// businessDay tells whether the day of t is a working day which is not excluded
+func (c *calendar) businessDay(t time.Time) bool {
+	t = t.In(c.loc)
+
+	return c.days[t.Weekday()] && !c.excluded[t.Format("2006-01-02")]
+}
+
+// open tells whether t is a business day, within the working hours if any
+func (c *calendar) open(t time.Time) bool {
+	if !c.businessDay(t) {
+		return false
+	}
+
+	if c.closes == 0 {
+		return true
+	}
+
+	clock := sinceMidnight(t.In(c.loc))
+
+	return clock >= c.opens && clock < c.closes
+}
+
+// shift returns the first business time at or after t: the same time of the next business day,
+// or its opening time if the calendar has working hours
+func (c *calendar) shift(t time.Time) time.Time {
+	t = t.In(c.loc)
+
+	// A working day which is not excluded is at most a week after the excluded dates
+	for i := 0; i <= len(c.excluded)+7; i++ {
+		if c.businessDay(t) {
+			clock := sinceMidnight(t)
+
+			if c.closes == 0 || (clock >= c.opens && clock < c.closes) {
+				return t
+			}
+
+			if clock < c.opens {
+				return c.at(t, c.opens)
+			}
+		}
+
+		y, m, d := t.Date()
+
+		if c.closes == 0 {
+			t = time.Date(y, m, d+1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), c.loc)
+		} else {
+			t = c.at(time.Date(y, m, d+1, 0, 0, 0, 0, c.loc), c.opens)
+		}
+	}
+
+	return time.Time{}
+}
+
+// at returns the time of the day of t shown by the clocks at the given duration since midnight
+func (c *calendar) at(t time.Time, clock time.Duration) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, c.loc)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// calendarSchedule restricts the occurrences of a schedule to the business times of a calendar
+type calendarSchedule struct {
+	cron.Schedule
+	cal    *calendar
+	policy CalendarPolicy
+}
+
+func (s calendarSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxCalendarSkips; i++ {
+		o := s.Schedule.Next(t)
+
+		if o.IsZero() || s.cal.open(o) {
+			return o
+		}
+
+		if s.policy == CalendarShift {
+			return s.cal.shift(o)
+		}
+
+		t = o
+	}
+
+	return time.Time{}
+}
+
+// calendars holds the calendars by name, they are looked up whenever an occurrence is computed
+// so that they can be changed without restarting the scheduler
+type calendars struct {
+	mu     sync.RWMutex
+	byName map[string]*calendar
+
+	// configured are the calendars of the configuration, they are kept in memory only and take
+	// precedence over the persisted ones
+	configured map[string]*calendar
+}
+
+func (cs *calendars) get(name string) (*calendar, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if c, ok := cs.configured[name]; ok {
+		return c, true
+	}
+
+	c, ok := cs.byName[name]
+
+	return c, ok
+}
+
+func (cs *calendars) isConfigured(name string) bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	_, ok := cs.configured[name]
+
+	return ok
+}
+
+func (cs *calendars) set(c *calendar) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.byName == nil {
+		cs.byName = make(map[string]*calendar)
+	}
+
+	cs.byName[c.def.Name] = c
+}
+
+func (cs *calendars) delete(name string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	_, ok := cs.byName[name]
+	delete(cs.byName, name)
+
+	return ok
+}
+
+func (cs *calendars) replace(byName map[string]*calendar) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.byName = byName
+}
+
+func (cs *calendars) configure(configured map[string]*calendar) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.configured = configured
+}
+
+func (cs *calendars) list() []Calendar {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	out := make([]Calendar, 0, len(cs.byName)+len(cs.configured))
+
+	for _, c := range cs.configured {
+		out = append(out, c.def)
+	}
+
+	for name, c := range cs.byName {
+		if _, ok := cs.configured[name]; !ok {
+			out = append(out, c.def)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
+
+// recurrence returns the schedule of a recurring event restricted to its calendar, then delayed by its jitter.
+// The occurrences of the events whose calendar has been deleted are not restricted
+func (sch *scheduler) recurrence(e event) (cron.Schedule, error) {
+	s, err := parseRecurrence(sch.cr, e)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if cal, ok := sch.calendars.get(e.Calendar); ok {
+		s = calendarSchedule{Schedule: s, cal: cal, policy: e.CalendarPolicy}
+	}
+
+	if e.Jitter > 0 {
+		s = jitterSchedule{Schedule: s, id: e.ID, window: e.Jitter, mode: e.JitterMode}
+	}
+
+	return s, nil
+}
+
+// SetCalendar creates or replaces a calendar, the occurrences already computed are not moved.
+// The calendars of the configuration cannot be changed
+func (sch *scheduler) SetCalendar(c Calendar) error {
+	if sch.calendars.isConfigured(c.Name) {
+		return ErrConfiguredCalendar
+	}
+
+	cal, err := compileCalendar(c)
+
+	if err != nil {
+		return err
+	}
+
+	if store, ok := sch.pM.(CalendarStore); ok {
+		if err := store.SaveCalendar(sch.ctx, c); err != nil {
+			return err
+		}
+	}
+
+	sch.calendars.set(cal)
+
+	return nil
+}
+
+// DeleteCalendar deletes a calendar, the events referring to it are no longer restricted
+func (sch *scheduler) DeleteCalendar(name string) error {
+	if sch.calendars.isConfigured(name) {
+		return ErrConfiguredCalendar
+	}
+
+	if _, ok := sch.calendars.get(name); !ok {
+		return ErrUnknownCalendar
+	}
+
+	if store, ok := sch.pM.(CalendarStore); ok {
+		if err := store.DeleteCalendar(sch.ctx, name); err != nil {
+			return err
+		}
+	}
+
+	sch.calendars.delete(name)
+
+	return nil
+}
+
+// Calendars returns the calendars sorted by name
+func (sch *scheduler) Calendars() []Calendar {
+	return sch.calendars.list()
+}
+
+// ReloadCalendars replaces the calendars with the persisted ones, e.g. after they have been changed
+// by another instance sharing the database
+func (sch *scheduler) ReloadCalendars() error {
+	store, ok := sch.pM.(CalendarStore)
+
+	if !ok {
+		return nil
+	}
+
+	defs, err := store.GetCalendars(sch.ctx)
+
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*calendar, len(defs))
+
+	for _, def := range defs {
+		cal, err := compileCalendar(def)
+
+		if err != nil {
+			return err
+		}
+
+		byName[def.Name] = cal
+	}
+
+	sch.calendars.replace(byName)
+
+	return nil
+}
+
+// ConfigureCalendars replaces the calendars of the configuration, they are not persisted so that
+// the ones removed from the configuration disappear
+func (sch *scheduler) ConfigureCalendars(cals []Calendar) error {
+	configured := make(map[string]*calendar, len(cals))
+
+	for _, def := range cals {
+		cal, err := compileCalendar(def)
+
+		if err != nil {
+			return err
+		}
+
+		configured[def.Name] = cal
+	}
+
+	sch.calendars.configure(configured)
+
+	return nil
+}
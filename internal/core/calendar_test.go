@@ -0,0 +1,167 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarSchedule_Next(t *testing.T) {
+	cal, err := compileCalendar(Calendar{
+		Name:          "paris",
+		TimeZone:      "Europe/Paris",
+		ExcludedDates: []string{"2026-12-25"},
+		WorkingHours:  "09:00-18:00",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		spec     string
+		policy   CalendarPolicy
+		from     string
+		expected []string
+	}{
+		// December 24th 2026 is a Thursday, the 25th is excluded and the 26th is a Saturday
+		{
+			"skip",
+			"CRON_TZ=Europe/Paris 0 10 * * *", CalendarSkip, "2026-12-23T00:00:00Z",
+			[]string{"2026-12-23T09:00:00Z", "2026-12-24T09:00:00Z", "2026-12-28T09:00:00Z"},
+		},
+		{
+			"shift",
+			"CRON_TZ=Europe/Paris 0 10 * * *", CalendarShift, "2026-12-24T10:00:00Z",
+			[]string{"2026-12-28T08:00:00Z"},
+		},
+		{
+			"shift to the opening",
+			"CRON_TZ=Europe/Paris 0 7 * * *", CalendarShift, "2026-12-23T00:00:00Z",
+			[]string{"2026-12-23T08:00:00Z", "2026-12-24T08:00:00Z", "2026-12-28T08:00:00Z"},
+		},
+		{
+			"outside the working hours",
+			"CRON_TZ=Europe/Paris 0 7,12,20 * * *", CalendarSkip, "2026-12-23T00:00:00Z",
+			[]string{"2026-12-23T11:00:00Z", "2026-12-24T11:00:00Z", "2026-12-28T11:00:00Z"},
+		},
+	} {
+		s, err := testParser.Parse(tc.spec)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cs := calendarSchedule{Schedule: s, cal: cal, policy: tc.policy}
+		from := utc(tc.from)
+
+		for i, e := range tc.expected {
+			from = cs.Next(from)
+
+			if !from.Equal(utc(e)) {
+				t.Fatalf("%s: wrong occurrence %d: expected:%s, got:%s\n", tc.name, i, e, from.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+func TestValidateCalendar(t *testing.T) {
+	for _, c := range []Calendar{
+		{},
+		{Name: "a", TimeZone: "Mars/Olympus"},
+		{Name: "a", ExcludedDates: []string{"2026-02-30"}},
+		{Name: "a", WorkingDays: []time.Weekday{7}},
+		{Name: "a", WorkingHours: "09:00"},
+		{Name: "a", WorkingHours: "18:00-09:00"},
+	} {
+		if err := ValidateCalendar(c); err == nil {
+			t.Fatalf("The calendar %+v must be rejected\n", c)
+		}
+	}
+
+	if err := ValidateCalendar(Calendar{Name: "a", WorkingHours: "00:00-24:00"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduler_Calendar(t *testing.T) {
+	sch := newTestScheduler()
+
+	if err := sch.SetCalendar(Calendar{Name: "weekends", TimeZone: "UTC", WorkingDays: []time.Weekday{time.Saturday, time.Sunday}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 12 * * *", TimeZone: "UTC", Calendar: "weekends"}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sch.queue.Pop()
+
+	if d := e.ShouldExecuteAt.Weekday(); d != time.Saturday && d != time.Sunday {
+		t.Fatalf("The occurrences must fall on the weekends: got %s\n", e.ShouldExecuteAt)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 12 * * *", Calendar: "holidays"}); err != ErrUnknownCalendar {
+		t.Fatalf("An unknown calendar must be rejected: expected:%v, got:%v\n", ErrUnknownCalendar, err)
+	}
+
+	if _, err := sch.Schedule(Event{ShouldExecuteAt: time.Now().Add(time.Hour), Calendar: "weekends"}); err != ErrCalendarNotRecurring {
+		t.Fatalf("A one-shot event must not follow a calendar: expected:%v, got:%v\n", ErrCalendarNotRecurring, err)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 12 * * *", Calendar: "weekends", CalendarPolicy: 5}); err != ErrInvalidCalendarPolicy {
+		t.Fatalf("An unknown policy must be rejected: expected:%v, got:%v\n", ErrInvalidCalendarPolicy, err)
+	}
+
+	if err := sch.DeleteCalendar("weekends"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sch.DeleteCalendar("weekends"); err != ErrUnknownCalendar {
+		t.Fatalf("Deleting an unknown calendar must fail: expected:%v, got:%v\n", ErrUnknownCalendar, err)
+	}
+
+	// The events referring to a deleted calendar are no longer restricted
+	if next, err := sch.next(newEvent(*e)); err != nil || !next.Equal(e.ShouldExecuteAt.Add(24*time.Hour)) {
+		t.Fatalf("Wrong occurrence after the deletion: got %s (%v)\n", next, err)
+	}
+}
+
+func TestScheduler_ConfigureCalendars(t *testing.T) {
+	sch := &scheduler{}
+
+	if err := sch.SetCalendar(Calendar{Name: "holidays", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+
+	weekends := Calendar{Name: "weekends", TimeZone: "UTC", WorkingDays: []time.Weekday{time.Saturday, time.Sunday}}
+
+	if err := sch.ConfigureCalendars([]Calendar{weekends}); err != nil {
+		t.Fatal(err)
+	}
+
+	if cals := sch.Calendars(); len(cals) != 2 || cals[0].Name != "holidays" || cals[1].Name != "weekends" {
+		t.Fatalf("The configured calendars must be listed along with the persisted ones: got %v\n", cals)
+	}
+
+	if err := sch.SetCalendar(weekends); err != ErrConfiguredCalendar {
+		t.Fatalf("A configured calendar must not be changed: expected:%v, got:%v\n", ErrConfiguredCalendar, err)
+	}
+
+	if err := sch.DeleteCalendar("weekends"); err != ErrConfiguredCalendar {
+		t.Fatalf("A configured calendar must not be deleted: expected:%v, got:%v\n", ErrConfiguredCalendar, err)
+	}
+
+	// The calendars removed from the configuration disappear
+	if err := sch.ConfigureCalendars(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := sch.calendars.get("weekends"); ok {
+		t.Fatalf("A calendar removed from the configuration must be deleted\n")
+	}
+
+	if _, ok := sch.calendars.get("holidays"); !ok {
+		t.Fatalf("The calendars managed through the API must be kept\n")
+	}
+}
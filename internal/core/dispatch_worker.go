@@ -2,21 +2,53 @@ package core
 
 import (
 	"context"
+	"errors"
+	"log"
 	"runtime"
+	"sync"
+	"time"
 )
 
-type DispatchFunc func(Event) error
+// maxRetryBackoff caps the exponential backoff between two attempts
+const maxRetryBackoff = 5 * time.Minute
+
+// persistenceRetryBackoff is the delay before the first retry of a failed completion or move, whatever the
+// retries of the deliveries
+var persistenceRetryBackoff = time.Second
+
+type DispatchFunc func(ctx context.Context, e Event) error
+
+// RetryError is returned by a DispatchFunc which only wants a part of the dispatch to be retried,
+// Retry is called instead of the DispatchFunc for the next attempts
+type RetryError struct {
+	Err   error
+	Retry DispatchFunc
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
 
 type DispatchManagerConfig struct {
 	WorkerNumber         int
 	DefaultQueueCapacity int
 	MaxQueueCapacity     int
+
+	// MaxRetries is the number of times a failed dispatch is attempted again, the delay between
+	// two attempts starts at RetryBackoff and doubles each time
+	MaxRetries   int
+	RetryBackoff time.Duration
 }
 
 type dispatchManager interface {
 	Dispatch(e event)
 	Run()
 	Stop()
+	SetConfig(config DispatchManagerConfig)
 }
 
 type _dispatchManager struct {
@@ -27,6 +59,9 @@ type _dispatchManager struct {
 	cancel  context.CancelFunc
 	metrics *metrics
 	ctx     context.Context
+	workers []context.CancelFunc
+	running bool
+	mu      *sync.Mutex
 }
 
 func newDispatchManager(ctx context.Context, pers PersistenceManager, fn DispatchFunc, config DispatchManagerConfig, metrics *metrics) dispatchManager {
@@ -40,18 +75,58 @@ func newDispatchManager(ctx context.Context, pers PersistenceManager, fn Dispatc
 		ctx:     ctx,
 		cancel:  cancel,
 		metrics: metrics,
+		mu:      &sync.Mutex{},
 	}
 
 	return d
 }
 
 func (d *_dispatchManager) Run() {
-	for i := 0; i < d.config.WorkerNumber; i++ {
-		go d.run()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.running = true
+	d.scale(d.config.WorkerNumber)
+}
+
+// SetConfig resizes the queue and the worker pool in place, so that the queued events are kept
+func (d *_dispatchManager) SetConfig(config DispatchManagerConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.qu.Lock()
+	d.qu.setCapacity(config.DefaultQueueCapacity, config.MaxQueueCapacity)
+	d.qu.Unlock()
+
+	d.config = config
+
+	if d.running {
+		d.scale(config.WorkerNumber)
+	}
+}
+
+// scale must be called with the lock held
+func (d *_dispatchManager) scale(n int) {
+	for len(d.workers) < n {
+		ctx, cancel := context.WithCancel(d.ctx)
+		d.workers = append(d.workers, cancel)
+
+		go d.run(ctx)
+	}
+
+	for len(d.workers) > n {
+		last := len(d.workers) - 1
+		d.workers[last]()
+		d.workers = d.workers[:last]
 	}
 }
 
 func (d *_dispatchManager) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.running = false
+	d.workers = nil
 	d.cancel()
 }
 
@@ -73,23 +148,124 @@ func (d *_dispatchManager) dispatch(u event) {
 		return
 	}
 
-	if ev.Mode == TimestampMode {
-		if err := d.pers.Delete(d.ctx, ev.ID); err != nil {
+	// The persisted event may not have been moved to the dispatched occurrence yet
+	ev.ShouldExecuteAt = u.ShouldExecuteAt
+	ev.FireCount = u.FireCount
+
+	firedAt := time.Now()
+
+	ctx, h := withHandoffs(d.ctx)
+	err = d.fn(ctx, ev)
+
+	ev.LastFiredAt = firedAt
+
+	// The dispatch is completed whatever its outcome so that the handoffs of the succeeding sinks are committed,
+	// the failed parts are then retried from memory
+	d.complete(ev, u.Next, h.all(), 0)
+
+	d.handle(ev, u.Next, 0, d.fn, err)
+}
+
+// complete persists the progression of the event along with the handoffs. It is attempted again until it
+// succeeds, so that neither the handoffs are lost nor the dispatched occurrence is dispatched again after a restart
+func (d *_dispatchManager) complete(ev Event, next time.Time, handoffs []Handoff, attempt int) {
+	err := d.pers.Complete(d.ctx, ev, next, handoffs)
+
+	if err == nil || d.ctx.Err() != nil {
+		return
+	}
+
+	delay := exponentialBackoff(persistenceRetryBackoff, attempt+1)
+
+	log.Printf("failed to complete the occurrence %s of event %s, retrying in %s: %v\n", ev.ShouldExecuteAt.Format(time.RFC3339), ev.ID, delay, err)
+
+	time.AfterFunc(delay, func() {
+		if d.ctx.Err() != nil {
 			return
 		}
+
+		d.complete(ev, next, handoffs, attempt+1)
+	})
+}
+
+// deliver attempts a failed dispatch again. Its handoffs are committed by completing the occurrence once more,
+// which does not move the event any further
+func (d *_dispatchManager) deliver(ev Event, next time.Time, attempt int, fn DispatchFunc) {
+	ctx, h := withHandoffs(d.ctx)
+	err := fn(ctx, ev)
+
+	if handoffs := h.all(); len(handoffs) > 0 {
+		d.complete(ev, next, handoffs, 0)
 	}
 
-	if err := d.fn(ev); err != nil {
+	d.handle(ev, next, attempt, fn, err)
+}
+
+func (d *_dispatchManager) handle(ev Event, next time.Time, attempt int, fn DispatchFunc, err error) {
+	if err != nil {
+		var retryErr *RetryError
+
+		if errors.As(err, &retryErr) && retryErr.Retry != nil {
+			fn = retryErr.Retry
+		}
+
+		d.retry(ev, next, attempt+1, fn)
 		return
 	}
 
 	d.metrics.Op()
 }
 
-func (d *_dispatchManager) run() {
+// backoff returns the delay before the attempt, or false if it must not be attempted
+func (d *_dispatchManager) backoff(attempt int) (time.Duration, bool) {
+	d.mu.Lock()
+	maxRetries, backoff := d.config.MaxRetries, d.config.RetryBackoff
+	d.mu.Unlock()
+
+	if attempt > maxRetries || d.ctx.Err() != nil {
+		return 0, false
+	}
+
+	return exponentialBackoff(backoff, attempt), true
+}
+
+// exponentialBackoff returns the delay before the attempt, it starts at base and doubles with each attempt
+// up to maxRetryBackoff
+func exponentialBackoff(base time.Duration, attempt int) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+
+	return delay
+}
+
+// retry delivers the event again after a backoff, the pending retries are kept in memory only
+func (d *_dispatchManager) retry(ev Event, next time.Time, attempt int, fn DispatchFunc) {
+	delay, ok := d.backoff(attempt)
+
+	if !ok {
+		return
+	}
+
+	time.AfterFunc(delay, func() {
+		if d.ctx.Err() != nil {
+			return
+		}
+
+		d.deliver(ev, next, attempt, fn)
+	})
+}
+
+func (d *_dispatchManager) run(ctx context.Context) {
 	for {
 		select {
-		case <-d.ctx.Done():
+		case <-ctx.Done():
 			return
 		default:
 			d.qu.Lock()
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type _persistenceMock struct {
+	PersistenceManager
+	event    Event
+	next     time.Time
+	handoffs []Handoff
+}
+
+func (p *_persistenceMock) Get(ctx context.Context, id ID) (Event, error) {
+	return p.event, nil
+}
+
+func (p *_persistenceMock) Complete(ctx context.Context, e Event, next time.Time, handoffs []Handoff) error {
+	p.next = next
+	p.handoffs = handoffs
+	return nil
+}
+
+func TestDispatchManager_Complete(t *testing.T) {
+	at := time.Now()
+	pers := &_persistenceMock{event: Event{ID: "1", Mode: CronMode, ShouldExecuteAt: at.Add(-time.Hour)}}
+
+	var dispatched Event
+
+	fn := func(ctx context.Context, e Event) error {
+		dispatched = e
+
+		return AddHandoff(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return nil
+		})
+	}
+
+	met := newMetrics()
+	d := newDispatchManager(context.Background(), pers, fn, DispatchManagerConfig{}, &met).(*_dispatchManager)
+
+	d.dispatch(event{ID: "1", Mode: CronMode, ShouldExecuteAt: at, Next: at.Add(time.Minute)})
+
+	if !dispatched.ShouldExecuteAt.Equal(at) {
+		t.Fatalf("The dispatched occurrence must be the one popped: expected:%s, got:%s\n", at, dispatched.ShouldExecuteAt)
+	}
+
+	if !pers.next.Equal(at.Add(time.Minute)) || len(pers.handoffs) != 1 {
+		t.Fatalf("The dispatch must be completed with its next occurrence and handoffs: got %s, %d\n", pers.next, len(pers.handoffs))
+	}
+
+	if err := AddHandoff(context.Background(), nil); err != ErrNotTransactional {
+		t.Fatalf("A handoff requires a transactional dispatch: got %v\n", err)
+	}
+}
+
+func TestDispatchManager_Retry(t *testing.T) {
+	var calls int32
+
+	fn := func(ctx context.Context, e Event) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("unavailable")
+		}
+
+		return nil
+	}
+
+	met := newMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newDispatchManager(ctx, nil, fn, DispatchManagerConfig{
+		MaxRetries:   5,
+		RetryBackoff: time.Millisecond,
+	}, &met).(*_dispatchManager)
+
+	d.deliver(Event{ID: "1"}, time.Time{}, 0, fn)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&calls); c != 3 {
+		t.Fatalf("The event must be delivered until it succeeds: expected:%d, got:%d\n", 3, c)
+	}
+
+	atomic.StoreInt32(&calls, -10)
+	d.SetConfig(DispatchManagerConfig{MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+	d.deliver(Event{ID: "2"}, time.Time{}, 0, fn)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&calls); c != -7 {
+		t.Fatalf("The event must not be delivered more than MaxRetries+1 times: expected:%d, got:%d\n", 3, c+10)
+	}
+}
+
+func TestDispatchManager_RetryError(t *testing.T) {
+	var calls, retries int32
+
+	retry := func(ctx context.Context, e Event) error {
+		atomic.AddInt32(&retries, 1)
+		return nil
+	}
+
+	fn := func(ctx context.Context, e Event) error {
+		atomic.AddInt32(&calls, 1)
+		return &RetryError{Err: errors.New("partial failure"), Retry: retry}
+	}
+
+	met := newMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newDispatchManager(ctx, nil, fn, DispatchManagerConfig{
+		MaxRetries:   5,
+		RetryBackoff: time.Millisecond,
+	}, &met).(*_dispatchManager)
+
+	d.deliver(Event{ID: "1"}, time.Time{}, 0, fn)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c, r := atomic.LoadInt32(&calls), atomic.LoadInt32(&retries); c != 1 || r != 1 {
+		t.Fatalf("Only the failed part must be retried: expected:%d/%d, got:%d/%d\n", 1, 1, c, r)
+	}
+}
+
+type _failingPersistenceMock struct {
+	PersistenceManager
+	failures  int32
+	completed int32
+}
+
+func (p *_failingPersistenceMock) Complete(ctx context.Context, e Event, next time.Time, handoffs []Handoff) error {
+	if atomic.AddInt32(&p.failures, -1) >= 0 {
+		return errors.New("unavailable")
+	}
+
+	atomic.AddInt32(&p.completed, 1)
+
+	return nil
+}
+
+func (p *_failingPersistenceMock) Move(ctx context.Context, id ID, next time.Time) error {
+	return p.Complete(ctx, Event{ID: id}, next, nil)
+}
+
+func TestDispatchManager_CompleteRetry(t *testing.T) {
+	defer func(b time.Duration) { persistenceRetryBackoff = b }(persistenceRetryBackoff)
+	persistenceRetryBackoff = time.Millisecond
+
+	// The completion is attempted again even though the deliveries are not
+	pers := &_failingPersistenceMock{failures: 2}
+
+	met := newMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newDispatchManager(ctx, pers, nil, DispatchManagerConfig{}, &met).(*_dispatchManager)
+
+	d.complete(Event{ID: "1"}, time.Now(), nil, 0)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&pers.completed); c != 1 {
+		t.Fatalf("The progression of the event must be persisted once the persistence is back: expected:%d, got:%d\n", 1, c)
+	}
+}
+
+func TestDispatchManager_RetryHandoff(t *testing.T) {
+	var calls int32
+
+	fn := func(ctx context.Context, e Event) error {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return errors.New("unavailable")
+		}
+
+		return AddHandoff(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return nil
+		})
+	}
+
+	at := time.Now()
+	pers := &_persistenceMock{event: Event{ID: "1", Mode: CronMode}}
+
+	met := newMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newDispatchManager(ctx, pers, fn, DispatchManagerConfig{
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	}, &met).(*_dispatchManager)
+
+	d.dispatch(event{ID: "1", Mode: CronMode, ShouldExecuteAt: at, Next: at.Add(time.Minute)})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&calls); c != 2 {
+		t.Fatalf("The failed dispatch must be retried: expected:%d, got:%d\n", 2, c)
+	}
+
+	if !pers.next.Equal(at.Add(time.Minute)) || len(pers.handoffs) != 1 {
+		t.Fatalf("The handoffs of the retried dispatch must be completed: got %s, %d\n", pers.next, len(pers.handoffs))
+	}
+}
+
+func TestScheduler_MoveRetry(t *testing.T) {
+	defer func(b time.Duration) { persistenceRetryBackoff = b }(persistenceRetryBackoff)
+	persistenceRetryBackoff = time.Millisecond
+
+	pers := &_failingPersistenceMock{failures: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sch := &scheduler{pM: pers, ctx: ctx}
+	sch.move(event{ID: "1", Next: time.Now()}, 0)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&pers.completed); c != 1 {
+		t.Fatalf("The skipped occurrence must be persisted once the persistence is back: expected:%d, got:%d\n", 1, c)
+	}
+}
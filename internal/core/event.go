@@ -1,14 +1,29 @@
 package core
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 const (
 	TimestampMode = iota
 	CronMode
+	IntervalMode
+	RRuleMode
 )
 
 type EventMode uint
 
+// recurring tells whether the events of the mode have several occurrences
+func (m EventMode) recurring() bool {
+	return m != TimestampMode
+}
+
+var (
+	ErrInvalidBounds = errors.New("the end of the event must follow its start and the maximum number of occurrences must not be negative")
+	ErrNoOccurrence  = errors.New("the event has no occurrence before its end")
+)
+
 type ID string
 
 type event struct {
@@ -16,6 +31,24 @@ type event struct {
 	CronExpression  string
 	ShouldExecuteAt time.Time
 	Mode            EventMode
+	TimeZone        string
+	Interval        time.Duration
+	RRule           string
+	Anchor          time.Time
+	EndAt           time.Time
+	MaxOccurrences  int
+
+	// FireCount is the number of occurrences dispatched before this one
+	FireCount      int
+	Misfire        MisfirePolicy
+	LastFiredAt    time.Time
+	Calendar       string
+	CalendarPolicy CalendarPolicy
+	Jitter         time.Duration
+	JitterMode     JitterMode
+
+	// Next is the occurrence following ShouldExecuteAt, it is zero if the event does not recur
+	Next time.Time
 }
 
 type Event struct {
@@ -27,7 +60,7 @@ type Event struct {
 	// ShouldExecuteAt is the timestamp at which the event must be scheduled
 	ShouldExecuteAt time.Time
 
-	// Mode is the mode in which the event should be scheduled (CronMode or TimestampMode)
+	// Mode is the mode in which the event should be scheduled (TimestampMode, CronMode, IntervalMode or RRuleMode)
 	Mode EventMode
 
 	// Topic is the channel on which the event has to be dispatched
@@ -35,4 +68,50 @@ type Event struct {
 
 	// Payload is the content of the event
 	Payload []byte
+
+	// Labels are the key/value pairs the subscribers can filter the events of a topic on
+	Labels map[string]string
+
+	// TimeZone is the IANA name of the zone the cron expression or the rules are evaluated in, e.g. Europe/Paris.
+	// The local zone of the server is used if it is empty and the expression has no CRON_TZ= prefix
+	TimeZone string
+
+	// Interval is the duration between two occurrences of an IntervalMode event
+	Interval time.Duration
+
+	// RRule holds the RFC 5545 RRULE, EXRULE, RDATE and EXDATE lines of a RRuleMode event, one per line,
+	// e.g. "RRULE:FREQ=MONTHLY;BYDAY=2TU;UNTIL=20261231T000000Z"
+	RRule string
+
+	// Anchor aligns the occurrences of an IntervalMode event, they are the anchor plus a multiple of the interval.
+	// It is the DTSTART of a RRuleMode event whose rules have none. Both start with their first occurrence if it is zero
+	Anchor time.Time
+
+	// StartAt and EndAt bound the occurrences of a recurring event, they are ignored if zero
+	StartAt time.Time
+	EndAt   time.Time
+
+	// MaxOccurrences is the number of occurrences after which a recurring event is unscheduled,
+	// it recurs until EndAt if zero
+	MaxOccurrences int
+
+	// FireCount is the number of occurrences already dispatched, it is set by the scheduler
+	FireCount int
+
+	// Misfire tells what happens to the occurrences missed while the scheduler was down
+	Misfire MisfirePolicy
+
+	// LastFiredAt is the time at which the last occurrence was dispatched, it is set by the scheduler
+	LastFiredAt time.Time
+
+	// Calendar is the name of the calendar restricting the occurrences of a recurring event to business days
+	// and hours, CalendarPolicy tells whether the occurrences outside them are skipped or shifted
+	Calendar       string
+	CalendarPolicy CalendarPolicy
+
+	// Jitter is the window within which each occurrence is delayed to spread the load of the events due
+	// at the same time, the events without one take the jitter of their topic unless it is NoJitter. It must be
+	// shorter than the time between two occurrences, the jitter of the topic is shortened to fit instead
+	Jitter     time.Duration
+	JitterMode JitterMode
 }
@@ -94,6 +94,20 @@ func (s *eventQueue) resize(cap int) {
 	}
 }
 
+// setCapacity updates the default and max capacities, the max capacity never goes below the current one
+func (s *eventQueue) setCapacity(defaultCap int, maxCap int) {
+	if maxCap < s.cap {
+		maxCap = s.cap
+	}
+
+	if defaultCap > maxCap {
+		defaultCap = maxCap
+	}
+
+	s.maxCap = maxCap
+	s.defaultCap = defaultCap
+}
+
 func (s *eventQueue) Get(i int) *eventQueueNode {
 
 	node := s.start
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrNotTransactional = errors.New("the dispatch is not completed in a transaction")
+)
+
+// Handoff writes a dispatched event within the transaction completing its dispatch, so that the event
+// is handed off if and only if it is deleted or moved to its next occurrence
+type Handoff func(ctx context.Context, tx *sql.Tx) error
+
+type handoffsKey struct{}
+
+type handoffs struct {
+	mu   *sync.Mutex
+	list []Handoff
+}
+
+func withHandoffs(ctx context.Context) (context.Context, *handoffs) {
+	h := &handoffs{mu: &sync.Mutex{}}
+
+	return context.WithValue(ctx, handoffsKey{}, h), h
+}
+
+func (h *handoffs) add(fn Handoff) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.list = append(h.list, fn)
+}
+
+func (h *handoffs) all() []Handoff {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.list
+}
+
+// AddHandoff registers a handoff to the dispatch of ctx, the handoffs of a retried dispatch are committed
+// by completing the dispatched occurrence again
+func AddHandoff(ctx context.Context, fn Handoff) error {
+	h, ok := ctx.Value(handoffsKey{}).(*handoffs)
+
+	if !ok {
+		return ErrNotTransactional
+	}
+
+	h.add(fn)
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// MinInterval is the shortest duration between two occurrences of an IntervalMode event
+const MinInterval = time.Second
+
+var (
+	ErrInvalidInterval = errors.New("the interval must be at least one second")
+	ErrUnknownMode     = errors.New("the mode of the event is unknown")
+)
+
+// newEvent returns the scheduled occurrence of the event
+func newEvent(e Event) event {
+	return event{
+		ID:              e.ID,
+		CronExpression:  e.CronExpression,
+		ShouldExecuteAt: e.ShouldExecuteAt,
+		Mode:            e.Mode,
+		TimeZone:        e.TimeZone,
+		Interval:        e.Interval,
+		RRule:           e.RRule,
+		Anchor:          e.Anchor,
+		EndAt:           e.EndAt,
+		MaxOccurrences:  e.MaxOccurrences,
+		FireCount:       e.FireCount,
+		Misfire:         e.Misfire,
+		LastFiredAt:     e.LastFiredAt,
+		Calendar:        e.Calendar,
+		CalendarPolicy:  e.CalendarPolicy,
+		Jitter:          e.Jitter,
+		JitterMode:      e.JitterMode,
+	}
+}
+
+// parseRecurrence returns the schedule of the occurrences of a recurring event
+func parseRecurrence(p cron.Parser, e event) (cron.Schedule, error) {
+	switch e.Mode {
+	case CronMode:
+		return parseCron(p, e.CronExpression, e.TimeZone)
+	case IntervalMode:
+		if e.Interval < MinInterval {
+			return nil, ErrInvalidInterval
+		}
+
+		return intervalSchedule{interval: e.Interval, anchor: e.Anchor}, nil
+	case RRuleMode:
+		return parseRRule(e.RRule, e.TimeZone, e.Anchor)
+	}
+
+	return nil, ErrUnknownMode
+}
+
+// intervalSchedule fires at the anchor plus a multiple of the interval, the interval is a duration
+// so the occurrences do not depend on the time zone nor on the DST transitions
+type intervalSchedule struct {
+	interval time.Duration
+	anchor   time.Time
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.anchor) {
+		return s.anchor
+	}
+
+	return s.anchor.Add((t.Sub(s.anchor)/s.interval + 1) * s.interval)
+}
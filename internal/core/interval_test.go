@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_Interval(t *testing.T) {
+	sch := newTestScheduler()
+
+	startAt := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	if _, err := sch.Schedule(Event{Mode: IntervalMode, Interval: 90 * time.Second, StartAt: startAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sch.queue.Pop()
+
+	if !e.ShouldExecuteAt.Equal(startAt) || !e.Anchor.Equal(startAt) {
+		t.Fatalf("The intervals must start with the first occurrence: expected:%s, got:%s (anchored at %s)\n", startAt, e.ShouldExecuteAt, e.Anchor)
+	}
+
+	ev := newEvent(*e)
+
+	for i, expected := range []time.Time{startAt.Add(90 * time.Second), startAt.Add(180 * time.Second)} {
+		next, err := sch.next(ev)
+
+		if err != nil || !next.Equal(expected) {
+			t.Fatalf("Wrong occurrence %d: expected:%s, got:%s (%v)\n", i+1, expected, next, err)
+		}
+
+		ev.ShouldExecuteAt = next
+	}
+
+	// The occurrences remain aligned on the anchor
+	anchor := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := sch.Schedule(Event{Mode: IntervalMode, Interval: 36 * time.Hour, Anchor: anchor}); err != nil {
+		t.Fatal(err)
+	}
+
+	e = sch.queue.Pop()
+
+	if elapsed := e.ShouldExecuteAt.Sub(anchor); elapsed%(36*time.Hour) != 0 || e.ShouldExecuteAt.Before(time.Now()) {
+		t.Fatalf("The first occurrence must be aligned on the anchor: got %s\n", e.ShouldExecuteAt)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: IntervalMode, Interval: time.Millisecond}); err != ErrInvalidInterval {
+		t.Fatalf("An interval shorter than a second must be rejected: got %v\n", err)
+	}
+}
+
+func TestIntervalSchedule_Next(t *testing.T) {
+	anchor := utc("2026-03-08T00:00:00Z")
+	s := intervalSchedule{interval: 90 * time.Second, anchor: anchor}
+
+	for _, tc := range []struct {
+		from     time.Time
+		expected time.Time
+	}{
+		{anchor.Add(-time.Hour), anchor},
+		{anchor, anchor.Add(90 * time.Second)},
+		{anchor.Add(89 * time.Second), anchor.Add(90 * time.Second)},
+		{anchor.Add(90 * time.Second), anchor.Add(180 * time.Second)},
+	} {
+		if got := s.Next(tc.from); !got.Equal(tc.expected) {
+			t.Fatalf("Wrong occurrence following %s: expected:%s, got:%s\n", tc.from, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"hash/fnv"
+	"path"
+	"sync"
+	"time"
+)
+
+// JitterMode tells how the occurrences of an event are delayed within its jitter window
+type JitterMode uint
+
+const (
+	// JitterHash delays every occurrence by the same offset derived from the ID of the event,
+	// the event fires at the same time from one day to the next
+	JitterHash JitterMode = iota
+
+	// JitterRandom delays each occurrence by its own pseudo-random offset
+	JitterRandom
+)
+
+// NoJitter opts an event out of the jitter of its topic, it is -1 in the milliseconds of the API
+const NoJitter = -time.Millisecond
+
+// jitterCheckedOccurrences is the number of occurrences whose gaps are compared with the jitter window
+const jitterCheckedOccurrences = 100
+
+var (
+	ErrInvalidJitter     = errors.New("the jitter must not be negative")
+	ErrInvalidJitterMode = errors.New("the jitter mode is unknown")
+	ErrJitterTooLong     = errors.New("the jitter must be shorter than the time between two occurrences")
+)
+
+// TopicJitter is the jitter of the events of the topics matching one of its glob patterns which have none
+type TopicJitter struct {
+	Topics []string
+	Jitter time.Duration
+	Mode   JitterMode
+}
+
+// ValidateTopicJitter checks the patterns, the window and the mode of the jitter
+func ValidateTopicJitter(j TopicJitter) error {
+	if len(j.Topics) == 0 {
+		return errors.New("the jitter has no topic")
+	}
+
+	for _, t := range j.Topics {
+		if _, err := path.Match(t, ""); err != nil {
+			return fmt.Errorf("invalid topic pattern %q: %v", t, err)
+		}
+	}
+
+	if j.Jitter < 0 {
+		return ErrInvalidJitter
+	}
+
+	if j.Mode > JitterRandom {
+		return ErrInvalidJitterMode
+	}
+
+	return nil
+}
+
+// jitterOffset returns the delay, in whole milliseconds within the window, of the occurrence of the event
+// due at t. The offsets are computed again after a restart, so the random ones are seeded with t
+func jitterOffset(id ID, mode JitterMode, window time.Duration, t time.Time) time.Duration {
+	steps := uint64(window / time.Millisecond)
+
+	if steps == 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(id))
+
+	if mode == JitterRandom {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(t.Unix()))
+		h.Write(b[:])
+	}
+
+	return time.Duration(h.Sum64()%steps) * time.Millisecond
+}
+
+// shortestPeriod returns the shortest time between two of the occurrences following from, or 0 if there are
+// less than two of them. The jitter window must be shorter so that the jittered occurrences keep their order
+func (sch *scheduler) shortestPeriod(e Event, from time.Time) (time.Duration, error) {
+	e.Jitter = 0
+
+	s, err := sch.recurrence(newEvent(e))
+
+	if err != nil {
+		return 0, err
+	}
+
+	var period time.Duration
+
+	prev := s.Next(from)
+
+	for i := 0; i < jitterCheckedOccurrences && !prev.IsZero(); i++ {
+		o := s.Next(prev)
+
+		if o.IsZero() {
+			break
+		}
+
+		if d := o.Sub(prev); period == 0 || d < period {
+			period = d
+		}
+
+		prev = o
+	}
+
+	return period, nil
+}
+
+// jitterSchedule delays the occurrences of a schedule, the window is shorter than the time between
+// two occurrences so that they keep their order
+type jitterSchedule struct {
+	cron.Schedule
+	id     ID
+	window time.Duration
+	mode   JitterMode
+}
+
+func (s jitterSchedule) Next(t time.Time) time.Time {
+	// An occurrence delayed after t is due less than a window before it
+	for o := s.Schedule.Next(t.Add(-s.window)); !o.IsZero(); o = s.Schedule.Next(o) {
+		if d := o.Add(jitterOffset(s.id, s.mode, s.window, o)); d.After(t) {
+			return d
+		}
+	}
+
+	return time.Time{}
+}
+
+// withTopicJitter gives the event the jitter of its topic if it has none, and tells whether it did
+func (sch *scheduler) withTopicJitter(e Event) (Event, bool) {
+	switch e.Jitter {
+	case 0:
+		e.Jitter, e.JitterMode = sch.jitters.get(e.Topic)
+
+		return e, e.Jitter > 0
+	case NoJitter:
+		e.Jitter = 0
+	}
+
+	return e, false
+}
+
+// topicJitters holds the default jitters of the topics, they are changed along with the configuration
+type topicJitters struct {
+	mu    sync.RWMutex
+	rules []TopicJitter
+}
+
+func (tj *topicJitters) set(rules []TopicJitter) {
+	tj.mu.Lock()
+	defer tj.mu.Unlock()
+
+	tj.rules = rules
+}
+
+// get returns the jitter of the first rule matching the topic
+func (tj *topicJitters) get(topic string) (time.Duration, JitterMode) {
+	tj.mu.RLock()
+	defer tj.mu.RUnlock()
+
+	for _, r := range tj.rules {
+		for _, t := range r.Topics {
+			if ok, _ := path.Match(t, topic); ok {
+				return r.Jitter, r.Mode
+			}
+		}
+	}
+
+	return 0, JitterHash
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitterSchedule_Next(t *testing.T) {
+	s, err := testParser.Parse("0 0 * * *")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := utc("2026-01-01T12:00:00Z")
+
+	for _, mode := range []JitterMode{JitterHash, JitterRandom} {
+		js := jitterSchedule{Schedule: s, id: "a5f1c2e4", window: time.Hour, mode: mode}
+		o := from
+		offsets := map[time.Duration]bool{}
+
+		for i := 0; i < 10; i++ {
+			next := js.Next(o)
+			day := utc("2026-01-02T00:00:00Z").AddDate(0, 0, i)
+
+			if next.Before(day) || !next.Before(day.Add(time.Hour)) {
+				t.Fatalf("The occurrence %d must be delayed within the window: expected:%s, got:%s\n", i, day, next)
+			}
+
+			offsets[next.Sub(day)] = true
+			o = next
+		}
+
+		if mode == JitterHash && len(offsets) != 1 {
+			t.Fatalf("The occurrences must keep the same offset: got %v\n", offsets)
+		}
+
+		if mode == JitterRandom && len(offsets) == 1 {
+			t.Fatalf("The occurrences must have their own offset: got %v\n", offsets)
+		}
+	}
+
+	js := jitterSchedule{Schedule: s, id: "a5f1c2e4", window: time.Hour}
+
+	if a, b := js.Next(from), (jitterSchedule{Schedule: s, id: "b7d3e9f0", window: time.Hour}).Next(from); a.Equal(b) {
+		t.Fatalf("The events must be spread: both at %s\n", a)
+	}
+
+	// The occurrence is the same whether it is computed from the previous one or from just before it
+	if next := js.Next(from); !js.Next(next.Add(-time.Millisecond)).Equal(next) {
+		t.Fatalf("The occurrences must be stable: expected:%s, got:%s\n", next, js.Next(next.Add(-time.Millisecond)))
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	sch := newTestScheduler()
+
+	sch.jitters.set([]TopicJitter{{Topics: []string{"reports.*"}, Jitter: 10 * time.Minute}})
+
+	startAt := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 0 * * *", TimeZone: "UTC", StartAt: startAt, Topic: "reports.daily"}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sch.queue.Pop()
+
+	if e.Jitter != 10*time.Minute || e.ShouldExecuteAt.Before(startAt) || !e.ShouldExecuteAt.Before(startAt.Add(10*time.Minute)) {
+		t.Fatalf("The default jitter of the topic must apply: got %s within %s\n", e.ShouldExecuteAt, e.Jitter)
+	}
+
+	next, err := sch.next(newEvent(*e))
+
+	if err != nil || next.Sub(e.ShouldExecuteAt) != 24*time.Hour {
+		t.Fatalf("The next occurrence must keep the offset: got %s (%v)\n", next, err)
+	}
+
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	if _, err := sch.Schedule(Event{ShouldExecuteAt: at, Jitter: time.Minute, Topic: "billing"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e := sch.queue.Pop(); e.Jitter != time.Minute || e.ShouldExecuteAt.Before(at) || !e.ShouldExecuteAt.Before(at.Add(time.Minute)) {
+		t.Fatalf("The one-shot events must be delayed as well: got %s\n", e.ShouldExecuteAt)
+	}
+
+	if _, err := sch.Schedule(Event{ShouldExecuteAt: at, Jitter: -time.Second}); err != ErrInvalidJitter {
+		t.Fatalf("A negative jitter must be rejected: expected:%v, got:%v\n", ErrInvalidJitter, err)
+	}
+
+	if _, err := sch.Schedule(Event{ShouldExecuteAt: at, Jitter: time.Second, JitterMode: 7}); err != ErrInvalidJitterMode {
+		t.Fatalf("An unknown jitter mode must be rejected: expected:%v, got:%v\n", ErrInvalidJitterMode, err)
+	}
+	// An event opts out of the jitter of its topic
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 0 * * *", TimeZone: "UTC", StartAt: startAt, Topic: "reports.daily", Jitter: NoJitter}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e := sch.queue.Pop(); e.Jitter != 0 || !e.ShouldExecuteAt.Equal(startAt) {
+		t.Fatalf("The event must not be delayed: got %s within %s\n", e.ShouldExecuteAt, e.Jitter)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 * * * *", Jitter: time.Hour}); err != ErrJitterTooLong {
+		t.Fatalf("A jitter as long as the period must be rejected: expected:%v, got:%v\n", ErrJitterTooLong, err)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 9,10 * * *", Jitter: 2 * time.Hour}); err != ErrJitterTooLong {
+		t.Fatalf("A jitter longer than the shortest period must be rejected: expected:%v, got:%v\n", ErrJitterTooLong, err)
+	}
+
+	// The default jitter of the topic does not fail the events scheduled more often than it
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "*/5 * * * *", TimeZone: "UTC", StartAt: startAt, Topic: "reports.frequent"}); err != nil {
+		t.Fatalf("The jitter of the topic must not be rejected: %v\n", err)
+	}
+
+	if e := sch.queue.Pop(); e.Jitter != 5*time.Minute-time.Millisecond || e.ShouldExecuteAt.Before(startAt) || !e.ShouldExecuteAt.Before(startAt.Add(5*time.Minute)) {
+		t.Fatalf("The jitter of the topic must be shortened below the period: got %s within %s\n", e.ShouldExecuteAt, e.Jitter)
+	}
+
+	if _, err := sch.Preview(Event{Mode: CronMode, CronExpression: "*/5 * * * *", Topic: "reports.frequent"}, 3); err != nil {
+		t.Fatalf("The jitter of the topic must not fail the preview: %v\n", err)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+const (
+	MaxLabels          = 64
+	MaxLabelValueBytes = 255
+)
+
+var labelKey = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.\-/]{0,62})$`)
+
+// ValidateLabels checks that the keys are short identifiers, made of letters, digits, "_", ".", "-" and "/"
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("an event must not have more than %d labels, got %d", MaxLabels, len(labels))
+	}
+
+	for k, v := range labels {
+		if !labelKey.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+
+		if len(v) > MaxLabelValueBytes {
+			return fmt.Errorf("the value of the label %q must not be longer than %d bytes", k, MaxLabelValueBytes)
+		}
+	}
+
+	return nil
+}
+
+// encodeLabels stores the labels as a JSON object, or an empty string if there is none
+func encodeLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(labels)
+
+	return string(b), err
+}
+
+func decodeLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var labels map[string]string
+
+	if err := json.Unmarshal([]byte(s), &labels); err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+type _rowMock []interface{}
+
+func (r _rowMock) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *ID:
+			*d = r[i].(ID)
+		case *string:
+			*d = r[i].(string)
+		case *time.Time:
+			*d = r[i].(time.Time)
+		case *EventMode:
+			*d = r[i].(EventMode)
+		case *[]byte:
+			*d = r[i].([]byte)
+		case *sql.NullString:
+			*d = r[i].(sql.NullString)
+		case *sql.NullTime:
+			*d = r[i].(sql.NullTime)
+		case *sql.NullInt64:
+			*d = r[i].(sql.NullInt64)
+		}
+	}
+
+	return nil
+}
+
+func TestValidateLabels(t *testing.T) {
+	valid := map[string]string{"tenant": "acme", "app.kubernetes.io/name": "billing", "region": ""}
+
+	if err := ValidateLabels(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"": "acme"},
+		{"ten ant": "acme"},
+		{"-tenant": "acme"},
+		{"tenant": strings.Repeat("a", MaxLabelValueBytes+1)},
+	} {
+		if err := ValidateLabels(invalid); err == nil {
+			t.Fatalf("The labels %v must be rejected\n", invalid)
+		}
+	}
+}
+
+func TestScanEvent_Labels(t *testing.T) {
+	e := Event{ID: "1", Topic: "billing", Payload: []byte("hello"), Labels: map[string]string{"tenant": "acme"}}
+
+	values, err := eventValues(e)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != len(eventColumns) {
+		t.Fatalf("Wrong number of values: expected:%d, got:%d\n", len(eventColumns), len(values))
+	}
+
+	row := _rowMock{e.ID, "", time.Time{}, EventMode(0), sql.NullString{String: e.Topic, Valid: true}, e.Payload, sql.NullString{String: values[6].(string), Valid: true}, sql.NullString{}, sql.NullTime{}, sql.NullTime{}, sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{}, sql.NullTime{}, sql.NullInt64{}, sql.NullTime{}, sql.NullString{}, sql.NullString{}, sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{}}
+
+	got, err := scanEvent(row)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Topic != "billing" || got.Labels["tenant"] != "acme" {
+		t.Fatalf("Wrong event: %+v\n", got)
+	}
+
+	// The rows created before the labels were added have no labels
+	row[6] = sql.NullString{}
+
+	if got, err := scanEvent(row); err != nil || got.Labels != nil {
+		t.Fatalf("Wrong labels of a migrated row: %v, %v\n", got.Labels, err)
+	}
+
+	if placeholders(1) != "($22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42)" {
+		t.Fatalf("Wrong placeholders: %s\n", placeholders(1))
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// MisfirePolicy tells what happens to the occurrences of a recurring event missed during a downtime
+type MisfirePolicy uint
+
+const (
+	// MisfireFireOnce dispatches a single occurrence for all the missed ones, then resumes at the next one
+	MisfireFireOnce MisfirePolicy = iota
+
+	// MisfireFireAll dispatches every missed occurrence, up to MaxCatchUpOccurrences of the most recent ones
+	MisfireFireAll
+
+	// MisfireSkip drops the missed occurrences and resumes at the next one
+	MisfireSkip
+)
+
+var ErrInvalidMisfirePolicy = errors.New("the misfire policy is unknown")
+
+// MisfireThreshold is the delay after which an occurrence which has not been dispatched is missed
+var MisfireThreshold = time.Minute
+
+const MaxCatchUpOccurrences = 100
+
+// misfired tells whether the occurrence of the event is missed at now. An event which fired within
+// the threshold, e.g. right before a restart, is only lagging behind its occurrences
+func misfired(e event, now time.Time) bool {
+	return e.Mode.recurring() && now.Sub(e.ShouldExecuteAt) > MisfireThreshold && now.Sub(e.LastFiredAt) > MisfireThreshold
+}
+
+// misfire applies the misfire policy of the event to its occurrence due at now. It returns false
+// if the occurrence must not be dispatched, the event is then moved to its next occurrence
+func (sch *scheduler) misfire(e event, now time.Time) (event, bool) {
+	if !misfired(e, now) {
+		return e, true
+	}
+
+	s, err := sch.recurrence(e)
+
+	// The occurrence is dispatched, the event is deleted once dispatched
+	if err != nil {
+		return e, true
+	}
+
+	switch e.Misfire {
+	case MisfireSkip:
+		e.Next = s.Next(now)
+
+		if !e.EndAt.IsZero() && e.Next.After(e.EndAt) {
+			e.Next = time.Time{}
+		}
+
+		return e, false
+	case MisfireFireAll:
+		e.ShouldExecuteAt = catchUp(s, e.ShouldExecuteAt, now)
+	}
+
+	return e, true
+}
+
+// catchUp returns the first of the MaxCatchUpOccurrences most recent occurrences from the missed one up to now.
+// The occurrences are walked from about MaxCatchUpOccurrences periods before now, the window is widened
+// until it holds enough of them
+func catchUp(s cron.Schedule, missed, now time.Time) time.Time {
+	var span time.Duration
+
+	// The period is estimated from the next occurrences
+	if a := s.Next(now); !a.IsZero() {
+		if b := s.Next(a); !b.IsZero() {
+			span = b.Sub(a) * MaxCatchUpOccurrences
+		}
+	}
+
+	for {
+		o := missed
+
+		if span > 0 && span < now.Sub(missed) {
+			o = s.Next(now.Add(-span))
+		}
+
+		// Only the most recent missed occurrences are kept
+		recent := make([]time.Time, 0, MaxCatchUpOccurrences)
+
+		for ; !o.IsZero() && !o.After(now); o = s.Next(o) {
+			if len(recent) == MaxCatchUpOccurrences {
+				recent = append(recent[:0], recent[1:]...)
+			}
+
+			recent = append(recent, o)
+		}
+
+		if len(recent) == MaxCatchUpOccurrences || span <= 0 || span >= now.Sub(missed) {
+			if len(recent) == 0 {
+				return missed
+			}
+
+			return recent[0]
+		}
+
+		span *= 2
+	}
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_Misfire(t *testing.T) {
+	sch := &scheduler{cr: testParser}
+
+	now := time.Now().UTC()
+	hour := now.Truncate(time.Hour)
+	missed := event{Mode: CronMode, CronExpression: "0 * * * *", TimeZone: "UTC", ShouldExecuteAt: hour.Add(-5 * time.Hour)}
+
+	// A single occurrence is dispatched, the next one follows now
+	e, fire := sch.misfire(missed, now)
+	next, _ := sch.next(e)
+
+	if !fire || !e.ShouldExecuteAt.Equal(missed.ShouldExecuteAt) || !next.Equal(hour.Add(time.Hour)) {
+		t.Fatalf("Wrong catch-up of the fire once policy: %t, %s, %s\n", fire, e.ShouldExecuteAt, next)
+	}
+
+	missed.Misfire = MisfireSkip
+
+	if e, fire := sch.misfire(missed, now); fire || !e.Next.Equal(hour.Add(time.Hour)) {
+		t.Fatalf("Wrong catch-up of the skip policy: %t, %s\n", fire, e.Next)
+	}
+
+	missed.Misfire = MisfireFireAll
+	e, fire = sch.misfire(missed, now)
+	next, _ = sch.next(e)
+
+	if !fire || !e.ShouldExecuteAt.Equal(missed.ShouldExecuteAt) || !next.Equal(missed.ShouldExecuteAt.Add(time.Hour)) {
+		t.Fatalf("Every missed occurrence must be dispatched: %t, %s, %s\n", fire, e.ShouldExecuteAt, next)
+	}
+
+	// Only the most recent occurrences are dispatched
+	secondly := event{Mode: CronMode, CronExpression: "* * * * * *", ShouldExecuteAt: now.Truncate(time.Second).Add(-time.Hour), Misfire: MisfireFireAll}
+
+	if e, _ := sch.misfire(secondly, now); !e.ShouldExecuteAt.Equal(now.Truncate(time.Second).Add(-(MaxCatchUpOccurrences - 1) * time.Second)) {
+		t.Fatalf("Wrong first caught-up occurrence: expected:%s, got:%s\n", now.Truncate(time.Second).Add(-(MaxCatchUpOccurrences-1)*time.Second), e.ShouldExecuteAt)
+	}
+
+	// The occurrences of a long downtime are not all walked
+	start := time.Now()
+	secondly.ShouldExecuteAt = now.Truncate(time.Second).AddDate(0, -1, 0)
+
+	if e, _ := sch.misfire(secondly, now); !e.ShouldExecuteAt.Equal(now.Truncate(time.Second).Add(-(MaxCatchUpOccurrences - 1) * time.Second)) {
+		t.Fatalf("Wrong first caught-up occurrence after a month: got:%s\n", e.ShouldExecuteAt)
+	}
+
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("The catch-up must only walk the most recent occurrences: took %s\n", d)
+	}
+
+	// The occurrences of a sparse schedule are found by widening the window
+	weekly := event{Mode: CronMode, CronExpression: "0 9 * * 1", TimeZone: "UTC", ShouldExecuteAt: now.AddDate(0, 0, -30), Misfire: MisfireFireAll}
+
+	if e, _ := sch.misfire(weekly, now); e.ShouldExecuteAt.Sub(weekly.ShouldExecuteAt) >= 7*24*time.Hour {
+		t.Fatalf("Every missed occurrence must be caught up: expected after:%s, got:%s\n", weekly.ShouldExecuteAt, e.ShouldExecuteAt)
+	}
+
+	// An event which just fired is lagging behind rather than missed
+	lagging := missed
+	lagging.Misfire = MisfireSkip
+	lagging.LastFiredAt = now.Add(-time.Second)
+
+	if _, fire := sch.misfire(lagging, now); !fire {
+		t.Fatalf("An event which fired within the threshold must not be missed\n")
+	}
+
+	late := event{Mode: CronMode, CronExpression: "0 * * * *", ShouldExecuteAt: now.Add(-time.Second), Misfire: MisfireSkip}
+
+	if _, fire := sch.misfire(late, now); !fire {
+		t.Fatalf("An occurrence dispatched within the threshold must not be missed\n")
+	}
+}
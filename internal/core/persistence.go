@@ -8,6 +8,9 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,7 +33,30 @@ const (
 			should_execute_at TIMESTAMP,
 			mode TINYINT,
 			topic VARCHAR(255),
-			payload VARBINARY
+			payload VARBINARY,
+			labels TEXT,
+			time_zone VARCHAR(64),
+			start_at TIMESTAMP NULL,
+			end_at TIMESTAMP NULL,
+			max_occurrences INT,
+			fire_count INT DEFAULT 0,
+			misfire_policy SMALLINT DEFAULT 0,
+			last_fired_at TIMESTAMP NULL,
+			repeat_interval BIGINT DEFAULT 0,
+			anchor TIMESTAMP NULL,
+			rrule TEXT,
+			calendar VARCHAR(255),
+			calendar_policy SMALLINT DEFAULT 0,
+			jitter BIGINT DEFAULT 0,
+			jitter_mode SMALLINT DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS calendars (
+			name VARCHAR(255) PRIMARY KEY,
+			time_zone VARCHAR(64),
+			excluded_dates TEXT,
+			working_days VARCHAR(32),
+			working_hours VARCHAR(16)
 		);
 	`
 
@@ -46,13 +72,140 @@ const (
 			should_execute_at TIMESTAMP,
 			mode SMALLINT,
 			topic VARCHAR(255),
-			payload BYTEA
+			payload BYTEA,
+			labels TEXT,
+			time_zone VARCHAR(64),
+			start_at TIMESTAMP NULL,
+			end_at TIMESTAMP NULL,
+			max_occurrences INT,
+			fire_count INT DEFAULT 0,
+			misfire_policy SMALLINT DEFAULT 0,
+			last_fired_at TIMESTAMP NULL,
+			repeat_interval BIGINT DEFAULT 0,
+			anchor TIMESTAMP NULL,
+			rrule TEXT,
+			calendar VARCHAR(255),
+			calendar_policy SMALLINT DEFAULT 0,
+			jitter BIGINT DEFAULT 0,
+			jitter_mode SMALLINT DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS calendars (
+			name VARCHAR(255) PRIMARY KEY,
+			time_zone VARCHAR(64),
+			excluded_dates TEXT,
+			working_days VARCHAR(32),
+			working_hours VARCHAR(16)
 		);
 	`
 )
 
 var migrations = map[int]string{
-	1: `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS topic VARCHAR(255);`,
+	1:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS topic VARCHAR(255);`,
+	2:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS labels TEXT;`,
+	3:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS time_zone VARCHAR(64);`,
+	4:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS start_at TIMESTAMP NULL, ADD IF NOT EXISTS end_at TIMESTAMP NULL, ADD IF NOT EXISTS max_occurrences INT, ADD IF NOT EXISTS fire_count INT DEFAULT 0;`,
+	5:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS misfire_policy SMALLINT DEFAULT 0, ADD IF NOT EXISTS last_fired_at TIMESTAMP NULL;`,
+	6:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS repeat_interval BIGINT DEFAULT 0, ADD IF NOT EXISTS anchor TIMESTAMP NULL;`,
+	7:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS rrule TEXT;`,
+	8:  `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS calendar VARCHAR(255), ADD IF NOT EXISTS calendar_policy SMALLINT DEFAULT 0;`,
+	9:  `CREATE TABLE IF NOT EXISTS calendars (name VARCHAR(255) PRIMARY KEY, time_zone VARCHAR(64), excluded_dates TEXT, working_days VARCHAR(32), working_hours VARCHAR(16));`,
+	10: `ALTER TABLE IF EXISTS events ADD IF NOT EXISTS jitter BIGINT DEFAULT 0, ADD IF NOT EXISTS jitter_mode SMALLINT DEFAULT 0;`,
+}
+
+// eventColumns are listed explicitly so that the queries do not depend on the order of the migrated columns
+var eventColumns = []string{"id", "cron_expression", "should_execute_at", "mode", "topic", "payload", "labels", "time_zone", "start_at", "end_at", "max_occurrences", "fire_count", "misfire_policy", "last_fired_at", "repeat_interval", "anchor", "rrule", "calendar", "calendar_policy", "jitter", "jitter_mode"}
+
+var (
+	selectEvents = fmt.Sprintf("SELECT %s FROM events", strings.Join(eventColumns, ", "))
+	insertEvents = fmt.Sprintf("INSERT INTO events (%s) VALUES", strings.Join(eventColumns, ", "))
+)
+
+// placeholders returns the placeholders of the n-th row of values, e.g. ($8, $9, ...) for the second row
+func placeholders(n int) string {
+	ph := make([]string, len(eventColumns))
+
+	for i := range ph {
+		ph[i] = fmt.Sprintf("$%d", n*len(eventColumns)+i+1)
+	}
+
+	return "(" + strings.Join(ph, ", ") + ")"
+}
+
+// eventValues returns the values of the event in the order of eventColumns
+func eventValues(e Event) ([]interface{}, error) {
+	labels, err := encodeLabels(e.Labels)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{string(e.ID), e.CronExpression, e.ShouldExecuteAt, e.Mode, e.Topic, e.Payload, labels, e.TimeZone, nullTime(e.StartAt), nullTime(e.EndAt), e.MaxOccurrences, e.FireCount, e.Misfire, nullTime(e.LastFiredAt), int64(e.Interval), nullTime(e.Anchor), e.RRule, e.Calendar, e.CalendarPolicy, int64(e.Jitter), e.JitterMode}, nil
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent reads a row selected with eventColumns, the columns added by the migrations may be NULL
+func scanEvent(row rowScanner) (Event, error) {
+	e := Event{}
+
+	var topic, labels, timeZone, rrule, cal sql.NullString
+	var startAt, endAt, lastFiredAt, anchor sql.NullTime
+	var maxOccurrences, fireCount, misfire, interval, calendarPolicy, jitter, jitterMode sql.NullInt64
+
+	err := row.Scan(
+		&e.ID,
+		&e.CronExpression,
+		&e.ShouldExecuteAt,
+		&e.Mode,
+		&topic,
+		&e.Payload,
+		&labels,
+		&timeZone,
+		&startAt,
+		&endAt,
+		&maxOccurrences,
+		&fireCount,
+		&misfire,
+		&lastFiredAt,
+		&interval,
+		&anchor,
+		&rrule,
+		&cal,
+		&calendarPolicy,
+		&jitter,
+		&jitterMode,
+	)
+
+	if err != nil {
+		return e, err
+	}
+
+	e.Topic = topic.String
+	e.TimeZone = timeZone.String
+	e.StartAt = startAt.Time
+	e.EndAt = endAt.Time
+	e.MaxOccurrences = int(maxOccurrences.Int64)
+	e.FireCount = int(fireCount.Int64)
+	e.Misfire = MisfirePolicy(misfire.Int64)
+	e.LastFiredAt = lastFiredAt.Time
+	e.Interval = time.Duration(interval.Int64)
+	e.Anchor = anchor.Time
+	e.RRule = rrule.String
+	e.Calendar = cal.String
+	e.CalendarPolicy = CalendarPolicy(calendarPolicy.Int64)
+	e.Jitter = time.Duration(jitter.Int64)
+	e.JitterMode = JitterMode(jitterMode.Int64)
+	e.Labels, err = decodeLabels(labels.String)
+
+	return e, err
 }
 
 var (
@@ -65,6 +218,22 @@ type PersistenceManager interface {
 	Delete(ctx context.Context, id ID) error
 	Get(ctx context.Context, id ID) (Event, error)
 	GetAll(ctx context.Context) ([]Event, error)
+
+	// Complete ends the dispatch of an occurrence: the handoffs are run and the event is deleted,
+	// or moved to its next occurrence if next is not zero along with its fire count and last fire time,
+	// in a single transaction
+	Complete(ctx context.Context, e Event, next time.Time, handoffs []Handoff) error
+
+	// Move moves the event to its next occurrence without dispatching the current one
+	Move(ctx context.Context, id ID, next time.Time) error
+}
+
+// CalendarStore persists the calendars, they are kept in memory only if the persistence manager of the
+// scheduler is not a CalendarStore
+type CalendarStore interface {
+	SaveCalendar(ctx context.Context, c Calendar) error
+	DeleteCalendar(ctx context.Context, name string) error
+	GetCalendars(ctx context.Context) ([]Calendar, error)
 }
 
 type SqlPersistenceManagerConfig struct {
@@ -196,7 +365,7 @@ func (m *sqlPersistenceManager) AddBulk(ctx context.Context, evs []Event) error
 	}
 
 	if m.Driver == "postgres" {
-		stmt, err := tx.Prepare(pq.CopyIn("events", "id", "cron_expression", "should_execute_at", "mode", "topic", "payload"))
+		stmt, err := tx.Prepare(pq.CopyIn("events", eventColumns...))
 
 		if err != nil {
 			if rollErr := tx.Rollback(); rollErr != nil {
@@ -207,7 +376,12 @@ func (m *sqlPersistenceManager) AddBulk(ctx context.Context, evs []Event) error
 		}
 
 		for _, e := range evs {
-			_, err = stmt.Exec(e.ID, e.CronExpression, e.ShouldExecuteAt, e.Mode, e.Topic, e.Payload)
+			args, err := eventValues(e)
+
+			if err == nil {
+				_, err = stmt.Exec(args...)
+			}
+
 			if err != nil {
 				if rollErr := tx.Rollback(); rollErr != nil {
 					return rollErr
@@ -241,21 +415,28 @@ func (m *sqlPersistenceManager) AddBulk(ctx context.Context, evs []Event) error
 	}
 
 	if m.Driver == "mysql" {
-		q := `INSERT INTO events VALUES`
+		q := insertEvents
 
-		args := make([]interface{}, 6*len(evs))
+		args := make([]interface{}, 0, len(eventColumns)*len(evs))
 
-		i := 0
-		for _, e := range evs {
-			q = fmt.Sprintf("%s ($%d, $%d, $%d, $%d, $%d, $%d)", q, i+1, i+2, i+3, i+4, i+5, i+6)
+		for i, e := range evs {
+			values, err := eventValues(e)
 
-			args = append(args, e.ID, e.CronExpression, e.ShouldExecuteAt, e.Mode, e.Topic, e.Payload)
+			if err != nil {
+				if rollErr := tx.Rollback(); rollErr != nil {
+					return rollErr
+				}
+
+				return err
+			}
+
+			q = fmt.Sprintf("%s %s", q, placeholders(i))
+
+			args = append(args, values...)
 
 			if i < len(evs)-1 {
 				q += ",\n"
 			}
-
-			i += 6
 		}
 
 		q += ";"
@@ -304,7 +485,17 @@ func (m *sqlPersistenceManager) Add(ctx context.Context, e Event) error {
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `INSERT INTO events VALUES ($1, $2, $3, $4, $5, $6);`, e.ID, e.CronExpression, e.ShouldExecuteAt, e.Mode, e.Topic, e.Payload)
+	args, err := eventValues(e)
+
+	if err != nil {
+		if rollErr := tx.Rollback(); rollErr != nil {
+			return rollErr
+		}
+
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, insertEvents+" "+placeholders(0)+";", args...)
 
 	if err != nil {
 		if rollErr := tx.Rollback(); rollErr != nil {
@@ -357,6 +548,103 @@ func (m *sqlPersistenceManager) Delete(ctx context.Context, id ID) error {
 	return tx.Commit()
 }
 
+func (m *sqlPersistenceManager) Complete(ctx context.Context, e Event, next time.Time, handoffs []Handoff) error {
+	tx, err := m.createTx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, h := range handoffs {
+		if err := h(ctx, tx); err != nil {
+			if rollErr := tx.Rollback(); rollErr != nil {
+				return rollErr
+			}
+
+			return err
+		}
+	}
+
+	var res sql.Result
+
+	// The occurrences may be completed out of order, the event is only moved forward
+	if next.IsZero() {
+		res, err = tx.ExecContext(ctx, `DELETE FROM events WHERE id = $1;`, string(e.ID))
+	} else {
+		res, err = tx.ExecContext(ctx, `UPDATE events SET should_execute_at = $1, fire_count = $2, last_fired_at = $3 WHERE id = $4 AND should_execute_at < $1;`, next, e.FireCount+1, nullTime(e.LastFiredAt), string(e.ID))
+	}
+
+	if err != nil {
+		if rollErr := tx.Rollback(); rollErr != nil {
+			return rollErr
+		}
+
+		return err
+	}
+
+	moved, err := res.RowsAffected()
+
+	if err != nil {
+		if rollErr := tx.Rollback(); rollErr != nil {
+			return rollErr
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The cache follows the committed transaction, the cached event is dropped if it cannot be updated
+	// so that it is read again from the database
+	if moved > 0 {
+		err = m.cache.Complete(ctx, e, next, nil)
+	}
+
+	if moved == 0 || err != nil {
+		m.dropCached(ctx, e.ID)
+	}
+
+	return nil
+}
+
+// dropCached removes an event which has been changed in the database from the cache. A failure is not returned
+// since the change is committed, the cached event is then stale until it expires
+func (m *sqlPersistenceManager) dropCached(ctx context.Context, id ID) {
+	if err := m.cache.Delete(ctx, id); err != nil {
+		log.Printf("failed to drop event %s from the cache: %v\n", id, err)
+	}
+}
+
+func (m *sqlPersistenceManager) Move(ctx context.Context, id ID, next time.Time) error {
+	tx, err := m.createTx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE events SET should_execute_at = $1 WHERE id = $2 AND should_execute_at < $1;`, next, string(id))
+
+	if err != nil {
+		if rollErr := tx.Rollback(); rollErr != nil {
+			return rollErr
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := m.cache.Move(ctx, id, next); err != nil {
+		m.dropCached(ctx, id)
+	}
+
+	return nil
+}
+
 func (m *sqlPersistenceManager) Get(ctx context.Context, id ID) (e Event, err error) {
 	e, err = m.cache.Get(ctx, id)
 
@@ -387,16 +675,9 @@ func (m *sqlPersistenceManager) Get(ctx context.Context, id ID) (e Event, err er
 				return e, ErrNotFound
 			}
 
-			row = tx.QueryRowContext(ctx, `SELECT * FROM events WHERE id = $1;`, string(id))
+			row = tx.QueryRowContext(ctx, selectEvents+" WHERE id = $1;", string(id))
 
-			err = row.Scan(
-				&e.ID,
-				&e.CronExpression,
-				&e.ShouldExecuteAt,
-				&e.Mode,
-				&e.Topic,
-				&e.Payload,
-			)
+			e, err = scanEvent(row)
 
 			if err != nil {
 				if rollErr := tx.Rollback(); rollErr != nil {
@@ -430,7 +711,7 @@ func (m *sqlPersistenceManager) GetAll(ctx context.Context) (out []Event, err er
 		return out, err
 	}
 
-	rows, err := tx.QueryContext(ctx, "SELECT * FROM events;")
+	rows, err := tx.QueryContext(ctx, selectEvents+";")
 
 	if err != nil {
 		if rollErr := tx.Rollback(); rollErr != nil {
@@ -441,16 +722,9 @@ func (m *sqlPersistenceManager) GetAll(ctx context.Context) (out []Event, err er
 	}
 
 	for rows.Next() {
-		e := Event{}
-
-		if err := rows.Scan(
-			&e.ID,
-			&e.CronExpression,
-			&e.ShouldExecuteAt,
-			&e.Mode,
-			&e.Topic,
-			&e.Payload,
-		); err != nil {
+		e, err := scanEvent(rows)
+
+		if err != nil {
 			if rollErr := tx.Rollback(); rollErr != nil {
 				return out, rollErr
 			}
@@ -473,3 +747,82 @@ func (m *sqlPersistenceManager) GetAll(ctx context.Context) (out []Event, err er
 
 	return out, tx.Commit()
 }
+
+func (m *sqlPersistenceManager) SaveCalendar(ctx context.Context, c Calendar) error {
+	tx, err := m.createTx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	days := make([]string, len(c.WorkingDays))
+
+	for i, d := range c.WorkingDays {
+		days[i] = strconv.Itoa(int(d))
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM calendars WHERE name = $1;`, c.Name)
+
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `INSERT INTO calendars (name, time_zone, excluded_dates, working_days, working_hours) VALUES ($1, $2, $3, $4, $5);`,
+			c.Name, c.TimeZone, strings.Join(c.ExcludedDates, ","), strings.Join(days, ","), c.WorkingHours)
+	}
+
+	if err != nil {
+		if rollErr := tx.Rollback(); rollErr != nil {
+			return rollErr
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *sqlPersistenceManager) DeleteCalendar(ctx context.Context, name string) error {
+	_, err := m.db.ExecContext(ctx, `DELETE FROM calendars WHERE name = $1;`, name)
+
+	return err
+}
+
+func (m *sqlPersistenceManager) GetCalendars(ctx context.Context) (out []Calendar, err error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT name, time_zone, excluded_dates, working_days, working_hours FROM calendars;`)
+
+	if err != nil {
+		return out, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Calendar
+		var timeZone, dates, days, hours sql.NullString
+
+		if err := rows.Scan(&c.Name, &timeZone, &dates, &days, &hours); err != nil {
+			return out, err
+		}
+
+		c.TimeZone = timeZone.String
+		c.WorkingHours = hours.String
+
+		if dates.String != "" {
+			c.ExcludedDates = strings.Split(dates.String, ",")
+		}
+
+		if days.String != "" {
+			for _, d := range strings.Split(days.String, ",") {
+				day, err := strconv.Atoi(d)
+
+				if err != nil {
+					return out, err
+				}
+
+				c.WorkingDays = append(c.WorkingDays, time.Weekday(day))
+			}
+		}
+
+		out = append(out, c)
+	}
+
+	return out, rows.Err()
+}
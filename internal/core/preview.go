@@ -0,0 +1,459 @@
+package core
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPreviewOccurrences is the largest number of occurrences listed by a preview
+const MaxPreviewOccurrences = 100
+
+// starBit is set by the cron parser on the day fields written as * or ?
+const starBit = 1 << 63
+
+// Preview is the schedule of an event which has not been scheduled
+type Preview struct {
+	// Description tells in English when the event occurs
+	Description string
+
+	// Occurrences are the next occurrences of the event. They are not jittered since the jitter depends on
+	// the ID the event is given when it is scheduled
+	Occurrences []time.Time
+}
+
+// Preview validates the schedule of the event as Schedule does and lists its next n occurrences, the event is
+// not scheduled
+func (sch *scheduler) Preview(e Event, n int) (Preview, error) {
+	if n < 1 || n > MaxPreviewOccurrences {
+		n = MaxPreviewOccurrences
+	}
+
+	e.ID = ""
+
+	if _, err := sch.firstOccurrence(e); err != nil {
+		return Preview{}, err
+	}
+
+	// The occurrences are computed once the jitter is validated
+	e.Jitter = NoJitter
+
+	e, err := sch.firstOccurrence(e)
+
+	if err != nil {
+		return Preview{}, err
+	}
+
+	p := Preview{Occurrences: []time.Time{e.ShouldExecuteAt}}
+
+	if !e.Mode.recurring() {
+		p.Description = "once at " + e.ShouldExecuteAt.Format(time.RFC3339)
+
+		return p, nil
+	}
+
+	s, err := sch.recurrence(newEvent(e))
+
+	if err != nil {
+		return Preview{}, err
+	}
+
+	p.Description = describeEvent(e, s)
+
+	for o := e.ShouldExecuteAt; len(p.Occurrences) < n && (e.MaxOccurrences == 0 || len(p.Occurrences) < e.MaxOccurrences); {
+		o = s.Next(o)
+
+		if o.IsZero() || (!e.EndAt.IsZero() && o.After(e.EndAt)) {
+			break
+		}
+
+		p.Occurrences = append(p.Occurrences, o)
+	}
+
+	return p, nil
+}
+
+// describeEvent describes the schedule of a recurring event and its bounds
+func describeEvent(e Event, s cron.Schedule) string {
+	parts := []string{describe(s)}
+
+	if !e.StartAt.IsZero() {
+		parts = append(parts, "starting "+e.StartAt.Format(time.RFC3339))
+	}
+
+	if !e.EndAt.IsZero() {
+		parts = append(parts, "until "+e.EndAt.Format(time.RFC3339))
+	}
+
+	if e.MaxOccurrences > 0 {
+		parts = append(parts, plural(e.MaxOccurrences, "time"))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func describe(s cron.Schedule) string {
+	switch s := s.(type) {
+	case jitterSchedule:
+		return fmt.Sprintf("%s, delayed by up to %s", describe(s.Schedule), s.window)
+	case calendarSchedule:
+		verb := "skipped"
+
+		if s.policy == CalendarShift {
+			verb = "shifted"
+		}
+
+		return fmt.Sprintf("%s, %s outside the business days and hours of the calendar %q", describe(s.Schedule), verb, s.cal.def.Name)
+	case zonedSchedule:
+		return fmt.Sprintf("%s (%s)", describeSpec(s.wall), s.loc)
+	case cron.ConstantDelaySchedule:
+		return "every " + s.Delay.String()
+	case intervalSchedule:
+		return fmt.Sprintf("every %s from %s", s.interval, s.anchor.Format(time.RFC3339))
+	case rruleSchedule:
+		return describeRRuleSchedule(s)
+	default:
+		return ""
+	}
+}
+
+// describeSpec describes the fields of a cron expression
+func describeSpec(s *cron.SpecSchedule) string {
+	secs, mins, hours := bits(s.Second, 0, 59), bits(s.Minute, 0, 59), bits(s.Hour, 0, 23)
+	dom, months, dow := bits(s.Dom, 1, 31), bits(s.Month, 1, 12), bits(s.Dow, 0, 6)
+
+	clock := describeClock(secs, mins, hours)
+
+	if clock == "" {
+		clock = describeTime([][]int{secs, mins, hours}, []int{59, 59, 23}, []string{"second", "minute", "hour"})
+	}
+
+	parts := []string{clock}
+
+	// A day matches both fields if one of them is a star, either of them otherwise
+	domPart := describeDays("on", dom, "day", "month")
+	dowPart := "on " + enumerate(ranges(dow, weekdayName))
+
+	switch {
+	case s.Dom&starBit != 0 && s.Dow&starBit != 0:
+		if strings.HasPrefix(clock, "at ") && !strings.Contains(clock, "every") {
+			parts = append(parts, "every day")
+		}
+	case s.Dom&starBit != 0:
+		parts = append(parts, dowPart)
+	case s.Dow&starBit != 0:
+		parts = append(parts, domPart)
+	default:
+		parts = append(parts, domPart+" or "+dowPart)
+	}
+
+	if len(months) < 12 {
+		parts = append(parts, "in "+enumerate(ranges(months, monthName)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// describeClock lists the times of the day, e.g. at 09:00 and 18:00, if there are a few of them
+func describeClock(secs []int, mins []int, hours []int) string {
+	if len(secs) != 1 || len(mins) != 1 || len(hours) == 0 || len(hours) > 6 {
+		return ""
+	}
+
+	times := make([]string, len(hours))
+
+	for i, h := range hours {
+		times[i] = formatClock(h, mins[0], secs[0])
+	}
+
+	return "at " + enumerate(times)
+}
+
+// describeTime describes the fields from the finest to the coarsest, e.g. every 15 minutes during hours 9 to 17
+func describeTime(fields [][]int, max []int, units []string) string {
+	var parts []string
+
+	for i, vals := range fields {
+		all := len(vals) == max[i]+1
+
+		switch {
+		case i == 0 && len(vals) == 1 && vals[0] == 0:
+			// The occurrences are at the start of the minutes
+		case all && len(parts) == 0:
+			parts = append(parts, "every "+units[i])
+		case all:
+			if last := parts[len(parts)-1]; strings.HasPrefix(last, "at ") {
+				parts = append(parts, "of every "+units[i])
+			}
+		default:
+			if step := progression(vals, max[i]); step > 1 {
+				parts = append(parts, fmt.Sprintf("every %d %ss", step, units[i]))
+			} else if len(parts) == 0 {
+				parts = append(parts, fmt.Sprintf("at %s %s", pluralUnit(len(vals), units[i]), enumerate(ranges(vals, strconv.Itoa))))
+			} else {
+				parts = append(parts, fmt.Sprintf("during %s %s", pluralUnit(len(vals), units[i]), enumerate(ranges(vals, strconv.Itoa))))
+			}
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// describeRRuleSchedule describes the rules, the exclusions and the dates of a RRULE recurrence
+func describeRRuleSchedule(s rruleSchedule) string {
+	var parts []string
+
+	rules := make([]string, len(s.rules))
+
+	for i, r := range s.rules {
+		rules[i] = describeRRule(r)
+	}
+
+	if len(rules) > 0 {
+		parts = append(parts, strings.Join(rules, " and "))
+	}
+
+	for _, r := range s.exRules {
+		parts = append(parts, "except "+describeRRule(r))
+	}
+
+	if len(s.rDates) > 0 {
+		parts = append(parts, "plus "+plural(len(s.rDates), "date"))
+	}
+
+	if len(s.exDates) > 0 {
+		parts = append(parts, "except "+plural(len(s.exDates), "date"))
+	}
+
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, ", "), s.loc)
+}
+
+var frequencyUnits = map[frequency]string{
+	secondly: "second",
+	minutely: "minute",
+	hourly:   "hour",
+	daily:    "day",
+	weekly:   "week",
+	monthly:  "month",
+	yearly:   "year",
+}
+
+func describeRRule(r *rrule) string {
+	unit := frequencyUnits[r.freq]
+	parts := []string{"every " + unit}
+
+	if r.interval > 1 {
+		parts[0] = fmt.Sprintf("every %d %ss", r.interval, unit)
+	}
+
+	if len(r.byMonth) > 0 {
+		parts = append(parts, "in "+enumerate(ranges(r.byMonth, monthName)))
+	}
+
+	if len(r.byWeekNo) > 0 {
+		parts = append(parts, describeDays("in", r.byWeekNo, "week", "year"))
+	}
+
+	if len(r.byYearDay) > 0 {
+		parts = append(parts, describeDays("on", r.byYearDay, "day", "year"))
+	}
+
+	if len(r.byMonthDay) > 0 {
+		parts = append(parts, describeDays("on", r.byMonthDay, "day", "month"))
+	}
+
+	if len(r.byDay) > 0 {
+		days := make([]string, len(r.byDay))
+		every := make([]int, 0, len(r.byDay))
+
+		for i, d := range r.byDay {
+			days[i] = d.day.String()
+
+			if d.n != 0 {
+				days[i] = "the " + ordinal(d.n) + " " + days[i]
+			} else {
+				every = append(every, int(d.day))
+			}
+		}
+
+		// The days of the week are written as ranges unless some of them are the n-th of the period
+		if len(every) == len(days) {
+			days = ranges(every, weekdayName)
+		}
+
+		parts = append(parts, "on "+enumerate(days))
+	}
+
+	clock := describeClock(r.bySecond, r.byMinute, r.byHour)
+
+	if clock == "" {
+		var fields [][]int
+		var max []int
+		var units []string
+
+		for i, f := range [][]int{r.bySecond, r.byMinute, r.byHour} {
+			if len(f) > 0 {
+				fields = append(fields, f)
+				max = append(max, []int{59, 59, 23}[i])
+				units = append(units, []string{"second", "minute", "hour"}[i])
+			}
+		}
+
+		clock = describeTime(fields, max, units)
+	}
+
+	if clock != "" {
+		parts = append(parts, clock)
+	}
+
+	if len(r.bySetPos) > 0 {
+		pos := make([]string, len(r.bySetPos))
+
+		for i, p := range r.bySetPos {
+			pos[i] = ordinal(p)
+		}
+
+		parts = append(parts, fmt.Sprintf("keeping the %s of each %s", enumerate(pos), unit))
+	}
+
+	desc := strings.Join(parts, " ")
+
+	if r.count > 0 {
+		desc += ", " + plural(r.count, "time")
+	}
+
+	if !r.until.IsZero() {
+		desc += ", until " + r.until.Format("2006-01-02 15:04:05")
+	}
+
+	return desc
+}
+
+// describeDays describes the positions of the days or the weeks in their period, e.g. on the 1st and last days of the month
+func describeDays(prep string, vals []int, unit string, period string) string {
+	return fmt.Sprintf("%s the %s %s of the %s", prep, enumerate(ranges(vals, ordinal)), pluralUnit(len(vals), unit), period)
+}
+
+// bits lists the values of a field of a cron expression
+func bits(b uint64, min int, max int) []int {
+	var vals []int
+
+	for v := min; v <= max; v++ {
+		if b&(1<<uint(v)) != 0 {
+			vals = append(vals, v)
+		}
+	}
+
+	return vals
+}
+
+// progression returns the step of the values if they are every step-th value from 0, 0 otherwise
+func progression(vals []int, max int) int {
+	if len(vals) < 2 || vals[0] != 0 {
+		return 0
+	}
+
+	step := vals[1] - vals[0]
+
+	for i, v := range vals {
+		if v != i*step {
+			return 0
+		}
+	}
+
+	if vals[len(vals)-1]+step <= max {
+		return 0
+	}
+
+	return step
+}
+
+// ranges formats the values, the runs of three values or more are written as ranges, e.g. Monday to Friday
+func ranges(vals []int, name func(int) string) []string {
+	var out []string
+
+	for i := 0; i < len(vals); {
+		j := i
+
+		for j+1 < len(vals) && vals[j+1] == vals[j]+1 {
+			j++
+		}
+
+		if j-i >= 2 {
+			out = append(out, name(vals[i])+" to "+name(vals[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				out = append(out, name(vals[k]))
+			}
+		}
+
+		i = j + 1
+	}
+
+	return out
+}
+
+// enumerate joins the items, e.g. a, b and c
+func enumerate(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func plural(n int, unit string) string {
+	return fmt.Sprintf("%d %s", n, pluralUnit(n, unit))
+}
+
+func pluralUnit(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+
+	return unit + "s"
+}
+
+// ordinal returns e.g. 2nd, or 2nd to last for -2
+func ordinal(n int) string {
+	if n == -1 {
+		return "last"
+	}
+
+	if n < 0 {
+		return ordinal(-n) + " to last"
+	}
+
+	suffix := "th"
+
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return strconv.Itoa(n) + suffix
+}
+
+func formatClock(h int, m int, s int) string {
+	if s != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+func weekdayName(d int) string {
+	return time.Weekday(d).String()
+}
+
+func monthName(m int) string {
+	return time.Month(m).String()
+}
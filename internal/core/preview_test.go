@@ -0,0 +1,101 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler_Preview(t *testing.T) {
+	sch := newTestScheduler()
+
+	startAt := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		event       Event
+		description string
+		first       string
+	}{
+		{
+			Event{Mode: CronMode, CronExpression: "0 8 * * MON-FRI", TimeZone: "Europe/Paris"},
+			"at 08:00 on Monday to Friday (Europe/Paris)", "",
+		},
+		{
+			Event{Mode: CronMode, CronExpression: "0 0 8,12,18 1,15 JAN,JUL *", TimeZone: "UTC"},
+			"at 08:00, 12:00 and 18:00 on the 1st and 15th days of the month in January and July (UTC)", "",
+		},
+		{
+			Event{Mode: CronMode, CronExpression: "*/15 9-17 * * *", TimeZone: "UTC"},
+			"every 15 minutes during hours 9 to 17 (UTC)", "",
+		},
+		{
+			Event{Mode: CronMode, CronExpression: "30 * * * * *", TimeZone: "UTC"},
+			"at second 30 of every minute (UTC)", "",
+		},
+		{
+			Event{Mode: CronMode, CronExpression: "@every 90m"},
+			"every 1h30m0s", "",
+		},
+		{
+			Event{Mode: IntervalMode, Interval: 6 * time.Hour, StartAt: startAt, MaxOccurrences: 3},
+			"every 6h0m0s from " + startAt.Format(time.RFC3339) + ", starting " + startAt.Format(time.RFC3339) + ", 3 times", startAt.Format(time.RFC3339),
+		},
+		{
+			Event{Mode: RRuleMode, RRule: "DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR\nEXDATE:20261225T090000", TimeZone: "UTC"},
+			"every 2 months on the last Friday at 09:00, except 1 date (UTC)", "",
+		},
+		{
+			Event{Mode: RRuleMode, RRule: "DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=120", TimeZone: "UTC"},
+			"every month on Monday to Friday at 09:00 keeping the last of each month, 120 times (UTC)", "",
+		},
+	} {
+		p, err := sch.Preview(tc.event, 5)
+
+		if err != nil {
+			t.Fatalf("%s: %v\n", tc.description, err)
+		}
+
+		if p.Description != tc.description {
+			t.Fatalf("Wrong description: expected:%s, got:%s\n", tc.description, p.Description)
+		}
+
+		if tc.first != "" && !p.Occurrences[0].Equal(utc(tc.first)) {
+			t.Fatalf("Wrong first occurrence: expected:%s, got:%s\n", tc.first, p.Occurrences[0])
+		}
+
+		max := 5
+
+		if tc.event.MaxOccurrences > 0 {
+			max = tc.event.MaxOccurrences
+		}
+
+		if len(p.Occurrences) != max {
+			t.Fatalf("%s: wrong number of occurrences: expected:%d, got:%d\n", tc.description, max, len(p.Occurrences))
+		}
+
+		for i := 1; i < len(p.Occurrences); i++ {
+			if !p.Occurrences[i].After(p.Occurrences[i-1]) {
+				t.Fatalf("%s: the occurrences must be sorted: %v\n", tc.description, p.Occurrences)
+			}
+		}
+	}
+
+	if _, err := sch.Preview(Event{Mode: CronMode, CronExpression: "0 8 * * MON-FRY"}, 5); err == nil {
+		t.Fatalf("An invalid expression must be rejected\n")
+	}
+
+	if _, err := sch.Preview(Event{Mode: CronMode, CronExpression: "0 8 * * *", TimeZone: "Mars/Olympus"}, 5); err == nil {
+		t.Fatalf("An unknown time zone must be rejected\n")
+	}
+
+	if _, err := sch.Preview(Event{Mode: CronMode, CronExpression: "0 8 * * *", Jitter: -time.Minute}, 5); err != ErrInvalidJitter {
+		t.Fatalf("A negative jitter must be rejected: got %v\n", err)
+	}
+
+	if _, err := sch.Preview(Event{Mode: CronMode, CronExpression: "0 8 * * *", Labels: map[string]string{"not a key": ""}}, 5); err == nil {
+		t.Fatalf("Invalid labels must be rejected\n")
+	}
+
+	if sch.queue.Pop() != nil {
+		t.Fatalf("A preview must not schedule the event\n")
+	}
+}
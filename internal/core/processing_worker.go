@@ -54,23 +54,40 @@ func (p *_processingWorker) Stop() {
 
 func (p *_processingWorker) process() {
 	p.stack.Lock()
-	defer p.stack.Unlock()
 
 	if p.stack.len == 0 {
+		p.stack.Unlock()
 		return
 	}
 
-	e := p.stack.Get(0)
-
 	now := time.Now()
 
-	if e.ShouldExecuteAt.Before(now) || e.ShouldExecuteAt.Equal(now) {
-		p.stack.Pop()
+	if p.stack.Get(0).ShouldExecuteAt.After(now) {
+		p.stack.Unlock()
+		return
+	}
+
+	// The stack is released before dispatching, as rescheduling may push into this very stack
+	e := p.stack.Pop()
+	p.stack.Unlock()
 
-		p.dispatch.Dispatch(*e.event)
+	e, fire := p.sch.misfire(e, now)
 
-		if e.Mode == CronMode {
-			p.sch.schedule(*e.event)
-		}
+	if !fire {
+		p.sch.skip(e)
+		return
+	}
+
+	// An event whose next occurrence cannot be computed is deleted once dispatched
+	if next, err := p.sch.next(e); err == nil {
+		e.Next = next
+	}
+
+	p.dispatch.Dispatch(e)
+
+	if !e.Next.IsZero() {
+		e.FireCount++
+		e.LastFiredAt = now
+		p.sch.schedule(e)
 	}
 }
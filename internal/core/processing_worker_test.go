@@ -25,19 +25,45 @@ func (d *_dispatcherMock) Stop() {
 
 }
 
+func (d *_dispatcherMock) SetConfig(config DispatchManagerConfig) {
+}
+
 func (s *_schedulerMock) schedule(e event) {
 	s.count++
 }
 
+func (s *_schedulerMock) next(e event) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (s *_schedulerMock) misfire(e event, now time.Time) (event, bool) {
+	return e, true
+}
+
+func (s *_schedulerMock) skip(e event) {
+}
+
 func (s *_schedulerMock) Schedule(e Event) (ID, error) {
 	s.count++
 	return "", nil
 }
 
+func (s *_schedulerMock) Preview(e Event, n int) (Preview, error) {
+	return Preview{}, nil
+}
+
 func (s *_schedulerMock) Unschedule(id ID) error {
 	return nil
 }
 
+func (s *_schedulerMock) Get(id ID) (Event, error) {
+	return Event{}, ErrNotFound
+}
+
+func (s *_schedulerMock) List() ([]Event, error) {
+	return nil, nil
+}
+
 func (s *_schedulerMock) Start() error {
 	return nil
 }
@@ -49,6 +75,30 @@ func (s *_schedulerMock) SetConfig(conf SchedulerConfig) error {
 	return nil
 }
 
+func (s *_schedulerMock) Config() SchedulerConfig {
+	return SchedulerConfig{}
+}
+
+func (s *_schedulerMock) SetCalendar(c Calendar) error {
+	return nil
+}
+
+func (s *_schedulerMock) DeleteCalendar(name string) error {
+	return nil
+}
+
+func (s *_schedulerMock) Calendars() []Calendar {
+	return nil
+}
+
+func (s *_schedulerMock) ReloadCalendars() error {
+	return nil
+}
+
+func (s *_schedulerMock) ConfigureCalendars(cals []Calendar) error {
+	return nil
+}
+
 func TestProcessingWorker(t *testing.T) {
 	var config = StackManagerConfig{
 		StacksNumber:         1,
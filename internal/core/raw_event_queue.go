@@ -94,6 +94,20 @@ func (s *rawEventQueue) resize(cap int) {
 	}
 }
 
+// setCapacity updates the default and max capacities, the max capacity never goes below the current one
+func (s *rawEventQueue) setCapacity(defaultCap int, maxCap int) {
+	if maxCap < s.cap {
+		maxCap = s.cap
+	}
+
+	if defaultCap > maxCap {
+		defaultCap = maxCap
+	}
+
+	s.maxCap = maxCap
+	s.defaultCap = defaultCap
+}
+
 func (s *rawEventQueue) Get(i int) *rawEventQueueNode {
 
 	node := s.start
@@ -0,0 +1,894 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoDTStart = errors.New("the recurrence rule has no DTSTART nor anchor")
+
+const (
+	// maxRRuleYear ends the rules which never match again
+	maxRRuleYear = 9999
+
+	// maxEmptyPeriods ends the rules whose periods stop matching, e.g. a BYSETPOS out of range
+	maxEmptyPeriods = 1 << 20
+
+	// maxExcluded ends the sets whose next occurrences are all excluded
+	maxExcluded = 10000
+
+	// maxRRuleCount bounds COUNT, the occurrences preceding the next one are enumerated once per schedule
+	// to be counted. The longer series end with UNTIL
+	maxRRuleCount = 100000
+)
+
+// frequency of a rule, from the shortest to the longest
+type frequency int
+
+const (
+	secondly frequency = iota
+	minutely
+	hourly
+	daily
+	weekly
+	monthly
+	yearly
+)
+
+var frequencies = map[string]frequency{
+	"SECONDLY": secondly,
+	"MINUTELY": minutely,
+	"HOURLY":   hourly,
+	"DAILY":    daily,
+	"WEEKLY":   weekly,
+	"MONTHLY":  monthly,
+	"YEARLY":   yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// nthWeekday is a BYDAY value, e.g. -1FR is the last Friday of the month or the year, n is 0 for every Friday
+type nthWeekday struct {
+	n   int
+	day time.Weekday
+}
+
+// rrule is a RRULE or an EXRULE, its times are wall clock times represented in UTC like the ones of zonedSchedule
+type rrule struct {
+	freq       frequency
+	interval   int
+	count      int
+	until      time.Time
+	bySecond   []int
+	byMinute   []int
+	byHour     []int
+	byDay      []nthWeekday
+	byMonthDay []int
+	byYearDay  []int
+	byWeekNo   []int
+	byMonth    []int
+	bySetPos   []int
+	wkst       time.Weekday
+	dtstart    time.Time
+
+	// cursor is the last occurrence returned by a rule with a COUNT, the following ones are counted from it
+	mu     sync.Mutex
+	cursor rruleCursor
+}
+
+// rruleCursor is an occurrence of a rule along with its period and the number of occurrences preceding the period
+type rruleCursor struct {
+	at     time.Time
+	period int
+	count  int
+}
+
+// rruleSchedule is the recurrence set of RFC 5545: the occurrences of the RRULEs and the RDATEs
+// which are neither occurrences of the EXRULEs nor EXDATEs
+type rruleSchedule struct {
+	loc     *time.Location
+	rules   []*rrule
+	exRules []*rrule
+	rDates  []time.Time
+	exDates []time.Time
+}
+
+// parseRRule parses the RRULE, EXRULE, RDATE and EXDATE lines of RFC 5545 in the time zone of the event,
+// a single RRULE may omit its RRULE: name. The rules start at the DTSTART line if any, at the anchor otherwise.
+//
+// The rules are evaluated on the wall clock of the zone, the DST transitions are handled like the ones of the
+// cron expressions. DTSTART is an occurrence only if it matches the rules, and the RDATEs and EXDATEs of type
+// DATE occur at the time of DTSTART.
+func parseRRule(text string, timeZone string, anchor time.Time) (cron.Schedule, error) {
+	loc, err := LoadTimeZone(timeZone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The long lines may be folded
+	text = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(text)
+
+	var props []property
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		p, err := parseProperty(line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		props = append(props, p)
+	}
+
+	var dtstart time.Time
+
+	for _, p := range props {
+		if p.name != "DTSTART" {
+			continue
+		}
+
+		if !dtstart.IsZero() {
+			return nil, errors.New("the recurrence has several DTSTART")
+		}
+
+		if tzid, ok := p.params["TZID"]; ok {
+			zone, err := LoadTimeZone(tzid)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if timeZone != "" && zone.String() != loc.String() {
+				return nil, ErrConflictingTimeZones
+			}
+
+			loc = zone
+		}
+
+		if dtstart, _, err = parseDateTime(p.value, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	if dtstart.IsZero() {
+		if anchor.IsZero() {
+			return nil, ErrNoDTStart
+		}
+
+		dtstart = wallClock(anchor, loc)
+	}
+
+	// RFC 5545 times have no fraction of second
+	dtstart = dtstart.Truncate(time.Second)
+
+	s := rruleSchedule{loc: loc}
+
+	for _, p := range props {
+		switch p.name {
+		case "DTSTART":
+		case "RRULE", "EXRULE":
+			r, err := parseRule(p.value, dtstart, loc)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if p.name == "RRULE" {
+				s.rules = append(s.rules, r)
+			} else {
+				s.exRules = append(s.exRules, r)
+			}
+		case "RDATE", "EXDATE":
+			dates, err := parseDates(p, dtstart, loc)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if p.name == "RDATE" {
+				s.rDates = append(s.rDates, dates...)
+			} else {
+				s.exDates = append(s.exDates, dates...)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence property %q", p.name)
+		}
+	}
+
+	if len(s.rules) == 0 && len(s.rDates) == 0 {
+		return nil, errors.New("the recurrence has no RRULE nor RDATE")
+	}
+
+	sort.Slice(s.rDates, func(i, j int) bool { return s.rDates[i].Before(s.rDates[j]) })
+
+	return s, nil
+}
+
+func (s rruleSchedule) Next(t time.Time) time.Time {
+	w := s.next(wallClock(t, s.loc))
+
+	if w.IsZero() {
+		return w
+	}
+
+	return zonedInstant(w, t, s.loc)
+}
+
+// next returns the first occurrence of the set following the wall clock time w, or the zero time
+func (s rruleSchedule) next(w time.Time) time.Time {
+	for i := 0; i < maxExcluded; i++ {
+		var next time.Time
+
+		for _, r := range s.rules {
+			if o := r.next(w); !o.IsZero() && (next.IsZero() || o.Before(next)) {
+				next = o
+			}
+		}
+
+		for _, d := range s.rDates {
+			if d.After(w) {
+				if next.IsZero() || d.Before(next) {
+					next = d
+				}
+
+				break
+			}
+		}
+
+		if next.IsZero() || !s.excluded(next) {
+			return next
+		}
+
+		w = next
+	}
+
+	return time.Time{}
+}
+
+func (s rruleSchedule) excluded(w time.Time) bool {
+	for _, d := range s.exDates {
+		if d.Equal(w) {
+			return true
+		}
+	}
+
+	for _, r := range s.exRules {
+		if r.next(w.Add(-time.Nanosecond)).Equal(w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// property is a content line of RFC 5545, e.g. EXDATE;TZID=Europe/Paris:20260310T090000
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseProperty(line string) (property, error) {
+	i := strings.Index(line, ":")
+
+	if i < 0 {
+		if strings.HasPrefix(strings.ToUpper(line), "FREQ=") {
+			return property{name: "RRULE", value: line}, nil
+		}
+
+		return property{}, fmt.Errorf("invalid recurrence line %q", line)
+	}
+
+	parts := strings.Split(line[:i], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: make(map[string]string, len(parts)-1), value: line[i+1:]}
+
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+
+		if len(kv) != 2 {
+			return p, fmt.Errorf("invalid parameter %q of the recurrence line %q", param, line)
+		}
+
+		p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+
+	return p, nil
+}
+
+// parseDateTime parses a DATE or DATE-TIME value as a wall clock time of the zone, the UTC values are converted
+func parseDateTime(v string, loc *time.Location) (time.Time, bool, error) {
+	if len(v) == len("20060102") {
+		t, err := time.Parse("20060102", v)
+
+		return t, true, err
+	}
+
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+
+		return wallClock(t, loc), false, err
+	}
+
+	t, err := time.Parse("20060102T150405", v)
+
+	return t, false, err
+}
+
+// parseDates parses the value of a RDATE or an EXDATE, the DATE values occur at the time of dtstart
+func parseDates(p property, dtstart time.Time, loc *time.Location) ([]time.Time, error) {
+	if v, ok := p.params["VALUE"]; ok && v != "DATE" && v != "DATE-TIME" {
+		return nil, fmt.Errorf("unsupported %s value type %q", p.name, v)
+	}
+
+	zone := loc
+
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+
+		if zone, err = LoadTimeZone(tzid); err != nil {
+			return nil, err
+		}
+	}
+
+	var dates []time.Time
+
+	for _, v := range strings.Split(p.value, ",") {
+		d, date, err := parseDateTime(v, zone)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", p.name, v, err)
+		}
+
+		if date {
+			d = d.Add(dtstart.Sub(truncateDay(dtstart)))
+		}
+
+		// The dates of another zone are converted to the wall clock of the event
+		if zone != loc {
+			d = wallClock(zonedInstant(d, time.Time{}, zone), loc)
+		}
+
+		dates = append(dates, d)
+	}
+
+	return dates, nil
+}
+
+// parseRule parses the value of a RRULE or an EXRULE, e.g. FREQ=MONTHLY;BYDAY=2TU;UNTIL=20261231T000000Z
+func parseRule(v string, dtstart time.Time, loc *time.Location) (*rrule, error) {
+	r := &rrule{freq: -1, interval: 1, wkst: time.Monday, dtstart: dtstart}
+
+	for _, part := range strings.Split(v, ";") {
+		kv := strings.SplitN(part, "=", 2)
+
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+
+		switch name, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1]); name {
+		case "FREQ":
+			f, ok := frequencies[value]
+
+			if !ok {
+				err = errors.New("unknown frequency")
+			}
+
+			r.freq = f
+		case "INTERVAL":
+			r.interval, err = parseInt(value, 1, 1<<16)
+		case "COUNT":
+			r.count, err = parseInt(value, 1, maxRRuleCount)
+		case "UNTIL":
+			var date bool
+
+			r.until, date, err = parseDateTime(value, loc)
+
+			// The occurrences of the last day are included
+			if date {
+				r.until = r.until.Add(24*time.Hour - time.Second)
+			}
+		case "BYSECOND":
+			r.bySecond, err = parseInts(value, 0, 59, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseInts(value, 0, 59, false)
+		case "BYHOUR":
+			r.byHour, err = parseInts(value, 0, 23, false)
+		case "BYDAY":
+			r.byDay, err = parseWeekdays(value)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(value, 1, 31, true)
+		case "BYYEARDAY":
+			r.byYearDay, err = parseInts(value, 1, 366, true)
+		case "BYWEEKNO":
+			r.byWeekNo, err = parseInts(value, 1, 53, true)
+		case "BYMONTH":
+			r.byMonth, err = parseInts(value, 1, 12, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseInts(value, 1, 366, true)
+		case "WKST":
+			d, ok := weekdays[value]
+
+			if !ok {
+				err = errors.New("unknown weekday")
+			}
+
+			r.wkst = d
+		default:
+			err = errors.New("unsupported rule part")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule part %q: %v", part, err)
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rule %q: %v", v, err)
+	}
+
+	// The parts which are not given are the ones of DTSTART
+	if len(r.byWeekNo) == 0 && len(r.byYearDay) == 0 && len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		switch r.freq {
+		case yearly:
+			if len(r.byMonth) == 0 {
+				r.byMonth = []int{int(dtstart.Month())}
+			}
+
+			r.byMonthDay = []int{dtstart.Day()}
+		case monthly:
+			r.byMonthDay = []int{dtstart.Day()}
+		case weekly:
+			r.byDay = []nthWeekday{{day: dtstart.Weekday()}}
+		}
+	}
+
+	if r.freq > hourly && len(r.byHour) == 0 {
+		r.byHour = []int{dtstart.Hour()}
+	}
+
+	if r.freq > minutely && len(r.byMinute) == 0 {
+		r.byMinute = []int{dtstart.Minute()}
+	}
+
+	if r.freq > secondly && len(r.bySecond) == 0 {
+		r.bySecond = []int{dtstart.Second()}
+	}
+
+	return r, nil
+}
+
+// validate checks the combinations of rule parts forbidden by RFC 5545
+func (r *rrule) validate() error {
+	switch {
+	case r.freq < 0:
+		return errors.New("FREQ is required")
+	case r.count > 0 && !r.until.IsZero():
+		return errors.New("COUNT and UNTIL are exclusive")
+	case len(r.byWeekNo) > 0 && r.freq != yearly:
+		return errors.New("BYWEEKNO is only allowed with FREQ=YEARLY")
+	case len(r.byYearDay) > 0 && (r.freq == daily || r.freq == weekly || r.freq == monthly):
+		return errors.New("BYYEARDAY is not allowed with FREQ=DAILY, WEEKLY or MONTHLY")
+	case len(r.byMonthDay) > 0 && r.freq == weekly:
+		return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	case len(r.bySetPos) > 0 && len(r.bySecond)+len(r.byMinute)+len(r.byHour)+len(r.byDay)+len(r.byMonthDay)+len(r.byYearDay)+len(r.byWeekNo)+len(r.byMonth) == 0:
+		return errors.New("BYSETPOS requires another BYxxx part")
+	}
+
+	for _, d := range r.byDay {
+		if d.n != 0 && (r.freq < monthly || len(r.byWeekNo) > 0) {
+			return errors.New("the ordinal weekdays are only allowed with FREQ=MONTHLY or FREQ=YEARLY without BYWEEKNO")
+		}
+	}
+
+	return nil
+}
+
+func parseInt(v string, min int, max int) (int, error) {
+	i, err := strconv.Atoi(v)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if i < min || i > max {
+		return 0, fmt.Errorf("%d is not between %d and %d", i, min, max)
+	}
+
+	return i, nil
+}
+
+// parseInts parses a list of integers between min and max, or between -max and -min if negative is true
+func parseInts(v string, min int, max int, negative bool) ([]int, error) {
+	var out []int
+
+	for _, s := range strings.Split(v, ",") {
+		abs := strings.TrimPrefix(strings.TrimPrefix(s, "+"), "-")
+
+		if !negative && abs != strings.TrimPrefix(s, "+") {
+			return nil, fmt.Errorf("%s must not be negative", s)
+		}
+
+		i, err := parseInt(abs, min, max)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(s, "-") {
+			i = -i
+		}
+
+		out = append(out, i)
+	}
+
+	sort.Ints(out)
+
+	return out, nil
+}
+
+// parseWeekdays parses a BYDAY list, e.g. MO,WE or 2TU,-1FR
+func parseWeekdays(v string) ([]nthWeekday, error) {
+	var out []nthWeekday
+
+	for _, s := range strings.Split(v, ",") {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", s)
+		}
+
+		d, ok := weekdays[s[len(s)-2:]]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", s)
+		}
+
+		w := nthWeekday{day: d}
+
+		if n := s[:len(s)-2]; n != "" {
+			ns, err := parseInts(n, 1, 53, true)
+
+			if err != nil {
+				return nil, err
+			}
+
+			w.n = ns[0]
+		}
+
+		out = append(out, w)
+	}
+
+	return out, nil
+}
+
+// next returns the first occurrence of the rule following the wall clock time w, or the zero time
+func (r *rrule) next(w time.Time) time.Time {
+	k, count, empty := 0, 0, 0
+
+	// The occurrences preceding w only need to be enumerated to be counted, from the last one returned if it precedes w
+	if r.count == 0 {
+		k = r.period(w)
+	} else {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if c := r.cursor; !c.at.IsZero() && !c.at.After(w) {
+			k, count = c.period, c.count
+		}
+	}
+
+	for empty < maxEmptyPeriods {
+		start := r.periodStart(k)
+
+		if start.Year() > maxRRuleYear || (!r.until.IsZero() && start.After(r.until)) {
+			return time.Time{}
+		}
+
+		// The days of the shorter periods are skipped at once
+		if r.freq < daily && !r.matchDay(truncateDay(start)) {
+			k = r.periodAfter(truncateDay(start).AddDate(0, 0, 1))
+			empty++
+			continue
+		}
+
+		occurrences := r.occurrences(k)
+
+		if len(occurrences) == 0 {
+			empty++
+		} else {
+			empty = 0
+		}
+
+		// The number of occurrences preceding the period
+		before := count
+
+		for _, o := range occurrences {
+			if o.Before(r.dtstart) {
+				continue
+			}
+
+			if !r.until.IsZero() && o.After(r.until) {
+				return time.Time{}
+			}
+
+			if count++; r.count > 0 && count > r.count {
+				return time.Time{}
+			}
+
+			if o.After(w) {
+				if r.count > 0 {
+					r.cursor = rruleCursor{at: o, period: k, count: before}
+				}
+
+				return o
+			}
+		}
+
+		k++
+	}
+
+	return time.Time{}
+}
+
+// periodStart returns the start of the k-th period of the rule, the first one contains DTSTART
+func (r *rrule) periodStart(k int) time.Time {
+	s, n := r.dtstart, k*r.interval
+
+	switch r.freq {
+	case yearly:
+		return time.Date(s.Year()+n, time.January, 1, 0, 0, 0, 0, time.UTC)
+	case monthly:
+		return time.Date(s.Year(), s.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	case weekly:
+		return weekStart(s, r.wkst).AddDate(0, 0, 7*n)
+	case daily:
+		return time.Date(s.Year(), s.Month(), s.Day()+n, 0, 0, 0, 0, time.UTC)
+	case hourly:
+		return time.Date(s.Year(), s.Month(), s.Day(), s.Hour()+n, 0, 0, 0, time.UTC)
+	case minutely:
+		return time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute()+n, 0, 0, time.UTC)
+	}
+
+	return time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute(), s.Second()+n, 0, time.UTC)
+}
+
+// period returns the index of a period starting before w, the periods before it end before w
+func (r *rrule) period(w time.Time) int {
+	var units int
+
+	switch r.freq {
+	case yearly:
+		units = w.Year() - r.dtstart.Year()
+	case monthly:
+		units = (w.Year()-r.dtstart.Year())*12 + int(w.Month()-r.dtstart.Month())
+	case weekly:
+		units = int(weekStart(w, r.wkst).Sub(weekStart(r.dtstart, r.wkst)).Hours()) / (7 * 24)
+	default:
+		units = int((w.Unix() - r.periodStart(0).Unix()) / r.seconds())
+	}
+
+	// The year of weeks of a BYWEEKNO rule may start in December, so the previous period is enumerated as well
+	if k := units/r.interval - 1; k > 0 {
+		return k
+	}
+
+	return 0
+}
+
+// periodAfter returns the index of the first period of a rule shorter than a day starting at or after w
+func (r *rrule) periodAfter(w time.Time) int {
+	step := r.seconds() * int64(r.interval)
+
+	return int((w.Unix() - r.periodStart(0).Unix() + step - 1) / step)
+}
+
+// seconds returns the length in seconds of the periods of the rules shorter than a week
+func (r *rrule) seconds() int64 {
+	switch r.freq {
+	case daily:
+		return 24 * 60 * 60
+	case hourly:
+		return 60 * 60
+	case minutely:
+		return 60
+	}
+
+	return 1
+}
+
+// occurrences returns the sorted occurrences of the k-th period
+func (r *rrule) occurrences(k int) []time.Time {
+	start := r.periodStart(k)
+	from, to := truncateDay(start), truncateDay(start).AddDate(0, 0, 1)
+
+	switch r.freq {
+	case yearly:
+		from, to = start, start.AddDate(1, 0, 0)
+
+		if len(r.byWeekNo) > 0 {
+			from, to = weekOne(start.Year(), r.wkst), weekOne(start.Year()+1, r.wkst)
+		}
+	case monthly:
+		from, to = start, start.AddDate(0, 1, 0)
+	case weekly:
+		from, to = start, start.AddDate(0, 0, 7)
+	}
+
+	hours, minutes, seconds := r.byHour, r.byMinute, r.bySecond
+
+	if r.freq <= hourly {
+		hours = limit(start.Hour(), r.byHour)
+	}
+
+	if r.freq <= minutely {
+		minutes = limit(start.Minute(), r.byMinute)
+	}
+
+	if r.freq == secondly {
+		seconds = limit(start.Second(), r.bySecond)
+	}
+
+	var out []time.Time
+
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if !r.matchDay(d) {
+			continue
+		}
+
+		for _, h := range hours {
+			for _, m := range minutes {
+				for _, s := range seconds {
+					out = append(out, time.Date(d.Year(), d.Month(), d.Day(), h, m, s, 0, time.UTC))
+				}
+			}
+		}
+	}
+
+	if len(r.bySetPos) == 0 {
+		return out
+	}
+
+	var set []time.Time
+
+	for i := range out {
+		for _, pos := range r.bySetPos {
+			if pos == i+1 || pos == i-len(out) {
+				set = append(set, out[i])
+				break
+			}
+		}
+	}
+
+	return set
+}
+
+// matchDay tells whether the day is allowed by the BYxxx parts of the rule
+func (r *rrule) matchDay(d time.Time) bool {
+	if len(r.byMonth) > 0 && !contains(r.byMonth, int(d.Month())) {
+		return false
+	}
+
+	if len(r.byWeekNo) > 0 {
+		no, weeks := weekNumber(d, r.wkst)
+
+		if !contains(r.byWeekNo, no) && !contains(r.byWeekNo, no-weeks-1) {
+			return false
+		}
+	}
+
+	yearDays := daysIn(d.Year(), 0)
+
+	if len(r.byYearDay) > 0 && !contains(r.byYearDay, d.YearDay()) && !contains(r.byYearDay, d.YearDay()-yearDays-1) {
+		return false
+	}
+
+	monthDays := daysIn(d.Year(), d.Month())
+
+	if len(r.byMonthDay) > 0 && !contains(r.byMonthDay, d.Day()) && !contains(r.byMonthDay, d.Day()-monthDays-1) {
+		return false
+	}
+
+	if len(r.byDay) == 0 {
+		return true
+	}
+
+	// The ordinal weekdays are counted in the month or in the year
+	day, days := d.Day(), monthDays
+
+	if r.freq == yearly && len(r.byMonth) == 0 {
+		day, days = d.YearDay(), yearDays
+	}
+
+	for _, w := range r.byDay {
+		if w.day != d.Weekday() {
+			continue
+		}
+
+		if w.n == 0 || w.n == (day-1)/7+1 || w.n == -((days-day)/7+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limit returns the value if it is allowed by the BYxxx part
+func limit(v int, by []int) []int {
+	if len(by) > 0 && !contains(by, v) {
+		return nil
+	}
+
+	return []int{v}
+}
+
+func contains(values []int, v int) bool {
+	for _, i := range values {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn returns the number of days of the month, or of the year if month is 0
+func daysIn(year int, month time.Month) int {
+	if month == 0 {
+		return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// weekStart returns the first day of the week of t
+func weekStart(t time.Time, wkst time.Weekday) time.Time {
+	d := truncateDay(t)
+
+	return d.AddDate(0, 0, -int((d.Weekday()-wkst+7)%7))
+}
+
+// weekOne returns the first day of the first week of the year, which is the one containing at least 4 days of the year
+func weekOne(year int, wkst time.Weekday) time.Time {
+	return weekStart(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC), wkst)
+}
+
+// weekNumber returns the number of the week of d and the number of weeks of its year
+func weekNumber(d time.Time, wkst time.Weekday) (int, int) {
+	year := d.Year()
+
+	if next := weekOne(year+1, wkst); !d.Before(next) {
+		year++
+	} else if d.Before(weekOne(year, wkst)) {
+		year--
+	}
+
+	first := weekOne(year, wkst)
+	weeks := int(weekOne(year+1, wkst).Sub(first).Hours()) / (7 * 24)
+
+	return int(d.Sub(first).Hours())/(7*24) + 1, weeks
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		text     string
+		timeZone string
+		from     string
+		expected []string
+	}{
+		{
+			"second tuesday until december",
+			"DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=2TU;UNTIL=20261201",
+			"UTC", "2026-01-01T00:00:00Z",
+			[]string{"2026-01-13T09:00:00Z", "2026-02-10T09:00:00Z", "2026-03-10T09:00:00Z"},
+		},
+		{
+			"last weekday of the month",
+			"DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			"UTC", "2026-01-01T00:00:00Z",
+			[]string{"2026-01-30T09:00:00Z", "2026-02-27T09:00:00Z", "2026-03-31T09:00:00Z", "2026-04-30T09:00:00Z", "2026-05-29T09:00:00Z"},
+		},
+		{
+			"count",
+			"DTSTART:20260101T090000Z\nRRULE:FREQ=DAILY;COUNT=2",
+			"UTC", "2026-01-01T00:00:00Z",
+			[]string{"2026-01-01T09:00:00Z", "2026-01-02T09:00:00Z", ""},
+		},
+		{
+			"dates",
+			"DTSTART:20260101T090000\nRRULE:FREQ=DAILY\nEXDATE:20260102T090000,20260103\nRDATE:20260102T120000",
+			"UTC", "2026-01-01T10:00:00Z",
+			[]string{"2026-01-02T12:00:00Z", "2026-01-04T09:00:00Z"},
+		},
+		{
+			"weekdays only",
+			"DTSTART:20260102T090000\nRRULE:FREQ=DAILY\nEXRULE:FREQ=WEEKLY;BYDAY=SA,SU",
+			"UTC", "2026-01-02T10:00:00Z",
+			[]string{"2026-01-05T09:00:00Z", "2026-01-06T09:00:00Z"},
+		},
+		{
+			"week number",
+			"DTSTART:20250101T080000\nRRULE:FREQ=YEARLY;BYWEEKNO=1;BYDAY=MO",
+			"UTC", "2025-01-01T00:00:00Z",
+			[]string{"2025-12-29T08:00:00Z", "2027-01-04T08:00:00Z"},
+		},
+		{
+			"hours of a weekday",
+			"DTSTART:20260105T000000\nRRULE:FREQ=HOURLY;INTERVAL=10;BYDAY=MO",
+			"UTC", "2026-01-05T12:00:00Z",
+			[]string{"2026-01-05T20:00:00Z", "2026-01-12T02:00:00Z", "2026-01-12T12:00:00Z"},
+		},
+		{
+			"leap day",
+			"DTSTART:20250101T000000\nFREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			"UTC", "2026-01-01T00:00:00Z",
+			[]string{"2028-02-29T00:00:00Z", "2032-02-29T00:00:00Z"},
+		},
+
+		// The wall clock is kept across the DST transitions of New York on March 8th 2026
+		{
+			"time zone",
+			"DTSTART;TZID=America/New_York:20260307T093000\nRRULE:FREQ=DAILY",
+			"", "2026-03-07T00:00:00Z",
+			[]string{"2026-03-07T14:30:00Z", "2026-03-08T13:30:00Z"},
+		},
+		{
+			"gap",
+			"DTSTART:20260307T023000\nRRULE:FREQ=DAILY",
+			"America/New_York", "2026-03-07T08:00:00Z",
+			[]string{"2026-03-08T07:00:00Z", "2026-03-09T06:30:00Z"},
+		},
+	} {
+		s, err := parseRRule(tc.text, tc.timeZone, time.Time{})
+
+		if err != nil {
+			t.Fatalf("%s: %v\n", tc.name, err)
+		}
+
+		from := utc(tc.from)
+
+		for i, e := range tc.expected {
+			from = s.Next(from)
+
+			if (e == "" && !from.IsZero()) || (e != "" && !from.Equal(utc(e))) {
+				t.Fatalf("%s: wrong occurrence %d: expected:%s, got:%s\n", tc.name, i, e, from.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+func TestParseRRule_Invalid(t *testing.T) {
+	anchor := utc("2026-01-01T09:00:00Z")
+
+	for _, text := range []string{
+		"",
+		"FREQ=FORTNIGHTLY",
+		"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20260201",
+		"RRULE:FREQ=WEEKLY;BYMONTHDAY=1",
+		"RRULE:FREQ=DAILY;BYDAY=2TU",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=32",
+		"RRULE:FREQ=DAILY;BYHOUR=-1",
+		"EXDATE:20260102T090000",
+		"RDATE;VALUE=PERIOD:20260102T090000Z/PT1H",
+		"SUMMARY:Reminder",
+		"RRULE:FREQ=SECONDLY;COUNT=1000000000",
+	} {
+		if _, err := parseRRule(text, "UTC", anchor); err == nil {
+			t.Fatalf("The recurrence %q must be rejected\n", text)
+		}
+	}
+
+	if _, err := parseRRule("DTSTART;TZID=Europe/Paris:20260101T090000\nRRULE:FREQ=DAILY", "Asia/Tokyo", anchor); err != ErrConflictingTimeZones {
+		t.Fatalf("The conflicting time zones must be rejected: got %v\n", err)
+	}
+
+	if _, err := parseRRule("RRULE:FREQ=DAILY", "UTC", time.Time{}); err != ErrNoDTStart {
+		t.Fatalf("A rule must have a start: got %v\n", err)
+	}
+}
+
+func TestParseRRule_CountBound(t *testing.T) {
+	anchor := utc("2026-01-01T09:00:00Z")
+
+	// Every occurrence is excluded, so the whole series is walked within a single call
+	s, err := parseRRule(fmt.Sprintf("RRULE:FREQ=SECONDLY;COUNT=%d\nEXRULE:FREQ=SECONDLY", maxRRuleCount), "UTC", anchor)
+
+	if err != nil {
+		t.Fatalf("The rule must be valid: %v\n", err)
+	}
+
+	start := time.Now()
+
+	if next := s.Next(anchor); !next.IsZero() {
+		t.Fatalf("Every occurrence must be excluded: got %s\n", next)
+	}
+
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("The occurrences must not be counted from the start each time: took %s\n", d)
+	}
+}
+
+func TestScheduler_RRule(t *testing.T) {
+	sch := newTestScheduler()
+
+	startAt := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := sch.Schedule(Event{Mode: RRuleMode, RRule: "RRULE:FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9;BYMINUTE=0;BYSECOND=0", TimeZone: "UTC", StartAt: startAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sch.queue.Pop()
+
+	if e.ShouldExecuteAt.Weekday() != time.Friday || e.ShouldExecuteAt.Month() != time.January || e.ShouldExecuteAt.Day() < 25 || e.ShouldExecuteAt.Hour() != 9 {
+		t.Fatalf("The first occurrence must be the last Friday of January: got %s\n", e.ShouldExecuteAt)
+	}
+
+	next, err := sch.next(newEvent(*e))
+
+	if err != nil || next.Month() != time.February || next.Weekday() != time.Friday {
+		t.Fatalf("The next occurrence must be the last Friday of February: got %s (%v)\n", next, err)
+	}
+
+	if _, err := sch.Schedule(Event{Mode: RRuleMode, RRule: "RRULE:FREQ=WEEKLY;BYMONTHDAY=1"}); err == nil {
+		t.Fatalf("An invalid rule must be rejected at schedule time\n")
+	}
+}
@@ -2,37 +2,55 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"github.com/robfig/cron/v3"
 	circuit "github.com/rubyist/circuitbreaker"
 	uuid "github.com/satori/go.uuid"
+	"log"
 	"math"
 	"runtime"
+	"sync"
 	"time"
 )
 
 type Scheduler interface {
 	Schedule(e Event) (ID, error)
+	Preview(e Event, n int) (Preview, error)
 	Unschedule(id ID) error
+	Get(id ID) (Event, error)
+	List() ([]Event, error)
 	schedule(e event)
+	next(e event) (time.Time, error)
+	misfire(e event, now time.Time) (event, bool)
+	skip(e event)
 	Start() error
 	Stop()
 	SetConfig(conf SchedulerConfig) error
+	Config() SchedulerConfig
+	SetCalendar(c Calendar) error
+	DeleteCalendar(name string) error
+	Calendars() []Calendar
+	ReloadCalendars() error
+	ConfigureCalendars(cals []Calendar) error
 }
 
 type scheduler struct {
 	dpM           dispatchManager
 	pM            PersistenceManager
 	cM            CacheManager
-	sM            stackManager
+	sM            *stackManager
 	dpFn          DispatchFunc
 	ctx           context.Context
 	cancel        context.CancelFunc
 	cr            cron.Parser
 	inputMetrics  *metrics
 	outputMetrics *metrics
-	workers       []processingWorker
+	workers       map[*stack]processingWorker
 	queue         rawEventQueue
 	conf          SchedulerConfig
+	confMu        *sync.RWMutex
+	calendars     calendars
+	jitters       topicJitters
 }
 
 type SchedulerConfig struct {
@@ -41,8 +59,57 @@ type SchedulerConfig struct {
 	DefaultInputQueueCapacity int
 	MaxInputQueueCapacity     int
 	MaxBulkLimit              int
+
+	// TopicJitters are the default jitters of the topics, the first one matching the topic of an event applies
+	TopicJitters []TopicJitter
+}
+
+// Validate checks the sizes of the configuration and returns every problem found, the fields are named
+// after the configuration file
+func (c SchedulerConfig) Validate() []error {
+	var errs []error
+
+	positive := func(name string, v int) {
+		if v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0, got %d", name, v))
+		}
+	}
+
+	notAbove := func(defName string, def int, maxName string, max int) {
+		if def > max {
+			errs = append(errs, fmt.Errorf("%s (%d) must not be greater than %s (%d)", defName, def, maxName, max))
+		}
+	}
+
+	positive("system.stacksNumber", c.StacksNumber)
+	positive("system.defaultStackCapacity", c.DefaultStackCapacity)
+	positive("system.maxStackCapacity", c.MaxStackCapacity)
+	notAbove("system.defaultStackCapacity", c.DefaultStackCapacity, "system.maxStackCapacity", c.MaxStackCapacity)
+
+	positive("dispatch.workersNumber", c.WorkerNumber)
+	positive("dispatch.defaultQueueCapacity", c.DefaultQueueCapacity)
+	positive("dispatch.maxQueueCapacity", c.MaxQueueCapacity)
+	notAbove("dispatch.defaultQueueCapacity", c.DefaultQueueCapacity, "dispatch.maxQueueCapacity", c.MaxQueueCapacity)
+
+	if c.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("dispatch.maxRetries must not be negative, got %d", c.MaxRetries))
+	}
+
+	if c.MaxRetries > 0 && c.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("dispatch.retryBackoff must be greater than 0 when dispatch.maxRetries is set, got %s", c.RetryBackoff))
+	}
+
+	positive("input.defaultQueueCapacity", c.DefaultInputQueueCapacity)
+	positive("input.maxQueueCapacity", c.MaxInputQueueCapacity)
+	positive("input.maxBulkLimit", c.MaxBulkLimit)
+	notAbove("input.defaultQueueCapacity", c.DefaultInputQueueCapacity, "input.maxQueueCapacity", c.MaxInputQueueCapacity)
+
+	return errs
 }
 
+// cronParser parses the cron expressions of the events, the field of the seconds is optional
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 func NewScheduler(ctx context.Context, conf SchedulerConfig, pers PersistenceManager, cache CacheManager, fn DispatchFunc) Scheduler {
 	ctx, cancel := context.WithCancel(ctx)
 
@@ -57,21 +124,24 @@ func NewScheduler(ctx context.Context, conf SchedulerConfig, pers PersistenceMan
 		pM:            pers,
 		cM:            cache,
 		cancel:        cancel,
-		sM:            *sM,
+		sM:            sM,
 		conf:          conf,
+		confMu:        &sync.RWMutex{},
 		ctx:           ctx,
 		dpFn:          fn,
-		cr:            cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor),
-		workers:       make([]processingWorker, conf.StacksNumber),
+		cr:            cronParser,
+		workers:       make(map[*stack]processingWorker, conf.StacksNumber),
 		queue:         newRawEventQueue(conf.DefaultInputQueueCapacity, conf.MaxInputQueueCapacity),
 		inputMetrics:  &inputMet,
 		outputMetrics: &outputMet,
 	}
 
-	for i := 0; i < conf.StackManagerConfig.StacksNumber; i++ {
-		sch.workers[i] = newProcessingWorker(ctx, sM.stacks[i], sch, dpM)
+	for _, st := range sM.Stacks() {
+		sch.workers[st] = newProcessingWorker(ctx, st, sch, dpM)
 	}
 
+	sch.jitters.set(conf.TopicJitters)
+
 	return sch
 }
 
@@ -79,32 +149,136 @@ func (sch *scheduler) Unschedule(id ID) error {
 	return sch.pM.Delete(sch.ctx, id)
 }
 
+func (sch *scheduler) Get(id ID) (Event, error) {
+	return sch.pM.Get(sch.ctx, id)
+}
+
+func (sch *scheduler) List() ([]Event, error) {
+	return sch.pM.GetAll(sch.ctx)
+}
+
 func (sch *scheduler) Schedule(e Event) (ID, error) {
 	sch.inputMetrics.Op()
 
+	// The jitter of the event depends on its ID
+	e.ID = ID(uuid.NewV4().String())
+
+	e, err := sch.firstOccurrence(e)
+
+	if err != nil {
+		return "", err
+	}
+
+	sch.queue.Lock()
+	defer sch.queue.Unlock()
+
+	return e.ID, sch.queue.Push(e)
+}
+
+// firstOccurrence validates the schedule of the event and sets its first occurrence
+func (sch *scheduler) firstOccurrence(e Event) (Event, error) {
+	if err := ValidateLabels(e.Labels); err != nil {
+		return e, err
+	}
+
+	if _, err := LoadTimeZone(e.TimeZone); err != nil {
+		return e, err
+	}
+
+	if e.MaxOccurrences < 0 || (!e.EndAt.IsZero() && e.EndAt.Before(e.StartAt)) {
+		return e, ErrInvalidBounds
+	}
+
+	if e.Misfire > MisfireSkip {
+		return e, ErrInvalidMisfirePolicy
+	}
+
+	if e.CalendarPolicy > CalendarShift {
+		return e, ErrInvalidCalendarPolicy
+	}
+
+	e, topicJitter := sch.withTopicJitter(e)
+
+	if e.Jitter < 0 {
+		return e, ErrInvalidJitter
+	}
+
+	if e.JitterMode > JitterRandom {
+		return e, ErrInvalidJitterMode
+	}
+
+	if e.Calendar != "" {
+		if !e.Mode.recurring() {
+			return e, ErrCalendarNotRecurring
+		}
+
+		if _, ok := sch.calendars.get(e.Calendar); !ok {
+			return e, ErrUnknownCalendar
+		}
+	}
+
+	e.FireCount = 0
+	e.LastFiredAt = time.Time{}
+
 	if e.ShouldExecuteAt.Before(time.Now()) {
 		e.ShouldExecuteAt = time.Now()
 	}
 
-	if e.Mode == CronMode {
-		s, err := sch.cr.Parse(e.CronExpression)
+	if e.Mode.recurring() {
+		from := e.ShouldExecuteAt
 
-		if err != nil {
-			return "", err
+		// The first occurrence may be StartAt itself
+		if e.StartAt.After(from) {
+			from = e.StartAt.Add(-time.Nanosecond)
 		}
 
-		e.ShouldExecuteAt = s.Next(e.ShouldExecuteAt)
-	}
+		// The intervals and the rules start with their first occurrence by default, which is ShouldExecuteAt or StartAt
+		if (e.Mode == IntervalMode || e.Mode == RRuleMode) && e.Anchor.IsZero() {
+			e.Anchor = e.ShouldExecuteAt
 
-	id := uuid.NewV4().String()
-	e.ID = ID(id)
+			if e.StartAt.After(e.Anchor) {
+				e.Anchor = e.StartAt
+			}
 
-	sch.queue.Lock()
-	defer sch.queue.Unlock()
+			from = e.Anchor.Add(-time.Nanosecond)
+		}
 
-	return e.ID, sch.queue.Push(e)
+		if e.Jitter > 0 {
+			period, err := sch.shortestPeriod(e, from)
+
+			if err != nil {
+				return e, err
+			}
+
+			if period > 0 && e.Jitter >= period {
+				if !topicJitter {
+					return e, ErrJitterTooLong
+				}
+
+				// The default jitter of the topic is shortened rather than failing the schedule
+				e.Jitter = period - time.Millisecond
+			}
+		}
+
+		s, err := sch.recurrence(newEvent(e))
+
+		if err != nil {
+			return e, err
+		}
+
+		e.ShouldExecuteAt = s.Next(from)
+
+		if e.ShouldExecuteAt.IsZero() || (!e.EndAt.IsZero() && e.ShouldExecuteAt.After(e.EndAt)) {
+			return e, ErrNoOccurrence
+		}
+	} else if e.Jitter > 0 {
+		e.ShouldExecuteAt = e.ShouldExecuteAt.Add(jitterOffset(e.ID, e.JitterMode, e.Jitter, e.ShouldExecuteAt))
+	}
+
+	return e, nil
 }
 
+// schedule pushes the next occurrence of a dispatched event, unless it has been unscheduled
 func (sch *scheduler) schedule(e event) {
 	_, err := sch.pM.Get(sch.ctx, e.ID)
 
@@ -112,19 +286,80 @@ func (sch *scheduler) schedule(e event) {
 		return
 	}
 
-	if e.Mode == CronMode {
-		s, err := sch.cr.Parse(e.CronExpression)
+	e.ShouldExecuteAt = e.Next
+	e.Next = time.Time{}
 
-		if err != nil {
+	if err := sch.sM.Push(e); err != nil {
+		return
+	}
+}
+
+// skip moves the event to its next occurrence without dispatching the current one,
+// the event is unscheduled if there is none
+func (sch *scheduler) skip(e event) {
+	if e.Next.IsZero() {
+		sch.Unschedule(e.ID)
+		return
+	}
+
+	sch.move(e, 0)
+
+	sch.schedule(e)
+}
+
+// move persists the skipped occurrence, it is attempted again until it succeeds so that the occurrence
+// is not dispatched after a restart
+func (sch *scheduler) move(e event, attempt int) {
+	err := sch.pM.Move(sch.ctx, e.ID, e.Next)
+
+	if err == nil || sch.ctx.Err() != nil {
+		return
+	}
+
+	delay := exponentialBackoff(persistenceRetryBackoff, attempt+1)
+
+	log.Printf("failed to skip the occurrence %s of event %s, retrying in %s: %v\n", e.ShouldExecuteAt.Format(time.RFC3339), e.ID, delay, err)
+
+	time.AfterFunc(delay, func() {
+		if sch.ctx.Err() != nil {
 			return
 		}
 
-		e.ShouldExecuteAt = s.Next(e.ShouldExecuteAt)
+		sch.move(e, attempt+1)
+	})
+}
+
+// next returns the occurrence following the one of the event, or the zero time if it does not recur
+// or if it is the last one
+func (sch *scheduler) next(e event) (time.Time, error) {
+	if !e.Mode.recurring() {
+		return time.Time{}, nil
 	}
 
-	if err := sch.sM.Push(e); err != nil {
-		return
+	if e.MaxOccurrences > 0 && e.FireCount+1 >= e.MaxOccurrences {
+		return time.Time{}, nil
 	}
+
+	s, err := sch.recurrence(e)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	from := e.ShouldExecuteAt
+
+	// The occurrences missed meanwhile are only dispatched by MisfireFireAll
+	if now := time.Now(); e.Misfire != MisfireFireAll && misfired(e, now) {
+		from = now
+	}
+
+	next := s.Next(from)
+
+	if !e.EndAt.IsZero() && next.After(e.EndAt) {
+		return time.Time{}, nil
+	}
+
+	return next, nil
 }
 
 func (sch *scheduler) run() {
@@ -140,8 +375,8 @@ func (sch *scheduler) run() {
 
 		limit := int(math.Ceil(rate * 5))
 
-		if limit > sch.conf.MaxBulkLimit {
-			limit = sch.conf.MaxBulkLimit
+		if maxBulkLimit := sch.Config().MaxBulkLimit; limit > maxBulkLimit {
+			limit = maxBulkLimit
 		}
 
 		evs := make([]Event, 0, limit)
@@ -173,12 +408,7 @@ func (sch *scheduler) run() {
 		}
 
 		for _, e := range evs {
-			err := sch.sM.Push(event{
-				ID:              e.ID,
-				CronExpression:  e.CronExpression,
-				ShouldExecuteAt: e.ShouldExecuteAt,
-				Mode:            e.Mode,
-			})
+			err := sch.sM.Push(newEvent(e))
 
 			if err != nil {
 				return
@@ -205,12 +435,7 @@ func (sch *scheduler) restoreEventsAtStartup() error {
 	}
 
 	for _, e := range evs {
-		if err := sch.sM.Push(event{
-			ID:              e.ID,
-			CronExpression:  e.CronExpression,
-			ShouldExecuteAt: e.ShouldExecuteAt,
-			Mode:            e.Mode,
-		}); err != nil {
+		if err := sch.sM.Push(newEvent(e)); err != nil {
 			return err
 		}
 	}
@@ -221,12 +446,18 @@ func (sch *scheduler) restoreEventsAtStartup() error {
 func (sch *scheduler) Start() error {
 	sch.dpM.Run()
 
+	sch.confMu.RLock()
 	for _, p := range sch.workers {
 		p.Run()
 	}
+	sch.confMu.RUnlock()
 
 	go sch.run()
 
+	if err := sch.ReloadCalendars(); err != nil {
+		return err
+	}
+
 	if err := sch.restoreEventsAtStartup(); err != nil {
 		return err
 	}
@@ -237,47 +468,68 @@ func (sch *scheduler) Start() error {
 func (sch *scheduler) Stop() {
 	sch.dpM.Stop()
 
+	sch.confMu.RLock()
 	for _, p := range sch.workers {
 		p.Stop()
 	}
+	sch.confMu.RUnlock()
 
 	sch.cancel()
 }
 
+// SetConfig reconfigures the scheduler while it is running: the events of removed stacks are migrated,
+// the queued dispatches are kept and every stack remains processed by exactly one worker
 func (sch *scheduler) SetConfig(conf SchedulerConfig) error {
-	err := sch.sM.SetConfig(conf.StackManagerConfig)
+	sch.confMu.Lock()
+	defer sch.confMu.Unlock()
 
-	if err != nil {
-		return err
+	for _, j := range conf.TopicJitters {
+		if err := ValidateTopicJitter(j); err != nil {
+			return err
+		}
 	}
 
-	sch.dpM.Stop()
-	sch.dpM = newDispatchManager(sch.ctx, sch.pM, sch.dpFn, conf.DispatchManagerConfig, sch.outputMetrics)
-	defer sch.dpM.Run()
+	if err := sch.sM.SetConfig(conf.StackManagerConfig); err != nil {
+		return err
+	}
 
-	delta := len(sch.workers) - conf.StacksNumber
+	sch.jitters.set(conf.TopicJitters)
 
-	if delta > 0 {
-		toDelete := sch.workers[:delta]
+	sch.dpM.SetConfig(conf.DispatchManagerConfig)
 
-		for _, p := range toDelete {
-			p.Stop()
-		}
+	sch.queue.Lock()
+	sch.queue.setCapacity(conf.DefaultInputQueueCapacity, conf.MaxInputQueueCapacity)
+	sch.queue.Unlock()
 
-		sch.workers = sch.workers[delta:]
-	}
+	current := sch.sM.Stacks()
+	kept := make(map[*stack]processingWorker, len(current))
 
-	if delta < 0 {
-		oldLen := len(sch.workers)
+	for _, st := range current {
+		p, ok := sch.workers[st]
 
-		for i := oldLen; i < oldLen+(-delta); i++ {
-			p := newProcessingWorker(sch.ctx, sch.sM.stacks[i], sch, sch.dpM)
+		if !ok {
+			p = newProcessingWorker(sch.ctx, st, sch, sch.dpM)
+			p.Run()
+		}
 
-			sch.workers = append(sch.workers, p)
+		kept[st] = p
+	}
 
-			p.Run()
+	for st, p := range sch.workers {
+		if _, ok := kept[st]; !ok {
+			p.Stop()
 		}
 	}
 
+	sch.workers = kept
+	sch.conf = conf
+
 	return nil
 }
+
+func (sch *scheduler) Config() SchedulerConfig {
+	sch.confMu.RLock()
+	defer sch.confMu.RUnlock()
+
+	return sch.conf
+}
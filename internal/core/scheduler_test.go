@@ -62,7 +62,7 @@ func TestScheduler_Schedule(t *testing.T) {
 		DefaultInputQueueCapacity: 1200,
 		MaxInputQueueCapacity:     1600,
 		MaxBulkLimit:              2000,
-	}, persM, cacheM, func(e Event) error {
+	}, persM, cacheM, func(ctx context.Context, e Event) error {
 		if e.Mode == CronMode {
 			if cronJobCount == 3 {
 				return nil
@@ -123,3 +123,63 @@ func TestScheduler_Schedule(t *testing.T) {
 		t.Fatalf("Not every events got dispatched: expected: 3, got: %d\n", cronJobCount)
 	}
 }
+
+// newTestScheduler returns a scheduler without persistence, the scheduled events stay in its input queue
+func newTestScheduler() *scheduler {
+	met := newMetrics()
+
+	return &scheduler{cr: testParser, queue: newRawEventQueue(10, 10), inputMetrics: &met}
+}
+
+func TestScheduler_Bounds(t *testing.T) {
+	sch := newTestScheduler()
+
+	startAt := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 * * * *", StartAt: startAt, EndAt: startAt.Add(2 * time.Hour), FireCount: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sch.queue.Pop()
+
+	if !e.ShouldExecuteAt.Equal(startAt) || e.FireCount != 0 {
+		t.Fatalf("The first occurrence must be the start: expected:%s, got:%s (%d fired)\n", startAt, e.ShouldExecuteAt, e.FireCount)
+	}
+
+	ev := event{Mode: CronMode, CronExpression: e.CronExpression, ShouldExecuteAt: e.ShouldExecuteAt, EndAt: e.EndAt}
+
+	for i, expected := range []time.Time{startAt.Add(time.Hour), startAt.Add(2 * time.Hour), {}} {
+		next, err := sch.next(ev)
+
+		if err != nil || !next.Equal(expected) {
+			t.Fatalf("Wrong occurrence %d: expected:%s, got:%s (%v)\n", i+1, expected, next, err)
+		}
+
+		ev.ShouldExecuteAt = next
+	}
+
+	ev = event{Mode: CronMode, CronExpression: "0 * * * *", ShouldExecuteAt: startAt, MaxOccurrences: 3, FireCount: 1}
+
+	if next, _ := sch.next(ev); next.IsZero() {
+		t.Fatalf("The third occurrence must be scheduled\n")
+	}
+
+	ev.FireCount = 2
+
+	if next, _ := sch.next(ev); !next.IsZero() {
+		t.Fatalf("The event must not recur after its last occurrence: got %s\n", next)
+	}
+
+	for _, invalid := range []Event{
+		{Mode: CronMode, CronExpression: "0 * * * *", StartAt: startAt, EndAt: startAt.Add(-time.Hour)},
+		{Mode: CronMode, CronExpression: "0 * * * *", MaxOccurrences: -1},
+	} {
+		if _, err := sch.Schedule(invalid); err != ErrInvalidBounds {
+			t.Fatalf("Invalid bounds must be rejected: got %v\n", err)
+		}
+	}
+
+	if _, err := sch.Schedule(Event{Mode: CronMode, CronExpression: "0 * * * *", StartAt: startAt.Add(time.Minute), EndAt: startAt.Add(30 * time.Minute)}); err != ErrNoOccurrence {
+		t.Fatalf("An event without occurrence must be rejected: got %v\n", err)
+	}
+}
@@ -73,6 +73,20 @@ func (s *stack) resize(cap int) {
 	}
 }
 
+// setCapacity updates the default and max capacities, the max capacity never goes below the current one
+func (s *stack) setCapacity(defaultCap int, maxCap int) {
+	if maxCap < s.cap {
+		maxCap = s.cap
+	}
+
+	if defaultCap > maxCap {
+		defaultCap = maxCap
+	}
+
+	s.maxCap = maxCap
+	s.defaultCap = defaultCap
+}
+
 func (s *stack) Get(i int) *stackNode {
 	node := s.start
 
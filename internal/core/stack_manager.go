@@ -1,5 +1,10 @@
 package core
 
+import (
+	"sync"
+	"sync/atomic"
+)
+
 const maxDefaultStackCapacity = 10000
 
 type StackManagerConfig struct {
@@ -14,6 +19,7 @@ type stackManager struct {
 	stacks     stacks
 	config     StackManagerConfig
 	insertions int64
+	mu         *sync.RWMutex
 }
 
 func newStackManager(config StackManagerConfig) *stackManager {
@@ -31,12 +37,20 @@ func newStackManager(config StackManagerConfig) *stackManager {
 	sm := stackManager{
 		stacks: stacks,
 		config: config,
+		mu:     &sync.RWMutex{},
 	}
 
 	return &sm
 }
 
 func (s *stackManager) Push(e event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.push(e)
+}
+
+func (s *stackManager) push(e event) error {
 	i := s.getNextStackIndex()
 	st := s.stacks[i]
 
@@ -47,31 +61,102 @@ func (s *stackManager) Push(e event) error {
 		return err
 	}
 
-	s.insertions++
+	atomic.AddInt64(&s.insertions, 1)
 
 	return nil
 }
 
+// SetConfig applies the new capacities to every stack and resizes the number of stacks,
+// the events of the removed stacks are migrated to the remaining ones. Nothing is changed on error
 func (s *stackManager) SetConfig(config StackManagerConfig) error {
+	if config.MaxStackCapacity == 0 {
+		config.MaxStackCapacity = maxDefaultStackCapacity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.fits(config); err != nil {
+		return err
+	}
+
+	old := s.config
+	s.config = config
+	s.setCapacity(config)
+
 	if err := s.resize(config.StacksNumber); err != nil {
+		s.config = old
+		s.setCapacity(old)
+
 		return err
 	}
 
-	if config.MaxStackCapacity > s.config.MaxStackCapacity {
-		for _, st := range s.stacks {
-			st.Lock()
-			st.maxCap = config.MaxStackCapacity
-			st.Unlock()
+	return nil
+}
+
+// setCapacity must be called with the write lock held
+func (s *stackManager) setCapacity(config StackManagerConfig) {
+	for _, st := range s.stacks {
+		st.Lock()
+		st.setCapacity(config.DefaultStackCapacity, config.MaxStackCapacity)
+		st.Unlock()
+	}
+}
+
+// fits tells whether the events of the stacks removed by the config fit in the remaining ones,
+// it must be called with the write lock held
+func (s *stackManager) fits(config StackManagerConfig) error {
+	ln := len(s.stacks)
+
+	if config.StacksNumber >= ln {
+		return nil
+	}
+
+	if config.StacksNumber <= 0 {
+		return ErrMaxStackCapacity
+	}
+
+	room := 0
+
+	for _, st := range s.stacks[ln-config.StacksNumber:] {
+		st.Lock()
+
+		// The max capacity of a stack never goes below its current one
+		if st.cap > config.MaxStackCapacity {
+			room += st.cap - st.len
+		} else {
+			room += config.MaxStackCapacity - st.len
 		}
+
+		st.Unlock()
+	}
+
+	if stacksLen(s.stacks[:ln-config.StacksNumber]) > room {
+		return ErrMaxStackCapacity
 	}
 
 	return nil
 }
 
+// Stacks returns a snapshot of the current stacks
+func (s *stackManager) Stacks() stacks {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(stacks, len(s.stacks))
+	copy(out, s.stacks)
+
+	return out
+}
+
 func (s *stackManager) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	return stacksLen(s.stacks)
 }
 
+// resize must be called with the write lock held
 func (s *stackManager) resize(stackNumber int) error {
 	ln := len(s.stacks)
 
@@ -88,31 +173,61 @@ func (s *stackManager) resize(stackNumber int) error {
 		return nil
 	}
 
-	if stackNumber < ln {
-		if s.config.MaxStackCapacity*stackNumber-s.Len() < 0 {
-			return ErrMaxStackCapacity
-		}
-
-		delSt := s.stacks[:ln-stackNumber]
+	if stackNumber <= 0 {
+		return ErrMaxStackCapacity
+	}
 
-		s.stacks = s.stacks[ln-stackNumber:]
+	kept := s.stacks[ln-stackNumber:]
 
-		for _, st := range delSt {
-			st.Lock()
-			for i := 0; i < st.len; i++ {
-				if err := s.Push(*st.Get(i).event); err != nil {
-					return err
+	for _, st := range s.stacks[:ln-stackNumber] {
+		// The stack is drained rather than copied so that its processing worker,
+		// which may still be running, cannot dispatch an event twice
+		st.Lock()
+		evs := make([]event, 0, st.len)
+		for st.len > 0 {
+			evs = append(evs, st.Pop())
+		}
+		st.Unlock()
+
+		for i, e := range evs {
+			if err := kept.pushAny(e); err != nil {
+				// The events which are not migrated go back to their stack, the stacks are kept as they are
+				st.Lock()
+				for _, e := range evs[i:] {
+					st.Push(e)
 				}
+				st.Unlock()
+
+				return err
 			}
-			st.Unlock()
 		}
 	}
 
+	s.stacks = kept
+
 	return nil
 }
 
+// pushAny pushes the event into the first stack which is not full
+func (s stacks) pushAny(e event) error {
+	for _, st := range s {
+		st.Lock()
+
+		if st.len < st.maxCap {
+			err := st.Push(e)
+			st.Unlock()
+
+			return err
+		}
+
+		st.Unlock()
+	}
+
+	return ErrMaxStackCapacity
+}
+
 func (sm *stackManager) getNextStackIndex() int {
-	return int((sm.insertions) % int64(len(sm.stacks)))
+	return int(atomic.LoadInt64(&sm.insertions) % int64(len(sm.stacks)))
 }
 
 func (s stacks) Len() int {
@@ -123,7 +238,9 @@ func stacksLen(s stacks) int {
 	i := 0
 
 	for _, st := range s {
+		st.Lock()
 		i += st.len
+		st.Unlock()
 	}
 
 	return i
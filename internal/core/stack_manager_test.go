@@ -116,6 +116,81 @@ func TestStackManager(t *testing.T) {
 	}
 }
 
+func TestStackManager_SetConfig(t *testing.T) {
+	s := newStackManager(StackManagerConfig{
+		StacksNumber:         4,
+		DefaultStackCapacity: 10,
+		MaxStackCapacity:     20,
+	})
+
+	for _, e := range generateEvents(40) {
+		if err := s.Push(e); err != nil {
+			t.Error(err)
+		}
+	}
+
+	removed := s.Stacks()[:2]
+
+	if err := s.SetConfig(StackManagerConfig{
+		StacksNumber:         2,
+		DefaultStackCapacity: 15,
+		MaxStackCapacity:     30,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.stacks.Len() != 2 {
+		t.Fatalf("The stack manager has not been resized: expected:%d, got:%d", 2, s.stacks.Len())
+	}
+
+	if s.Len() != 40 {
+		t.Fatalf("Elements have been lost while reconfiguring the stack manager: expected:%d, got:%d\n", 40, s.Len())
+	}
+
+	for _, st := range removed {
+		if st.len != 0 {
+			t.Fatalf("The removed stacks must be drained: %d events left\n", st.len)
+		}
+	}
+
+	for _, st := range s.stacks {
+		if st.maxCap != 30 || st.defaultCap != 15 {
+			t.Fatalf("The capacities have not been applied: expected:%d/%d, got:%d/%d\n", 15, 30, st.defaultCap, st.maxCap)
+		}
+	}
+
+	if err := s.SetConfig(StackManagerConfig{
+		StacksNumber:         1,
+		DefaultStackCapacity: 5,
+		MaxStackCapacity:     35,
+	}); err == nil {
+		t.Fatalf("Max capacity would be exceeded but no error was raised\n")
+	}
+
+	if s.stacks.Len() != 2 || s.config.StacksNumber != 2 || s.Len() != 40 {
+		t.Fatalf("A failed reconfiguration must keep the current stacks and events\n")
+	}
+
+	for _, st := range s.stacks {
+		if st.maxCap != 30 || st.defaultCap != 15 {
+			t.Fatalf("A failed reconfiguration must keep the capacities: expected:%d/%d, got:%d/%d\n", 15, 30, st.defaultCap, st.maxCap)
+		}
+	}
+
+	// The migrated events fill the remaining stacks whatever their order of insertion
+	if err := s.SetConfig(StackManagerConfig{
+		StacksNumber:         1,
+		DefaultStackCapacity: 15,
+		MaxStackCapacity:     40,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.stacks.Len() != 1 || s.Len() != 40 {
+		t.Fatalf("The events must fit in the remaining stack: expected:%d, got:%d\n", 40, s.Len())
+	}
+}
+
 func BenchmarkInsertingBelowCapacity(b *testing.B) {
 	b.StopTimer()
 	s := newStackManager(config)
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+var ErrConflictingTimeZones = errors.New("the time zone of the expression conflicts with the time zone of the event")
+
+// LoadTimeZone returns the IANA time zone, the local zone of the server if the name is empty
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %v", name, err)
+	}
+
+	return loc, nil
+}
+
+// parseCron parses the cron expression in the time zone of the event, a CRON_TZ= or TZ= prefix
+// of the expression is used if the event has no time zone.
+//
+// The expression is evaluated on the wall clock of the zone, so that "0 9 * * *" fires at 9am
+// all year long. Across the DST transitions:
+//
+//   - the occurrences falling in a gap, e.g. 02:30 when the clocks jump from 02:00 to 03:00,
+//     fire once at the end of the gap
+//   - the occurrences falling in an overlap, e.g. 01:30 when the clocks go back from 02:00 to 01:00,
+//     fire once at their first instant
+func parseCron(p cron.Parser, expr string, timeZone string) (cron.Schedule, error) {
+	loc, err := LoadTimeZone(timeZone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := p.Parse(expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	spec, ok := s.(*cron.SpecSchedule)
+
+	// The @every schedules are durations, they do not depend on the zone
+	if !ok {
+		return s, nil
+	}
+
+	if spec.Location != time.Local {
+		if timeZone != "" && spec.Location.String() != loc.String() {
+			return nil, ErrConflictingTimeZones
+		}
+
+		loc = spec.Location
+	}
+
+	wall := *spec
+	wall.Location = time.UTC
+
+	return zonedSchedule{wall: &wall, loc: loc}, nil
+}
+
+// zonedSchedule evaluates a cron schedule on the wall clock of a time zone, the wall clock times
+// are represented in UTC so that the cron parser never sees a DST transition
+type zonedSchedule struct {
+	wall *cron.SpecSchedule
+	loc  *time.Location
+}
+
+func (s zonedSchedule) Next(t time.Time) time.Time {
+	w := s.wall.Next(wallClock(t, s.loc))
+
+	if w.IsZero() {
+		return w
+	}
+
+	return zonedInstant(w, t, s.loc)
+}
+
+// wallClock returns the time shown by the clocks of the zone at t, as a UTC time
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// zonedInstant returns the first instant after t at which the clocks of the zone show w, or the end
+// of the DST gap w falls in
+func zonedInstant(w time.Time, after time.Time, loc *time.Location) time.Time {
+	// The DST transitions are months apart, so the offsets a day before and after w
+	// are the only ones w may be shown with
+	_, before := w.Add(-24 * time.Hour).In(loc).Zone()
+	_, later := w.Add(24 * time.Hour).In(loc).Zone()
+
+	early, late := w.Add(-time.Duration(before)*time.Second), w.Add(-time.Duration(later)*time.Second)
+
+	if late.Before(early) {
+		early, late = late, early
+	}
+
+	var found time.Time
+
+	for _, c := range []time.Time{early, late} {
+		if !wallClock(c, loc).Equal(w) {
+			continue
+		}
+
+		if c.After(after) {
+			return c
+		}
+
+		found = c
+	}
+
+	if !found.IsZero() {
+		return found
+	}
+
+	// w is skipped by the clocks, the gap ends with the first instant shown with the later offset
+	for late.Sub(early) > time.Second {
+		mid := early.Add(late.Sub(early) / 2)
+
+		if _, off := mid.In(loc).Zone(); off == later {
+			late = mid
+		} else {
+			early = mid
+		}
+	}
+
+	return late.Truncate(time.Second)
+}
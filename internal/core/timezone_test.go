@@ -0,0 +1,85 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+var testParser = cronParser
+
+func nextOccurrences(t *testing.T, expr string, timeZone string, from time.Time, n int) []time.Time {
+	s, err := parseCron(testParser, expr, timeZone)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out []time.Time
+
+	for i := 0; i < n; i++ {
+		from = s.Next(from)
+		out = append(out, from)
+	}
+
+	return out
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return t.UTC()
+}
+
+func TestParseCron_DST(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expr     string
+		from     string
+		expected []string
+	}{
+		// New York springs forward from 02:00 EST to 03:00 EDT on March 8th 2026
+		{"same wall clock", "0 9 * * *", "2026-03-07T10:00:00-05:00", []string{"2026-03-08T13:00:00Z", "2026-03-09T13:00:00Z"}},
+		{"gap", "30 2 * * *", "2026-03-07T03:00:00-05:00", []string{"2026-03-08T07:00:00Z", "2026-03-09T06:30:00Z"}},
+		{"gap fired once", "*/20 2 * * *", "2026-03-08T01:50:00-05:00", []string{"2026-03-08T07:00:00Z", "2026-03-09T06:00:00Z"}},
+
+		// It falls back from 02:00 EDT to 01:00 EST on November 1st 2026
+		{"overlap", "30 1 * * *", "2026-10-31T12:00:00-04:00", []string{"2026-11-01T05:30:00Z", "2026-11-02T06:30:00Z"}},
+		{"overlap fired once", "*/30 * * * *", "2026-11-01T00:45:00-04:00", []string{"2026-11-01T05:00:00Z", "2026-11-01T05:30:00Z", "2026-11-01T07:00:00Z"}},
+		{"scheduled during the overlap", "45 1 * * *", "2026-11-01T01:15:00-05:00", []string{"2026-11-01T06:45:00Z"}},
+
+		// The durations do not depend on the zone
+		{"every", "@every 1h", "2026-03-08T01:30:00-05:00", []string{"2026-03-08T07:30:00Z", "2026-03-08T08:30:00Z"}},
+	} {
+		got := nextOccurrences(t, tc.expr, "America/New_York", utc(tc.from), len(tc.expected))
+
+		for i, e := range tc.expected {
+			if !got[i].Equal(utc(e)) {
+				t.Fatalf("%s: wrong occurrence %d of %q: expected:%s, got:%s\n", tc.name, i, tc.expr, e, got[i].UTC().Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+func TestParseCron_TimeZone(t *testing.T) {
+	from := utc("2026-06-01T00:00:00Z")
+
+	if got := nextOccurrences(t, "CRON_TZ=Europe/Paris 0 9 * * *", "", from, 1); !got[0].Equal(utc("2026-06-01T07:00:00Z")) {
+		t.Fatalf("The CRON_TZ prefix must be applied: got %s\n", got[0])
+	}
+
+	if got := nextOccurrences(t, "TZ=Asia/Tokyo 0 9 * * *", "Asia/Tokyo", from, 1); !got[0].Equal(utc("2026-06-01T00:00:00Z").Add(24 * time.Hour)) {
+		t.Fatalf("The prefix matching the time zone of the event must be accepted: got %s\n", got[0])
+	}
+
+	if _, err := parseCron(testParser, "CRON_TZ=Europe/Paris 0 9 * * *", "Asia/Tokyo"); err != ErrConflictingTimeZones {
+		t.Fatalf("The conflicting time zones must be rejected: got %v\n", err)
+	}
+
+	if _, err := parseCron(testParser, "0 9 * * *", "Mars/Olympus_Mons"); err == nil {
+		t.Fatalf("An unknown time zone must be rejected\n")
+	}
+}
@@ -0,0 +1,465 @@
+// Package filter parses the expressions selecting events on their labels, e.g.
+//
+//	tenant = acme and (region in (eu-west, eu-central) or not priority)
+//
+// The predicates are:
+//
+//	key = value        the label is set to the value
+//	key != value       the label is not set to the value, or is not set
+//	key in (v1, v2)    the label is set to one of the values
+//	key not in (v1)    the label is not set to any of the values, or is not set
+//	key                the label is set, whatever its value
+//
+// They are combined with parentheses, "not", "and" and "or", from the tightest to the loosest binding.
+// The keys and values containing spaces or punctuation are double-quoted
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter tells whether an event is selected by its labels
+type Filter interface {
+	Match(labels map[string]string) bool
+}
+
+// SyntaxError locates the error in the expression
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: %s at position %d", e.Msg, e.Pos)
+}
+
+// Parse compiles the expression, an empty expression returns a nil Filter which selects every event
+func Parse(expr string) (Filter, error) {
+	p := &parser{lex: &lexer{src: expr}}
+	p.next()
+
+	if p.tok.kind == tokEOF && p.err == nil {
+		return nil, nil
+	}
+
+	f, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+
+	return f, nil
+}
+
+type equal struct {
+	key   string
+	value string
+}
+
+func (f equal) Match(labels map[string]string) bool {
+	v, ok := labels[f.key]
+
+	return ok && v == f.value
+}
+
+type in struct {
+	key    string
+	values map[string]bool
+}
+
+func (f in) Match(labels map[string]string) bool {
+	v, ok := labels[f.key]
+
+	return ok && f.values[v]
+}
+
+type exists struct {
+	key string
+}
+
+func (f exists) Match(labels map[string]string) bool {
+	_, ok := labels[f.key]
+
+	return ok
+}
+
+type not struct {
+	f Filter
+}
+
+func (f not) Match(labels map[string]string) bool {
+	return !f.f.Match(labels)
+}
+
+type and []Filter
+
+func (f and) Match(labels map[string]string) bool {
+	for _, sub := range f {
+		if !sub.Match(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type or []Filter
+
+func (f or) Match(labels map[string]string) bool {
+	for _, sub := range f {
+		if sub.Match(labels) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokLParen
+	tokRParen
+	tokComma
+	tokEqual
+	tokNotEqual
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+
+	return strconv.Quote(t.text)
+}
+
+// keyword tells whether the token is the unquoted keyword
+func (t token) keyword(kw string) bool {
+	return t.kind == tokWord && t.text == kw
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-/:", r)
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+
+	start := l.pos
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	switch c := l.src[l.pos]; {
+	case c == '(':
+		l.pos++
+		return token{tokLParen, "(", start}, nil
+	case c == ')':
+		l.pos++
+		return token{tokRParen, ")", start}, nil
+	case c == ',':
+		l.pos++
+		return token{tokComma, ",", start}, nil
+	case c == '=':
+		l.pos++
+
+		// == is accepted as well
+		if l.pos < len(l.src) && l.src[l.pos] == '=' {
+			l.pos++
+		}
+
+		return token{tokEqual, "=", start}, nil
+	case c == '!' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '=':
+		l.pos += 2
+		return token{tokNotEqual, "!=", start}, nil
+	case c == '"':
+		return l.quoted()
+	}
+
+	for l.pos < len(l.src) {
+		r := rune(l.src[l.pos])
+
+		if r >= 0x80 {
+			// Multi-byte runes are only allowed in the quoted values
+			break
+		}
+
+		if !isWordRune(r) {
+			break
+		}
+
+		l.pos++
+	}
+
+	if l.pos == start {
+		return token{}, &SyntaxError{start, fmt.Sprintf("unexpected character %q", l.src[start])}
+	}
+
+	return token{tokWord, l.src[start:l.pos], start}, nil
+}
+
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '"':
+			l.pos++
+
+			s, err := strconv.Unquote(l.src[start:l.pos])
+
+			if err != nil {
+				return token{}, &SyntaxError{start, "invalid quoted value"}
+			}
+
+			return token{tokString, s, start}, nil
+		}
+
+		l.pos++
+	}
+
+	return token{}, &SyntaxError{start, "unterminated quoted value"}
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+	err error
+}
+
+// next reads the following token, a lexing error is reported as the end of the expression
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+
+	tok, err := p.lex.next()
+
+	if err != nil {
+		p.err = err
+		tok = token{kind: tokEOF, pos: p.lex.pos}
+	}
+
+	p.tok = tok
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	return &SyntaxError{p.tok.pos, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	f, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fs := or{f}
+
+	for p.tok.keyword("or") {
+		p.next()
+
+		f, err := p.parseAnd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+
+	return fs, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	f, err := p.parseNot()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fs := and{f}
+
+	for p.tok.keyword("and") {
+		p.next()
+
+		f, err := p.parseNot()
+
+		if err != nil {
+			return nil, err
+		}
+
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+
+	return fs, nil
+}
+
+func (p *parser) parseNot() (Filter, error) {
+	if p.tok.keyword("not") {
+		p.next()
+
+		f, err := p.parseNot()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return not{f}, nil
+	}
+
+	if p.tok.kind == tokLParen {
+		p.next()
+
+		f, err := p.parseOr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\", got %s", p.tok)
+		}
+
+		p.next()
+
+		return f, nil
+	}
+
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (Filter, error) {
+	if p.tok.kind != tokWord && p.tok.kind != tokString {
+		return nil, p.errorf("expected a label key, got %s", p.tok)
+	}
+
+	key := p.tok.text
+	p.next()
+
+	switch {
+	case p.tok.kind == tokEqual || p.tok.kind == tokNotEqual:
+		negate := p.tok.kind == tokNotEqual
+		p.next()
+
+		v, err := p.parseValue()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if negate {
+			return not{equal{key, v}}, nil
+		}
+
+		return equal{key, v}, nil
+	case p.tok.keyword("in"):
+		p.next()
+
+		return p.parseIn(key)
+	case p.tok.keyword("not"):
+		p.next()
+
+		if !p.tok.keyword("in") {
+			return nil, p.errorf("expected \"in\" after \"%s not\", got %s", key, p.tok)
+		}
+
+		p.next()
+
+		f, err := p.parseIn(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return not{f}, nil
+	}
+
+	return exists{key}, nil
+}
+
+func (p *parser) parseIn(key string) (Filter, error) {
+	if p.tok.kind != tokLParen {
+		return nil, p.errorf("expected \"(\" after \"in\", got %s", p.tok)
+	}
+
+	p.next()
+
+	f := in{key: key, values: make(map[string]bool)}
+
+	for {
+		v, err := p.parseValue()
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.values[v] = true
+
+		if p.tok.kind == tokRParen {
+			p.next()
+			return f, nil
+		}
+
+		if p.tok.kind != tokComma {
+			return nil, p.errorf("expected \",\" or \")\", got %s", p.tok)
+		}
+
+		p.next()
+	}
+}
+
+func (p *parser) parseValue() (string, error) {
+	if p.tok.kind != tokWord && p.tok.kind != tokString {
+		return "", p.errorf("expected a value, got %s", p.tok)
+	}
+
+	v := p.tok.text
+	p.next()
+
+	return v, nil
+}
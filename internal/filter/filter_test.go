@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	labels := map[string]string{"tenant": "acme", "region": "eu-west", "note": "hello world"}
+
+	for expr, expected := range map[string]bool{
+		"tenant = acme":                                 true,
+		"tenant == acme":                                true,
+		"tenant != acme":                                false,
+		"tenant = other":                                false,
+		"missing != acme":                               true,
+		"region in (eu-west, eu-central)":               true,
+		"region not in (eu-west)":                       false,
+		"missing not in (eu-west)":                      true,
+		"tenant":                                        true,
+		"not tenant":                                    false,
+		"not missing":                                   true,
+		`note = "hello world"`:                          true,
+		"tenant = acme and region = us":                 false,
+		"tenant = acme and region = us or note":         true,
+		"tenant = acme and (region = us or missing)":    false,
+		"not (tenant = other) and region in (eu-west)":  true,
+		"tenant = other or region = us or not missing":  true,
+		"not not tenant":                                true,
+		`"app.kubernetes.io/name" = "billing"`:          false,
+		"tenant=acme and region in(eu-west,eu-central)": true,
+	} {
+		f, err := Parse(expr)
+
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v\n", expr, err)
+		}
+
+		if got := f.Match(labels); got != expected {
+			t.Fatalf("Wrong result of %q: expected:%t, got:%t\n", expr, expected, got)
+		}
+	}
+
+	if f, err := Parse("  "); f != nil || err != nil {
+		t.Fatalf("An empty expression must select every event: got %v, %v\n", f, err)
+	}
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	for expr, pos := range map[string]int{
+		"tenant =":                  8,
+		"tenant = acme and":         17,
+		"(tenant = acme":            14,
+		"region in eu-west":         10,
+		"region in (eu-west":        18,
+		"region not eu-west":        11,
+		"tenant = acme region = eu": 14,
+		`note = "hello`:             7,
+		"tenant = ac$me":            11,
+		"= acme":                    0,
+	} {
+		_, err := Parse(expr)
+
+		var syntaxErr *SyntaxError
+
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("The expression %q must be rejected: got %v\n", expr, err)
+		}
+
+		if syntaxErr.Pos != pos {
+			t.Fatalf("Wrong position of the error in %q: expected:%d, got:%d (%v)\n", expr, pos, syntaxErr.Pos, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+const (
+	DefaultCommandTimeout = time.Minute
+
+	// maxCommandOutput bounds the output of a process kept for the logs
+	maxCommandOutput = 64 * 1024
+)
+
+var ErrEmptyCommand = errors.New("the command must not be empty")
+
+type CommandConfig struct {
+	// Args is the argv of the process, each argument is a text/template executed with a CommandData
+	Args []string
+
+	Dir string
+
+	// Env is appended to the environment of the server along with the SCHEDULO_* variables
+	Env []string
+
+	// Timeout kills the process along with its children once elapsed, DefaultCommandTimeout by default
+	Timeout time.Duration
+
+	// MaxProcesses bounds the number of processes running at once, the number of CPUs by default
+	MaxProcesses int
+}
+
+// CommandData is the data the argument templates are executed with
+type CommandData struct {
+	ID             string
+	Topic          string
+	CronExpression string
+	TimeZone       string
+	Payload        string
+	Labels         map[string]string
+	ScheduledAt    time.Time
+	FiredAt        time.Time
+}
+
+// ExitError is returned when the process does not exit with the status 0
+type ExitError struct {
+	Command  string
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", e.Command, e.ExitCode)
+}
+
+// Command runs a process for each event, the payload is written on its standard input
+type Command struct {
+	args []*template.Template
+	conf CommandConfig
+	sem  chan struct{}
+	now  func() time.Time
+	logf func(format string, v ...interface{})
+}
+
+func NewCommand(conf CommandConfig) (*Command, error) {
+	if len(conf.Args) == 0 {
+		return nil, ErrEmptyCommand
+	}
+
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultCommandTimeout
+	}
+
+	if conf.MaxProcesses <= 0 {
+		conf.MaxProcesses = runtime.NumCPU()
+	}
+
+	c := &Command{
+		args: make([]*template.Template, len(conf.Args)),
+		conf: conf,
+		sem:  make(chan struct{}, conf.MaxProcesses),
+		now:  time.Now,
+		logf: log.Printf,
+	}
+
+	for i, arg := range conf.Args {
+		tmpl, err := template.New(strconv.Itoa(i)).Option("missingkey=error").Parse(arg)
+
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+
+		c.args[i] = tmpl
+	}
+
+	return c, nil
+}
+
+func (c *Command) argv(data CommandData) ([]string, error) {
+	argv := make([]string, len(c.args))
+
+	for i, tmpl := range c.args {
+		buf := &bytes.Buffer{}
+
+		if err := tmpl.Execute(buf, data); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+
+		argv[i] = buf.String()
+	}
+
+	if argv[0] == "" {
+		return nil, ErrEmptyCommand
+	}
+
+	return argv, nil
+}
+
+func (c *Command) Send(ctx context.Context, e core.Event) error {
+	data := CommandData{
+		ID:             string(e.ID),
+		Topic:          e.Topic,
+		CronExpression: e.CronExpression,
+		TimeZone:       e.TimeZone,
+		Payload:        string(e.Payload),
+		Labels:         e.Labels,
+		ScheduledAt:    e.ShouldExecuteAt,
+		FiredAt:        c.now(),
+	}
+
+	argv, err := c.argv(data)
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	defer func() {
+		<-c.sem
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.conf.Timeout)
+	defer cancel()
+
+	out := &limitedBuffer{max: maxCommandOutput}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = c.conf.Dir
+	cmd.Stdin = bytes.NewReader(e.Payload)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.Env = append(append(os.Environ(), c.conf.Env...),
+		"SCHEDULO_EVENT_ID="+data.ID,
+		"SCHEDULO_TOPIC="+data.Topic,
+		"SCHEDULO_CRON_EXPRESSION="+data.CronExpression,
+		"SCHEDULO_TIME_ZONE="+data.TimeZone,
+		"SCHEDULO_SCHEDULED_AT="+strconv.FormatInt(data.ScheduledAt.Unix(), 10),
+		"SCHEDULO_FIRED_AT="+strconv.FormatInt(data.FiredAt.Unix(), 10),
+	)
+
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// The whole group is killed once the timeout elapses, the children still holding the output would
+	// keep Wait blocked otherwise
+	exited := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-exited:
+		}
+	}()
+
+	err = cmd.Wait()
+	close(exited)
+
+	c.logOutput(argv[0], data.ID, out)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s was killed after %s: %w", argv[0], c.conf.Timeout, ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) {
+		return &ExitError{Command: argv[0], ExitCode: exitErr.ExitCode()}
+	}
+
+	return err
+}
+
+func (c *Command) logOutput(name string, id string, out *limitedBuffer) {
+	s := bufio.NewScanner(bytes.NewReader(out.buf.Bytes()))
+
+	for s.Scan() {
+		c.logf("%s [%s]: %s\n", name, id, s.Text())
+	}
+
+	if out.truncated {
+		c.logf("%s [%s]: output truncated to %d bytes\n", name, id, out.max)
+	}
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.truncated = true
+
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package sink
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the process in its own group, so that its children are killed along with it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process and every process of its group
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
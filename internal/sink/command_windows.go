@@ -0,0 +1,12 @@
+package sink
+
+import (
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup only kills the process, its children are left running
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
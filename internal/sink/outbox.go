@@ -0,0 +1,111 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"regexp"
+	"time"
+)
+
+const DefaultOutboxTable = "schedulo_outbox"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+const (
+	postgresOutboxSchema = `
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			event_id CHAR(36) NOT NULL,
+			topic VARCHAR(255),
+			payload BYTEA,
+			scheduled_at TIMESTAMP,
+			fired_at TIMESTAMP
+		);
+	`
+
+	mysqlOutboxSchema = `
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			event_id CHAR(36) NOT NULL,
+			topic VARCHAR(255),
+			payload BLOB,
+			scheduled_at TIMESTAMP NULL,
+			fired_at TIMESTAMP NULL
+		);
+	`
+)
+
+type OutboxConfig struct {
+	// Url and Driver must designate the database of the persistence manager
+	Url    string
+	Driver string
+
+	// Table is created if it does not exist, DefaultOutboxTable by default
+	Table string
+}
+
+// Outbox inserts the dispatched events into a table of the scheduler database, in the transaction which
+// completes their dispatch. The consumers poll the table and delete the rows they have processed
+type Outbox struct {
+	insert string
+	now    func() time.Time
+}
+
+// NewOutbox creates the outbox table, the events are then inserted through the persistence manager
+func NewOutbox(conf OutboxConfig) (*Outbox, error) {
+	if conf.Table == "" {
+		conf.Table = DefaultOutboxTable
+	}
+
+	if !ValidTableName(conf.Table) {
+		return nil, fmt.Errorf("invalid outbox table name %q", conf.Table)
+	}
+
+	var schema, insert string
+
+	switch conf.Driver {
+	case "postgres":
+		schema = postgresOutboxSchema
+		insert = `INSERT INTO %s (event_id, topic, payload, scheduled_at, fired_at) VALUES ($1, $2, $3, $4, $5);`
+	case "mysql":
+		schema = mysqlOutboxSchema
+		insert = `INSERT INTO %s (event_id, topic, payload, scheduled_at, fired_at) VALUES (?, ?, ?, ?, ?);`
+	default:
+		return nil, fmt.Errorf("unsupported outbox driver %q", conf.Driver)
+	}
+
+	db, err := sql.Open(conf.Driver, conf.Url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+
+	if _, err := db.Exec(fmt.Sprintf(schema, conf.Table)); err != nil {
+		return nil, err
+	}
+
+	return &Outbox{
+		insert: fmt.Sprintf(insert, conf.Table),
+		now:    time.Now,
+	}, nil
+}
+
+// ValidTableName tells whether the name is a plain, optionally schema qualified, SQL identifier
+func ValidTableName(name string) bool {
+	return tableName.MatchString(name)
+}
+
+// Send registers the insertion of the event, it fails if ctx is not the one of a dispatch
+func (o *Outbox) Send(ctx context.Context, e core.Event) error {
+	firedAt := o.now()
+
+	return core.AddHandoff(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, o.insert, string(e.ID), e.Topic, e.Payload, e.ShouldExecuteAt, firedAt)
+
+		return err
+	})
+}
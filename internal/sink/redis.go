@@ -0,0 +1,121 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v7"
+	"github.com/yanishoss/schedulo/internal/core"
+	"time"
+)
+
+const DefaultRedisPrefix = "schedulo:events:"
+
+// RedisMode tells how the events are published to Redis
+type RedisMode string
+
+const (
+	// RedisStream appends each event to the stream of its topic, so that consumer groups can read them
+	RedisStream RedisMode = "stream"
+
+	// RedisPubSub publishes each event as JSON on the channel of its topic, it is lost if nobody listens
+	RedisPubSub RedisMode = "pubsub"
+)
+
+var RedisModes = []RedisMode{RedisStream, RedisPubSub}
+
+type RedisConfig struct {
+	Addr string
+	Pass string
+	DB   int
+
+	Mode RedisMode
+
+	// Prefix is prepended to the topic to name its stream or channel, DefaultRedisPrefix by default
+	Prefix string
+
+	// MaxLen approximately caps the length of each stream, they are not trimmed if it is zero
+	MaxLen int64
+}
+
+// Redis publishes the events to Redis Streams or Pub/Sub channels
+type Redis struct {
+	c    *redis.Client
+	conf RedisConfig
+	now  func() time.Time
+}
+
+func NewRedis(conf RedisConfig) (*Redis, error) {
+	if conf.Mode == "" {
+		conf.Mode = RedisStream
+	}
+
+	if conf.Prefix == "" {
+		conf.Prefix = DefaultRedisPrefix
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     conf.Addr,
+		Password: conf.Pass,
+		DB:       conf.DB,
+	})
+
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &Redis{
+		c:    client,
+		conf: conf,
+		now:  time.Now,
+	}, nil
+}
+
+func (r *Redis) Send(ctx context.Context, e core.Event) error {
+	c := r.c.WithContext(ctx)
+	key := r.conf.Prefix + e.Topic
+	rec := NewRecord(e, r.now())
+
+	switch r.conf.Mode {
+	case RedisStream:
+		values := map[string]interface{}{
+			"id":           string(rec.ID),
+			"topic":        rec.Topic,
+			"payload":      rec.Payload,
+			"scheduled_at": rec.ScheduledAt,
+			"fired_at":     rec.FiredAt,
+		}
+
+		// The labels are a JSON object, the field is omitted if there is none
+		if len(rec.Labels) > 0 {
+			labels, err := json.Marshal(rec.Labels)
+
+			if err != nil {
+				return err
+			}
+
+			values["labels"] = labels
+		}
+
+		return c.XAdd(&redis.XAddArgs{
+			Stream:       key,
+			MaxLenApprox: r.conf.MaxLen,
+			Values:       values,
+		}).Err()
+	case RedisPubSub:
+		buf, err := json.Marshal(rec)
+
+		if err != nil {
+			return err
+		}
+
+		return c.Publish(key, buf).Err()
+	}
+
+	return fmt.Errorf("unknown redis mode %q", r.conf.Mode)
+}
+
+func (r *Redis) Close() error {
+	return r.c.Close()
+}
@@ -0,0 +1,94 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v7"
+	"github.com/yanishoss/schedulo/internal/core"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestRedis(t *testing.T, mode RedisMode) (*Redis, *redis.Client) {
+	addr := os.Getenv("REDIS_ADDR")
+
+	if addr == "" {
+		t.Skip("REDIS_ADDR is not set")
+	}
+
+	prefix := "schedulo_test:" + strconv.FormatInt(time.Now().UnixNano(), 10) + ":"
+
+	r, err := NewRedis(RedisConfig{Addr: addr, Mode: mode, Prefix: prefix, MaxLen: 100})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := redis.NewClient(&redis.Options{Addr: addr})
+
+	t.Cleanup(func() {
+		c.Del(prefix + "billing")
+		c.Close()
+		r.Close()
+	})
+
+	return r, c
+}
+
+func TestRedis_Stream(t *testing.T) {
+	r, c := newTestRedis(t, RedisStream)
+
+	at := time.Unix(1600000000, 0)
+
+	if err := r.Send(context.Background(), core.Event{ID: "1", Topic: "billing", ShouldExecuteAt: at, Payload: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := c.XRange(r.conf.Prefix+"billing", "-", "+").Result()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("Wrong number of messages: expected:%d, got:%d\n", 1, len(msgs))
+	}
+
+	v := msgs[0].Values
+
+	if v["id"] != "1" || v["topic"] != "billing" || v["payload"] != "hello" || v["scheduled_at"] != "1600000000" {
+		t.Fatalf("Wrong message: %v\n", v)
+	}
+}
+
+func TestRedis_PubSub(t *testing.T) {
+	r, c := newTestRedis(t, RedisPubSub)
+
+	sub := c.Subscribe(r.conf.Prefix + "billing")
+	defer sub.Close()
+
+	if _, err := sub.Receive(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Send(context.Background(), core.Event{ID: "1", Topic: "billing", Payload: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-sub.Channel():
+		var rec Record
+
+		if err := json.Unmarshal([]byte(msg.Payload), &rec); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.ID != "1" || string(rec.Payload) != "hello" {
+			t.Fatalf("Wrong message: %+v\n", rec)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("The event was not published\n")
+	}
+}
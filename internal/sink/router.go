@@ -0,0 +1,179 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Route sends the events of the topics matching one of its glob patterns to its sinks
+type Route struct {
+	Topics []string
+	Sinks  []string
+}
+
+func (r Route) matches(topic string) bool {
+	for _, t := range r.Topics {
+		if ok, _ := path.Match(t, topic); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+type route struct {
+	Route
+	targets []*entry
+}
+
+// Router dispatches each event to the sinks of the first route matching its topic,
+// the events matched by no route are sent to the fallback sink
+type Router struct {
+	routes   []route
+	fallback []*entry
+}
+
+func NewRouter(reg *Registry, routes []Route, fallback string) (*Router, error) {
+	r := &Router{}
+
+	resolve := func(names []string) ([]*entry, error) {
+		targets := make([]*entry, len(names))
+
+		for i, name := range names {
+			e, ok := reg.get(name)
+
+			if !ok {
+				return nil, fmt.Errorf("%s: %w", name, ErrUnknownSink)
+			}
+
+			targets[i] = e
+		}
+
+		return targets, nil
+	}
+
+	for _, rt := range routes {
+		for _, t := range rt.Topics {
+			if _, err := path.Match(t, ""); err != nil {
+				return nil, fmt.Errorf("invalid topic pattern %q: %w", t, err)
+			}
+		}
+
+		targets, err := resolve(rt.Sinks)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.routes = append(r.routes, route{rt, targets})
+	}
+
+	fb, err := resolve([]string{fallback})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.fallback = fb
+
+	return r, nil
+}
+
+func (r *Router) targets(topic string) []*entry {
+	for _, rt := range r.routes {
+		if rt.matches(topic) {
+			return rt.targets
+		}
+	}
+
+	return r.fallback
+}
+
+// target is a sink to send an event to, fn narrows the send to the retry of a sink which partially failed
+type target struct {
+	*entry
+	fn core.DispatchFunc
+}
+
+func (t target) send(ctx context.Context, e core.Event) error {
+	if t.fn == nil {
+		return t.entry.send(ctx, e)
+	}
+
+	return t.call(ctx, e, t.fn)
+}
+
+// retry returns the target to send the event to again after err, the sinks can narrow their retry
+// with a core.RetryError
+func (t target) retry(err error) target {
+	var retryErr *core.RetryError
+
+	if errors.As(err, &retryErr) && retryErr.Retry != nil {
+		return target{t.entry, retryErr.Retry}
+	}
+
+	return target{entry: t.entry}
+}
+
+// Dispatch is the core.DispatchFunc of the router, when some sinks fail only them are retried
+func (r *Router) Dispatch(ctx context.Context, e core.Event) error {
+	entries := r.targets(e.Topic)
+	targets := make([]target, len(entries))
+
+	for i, en := range entries {
+		targets[i] = target{entry: en}
+	}
+
+	return r.send(ctx, e, targets)
+}
+
+func (r *Router) send(ctx context.Context, e core.Event, targets []target) error {
+	if len(targets) == 1 {
+		if err := targets[0].send(ctx, e); err != nil {
+			return &core.RetryError{Err: err, Retry: r.retry([]target{targets[0].retry(err)})}
+		}
+
+		return nil
+	}
+
+	errs := make([]error, len(targets))
+	wg := &sync.WaitGroup{}
+
+	for i, t := range targets {
+		wg.Add(1)
+
+		go func(i int, t target) {
+			defer wg.Done()
+			errs[i] = t.send(ctx, e)
+		}(i, t)
+	}
+
+	wg.Wait()
+
+	var failed []target
+	var msgs []string
+
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, targets[i].retry(err))
+			msgs = append(msgs, err.Error())
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &core.RetryError{Err: fmt.Errorf("%s", strings.Join(msgs, "; ")), Retry: r.retry(failed)}
+}
+
+func (r *Router) retry(targets []target) core.DispatchFunc {
+	return func(ctx context.Context, e core.Event) error {
+		return r.send(ctx, e, targets)
+	}
+}
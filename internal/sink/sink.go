@@ -0,0 +1,188 @@
+// Package sink delivers the dispatched events to their destinations, the topics are routed to the sinks
+// registered under a name
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"io"
+	"log"
+	"sort"
+	"sync"
+)
+
+// Subscribers is the name of the built-in sink streaming the events to the gRPC subscribers
+const Subscribers = "grpc"
+
+var (
+	ErrDuplicateSink = errors.New("a sink is already registered under this name")
+	ErrUnknownSink   = errors.New("no sink is registered under this name")
+)
+
+// Sink is a destination of the dispatched events
+type Sink interface {
+	Send(ctx context.Context, e core.Event) error
+}
+
+// Func adapts a function to the Sink interface
+type Func func(ctx context.Context, e core.Event) error
+
+func (f Func) Send(ctx context.Context, e core.Event) error {
+	return f(ctx, e)
+}
+
+// FailurePolicy tells what happens to the events a sink failed to receive
+type FailurePolicy string
+
+const (
+	// Retry reports the failure so that the dispatch of the event to the sink is retried
+	Retry FailurePolicy = "retry"
+
+	// Ignore logs the failure and drops the event
+	Ignore FailurePolicy = "ignore"
+)
+
+var FailurePolicies = []FailurePolicy{Retry, Ignore}
+
+type Options struct {
+	// Concurrency bounds the number of events sent to the sink at once, it is unbounded if it is zero
+	Concurrency int
+
+	// FailurePolicy is Retry by default
+	FailurePolicy FailurePolicy
+}
+
+type entry struct {
+	name string
+	sink Sink
+	opts Options
+	sem  chan struct{}
+}
+
+func (e *entry) send(ctx context.Context, ev core.Event) error {
+	return e.call(ctx, ev, e.sink.Send)
+}
+
+// call sends the event through fn under the options of the sink, fn is either the Send method of the sink
+// or the retry it returned in a core.RetryError
+func (e *entry) call(ctx context.Context, ev core.Event, fn core.DispatchFunc) error {
+	if e.sem != nil {
+		select {
+		case e.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		defer func() {
+			<-e.sem
+		}()
+	}
+
+	err := fn(ctx, ev)
+
+	if err == nil {
+		return nil
+	}
+
+	if e.opts.FailurePolicy == Ignore {
+		log.Printf("sink %s dropped event %s: %v\n", e.name, ev.ID, err)
+		return nil
+	}
+
+	return fmt.Errorf("sink %s: %w", e.name, err)
+}
+
+// Registry holds the sinks by name
+type Registry struct {
+	sinks map[string]*entry
+	mu    *sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		sinks: make(map[string]*entry),
+		mu:    &sync.RWMutex{},
+	}
+}
+
+func (r *Registry) Register(name string, s Sink, opts Options) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sinks[name]; ok {
+		return fmt.Errorf("%s: %w", name, ErrDuplicateSink)
+	}
+
+	if opts.FailurePolicy == "" {
+		opts.FailurePolicy = Retry
+	}
+
+	e := &entry{
+		name: name,
+		sink: s,
+		opts: opts,
+	}
+
+	if opts.Concurrency > 0 {
+		e.sem = make(chan struct{}, opts.Concurrency)
+	}
+
+	r.sinks[name] = e
+
+	return nil
+}
+
+func (r *Registry) Get(name string) (Sink, bool) {
+	e, ok := r.get(name)
+
+	if !ok {
+		return nil, false
+	}
+
+	return e.sink, true
+}
+
+func (r *Registry) get(name string) (*entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.sinks[name]
+
+	return e, ok
+}
+
+// Names returns the names of the registered sinks in alphabetical order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.sinks))
+
+	for name := range r.sinks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Close closes the sinks holding resources, such as files or connections
+func (r *Registry) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var err error
+
+	for _, e := range r.sinks {
+		if c, ok := e.sink.(io.Closer); ok {
+			if errC := c.Close(); errC != nil {
+				err = errC
+			}
+		}
+	}
+
+	return err
+}
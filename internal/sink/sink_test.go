@@ -0,0 +1,327 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type _sinkMock struct {
+	mu     *sync.Mutex
+	events []core.Event
+	err    error
+}
+
+func newSinkMock(err error) *_sinkMock {
+	return &_sinkMock{mu: &sync.Mutex{}, err: err}
+}
+
+func (s *_sinkMock) Send(ctx context.Context, e core.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, e)
+
+	return s.err
+}
+
+func (s *_sinkMock) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.events)
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	reg := NewRegistry()
+
+	grpc, hook, audit := newSinkMock(nil), newSinkMock(nil), newSinkMock(nil)
+
+	reg.Register("grpc", grpc, Options{})
+	reg.Register("hook", hook, Options{})
+	reg.Register("audit", audit, Options{})
+
+	if err := reg.Register("audit", audit, Options{}); !errors.Is(err, ErrDuplicateSink) {
+		t.Fatalf("A sink name must be unique: got %v\n", err)
+	}
+
+	if _, err := NewRouter(reg, []Route{{Topics: []string{"*"}, Sinks: []string{"unknown"}}}, "grpc"); !errors.Is(err, ErrUnknownSink) {
+		t.Fatalf("The routes must only reference registered sinks: got %v\n", err)
+	}
+
+	r, err := NewRouter(reg, []Route{
+		{Topics: []string{"billing.*"}, Sinks: []string{"hook", "audit"}},
+		{Topics: []string{"*.audit"}, Sinks: []string{"audit"}},
+	}, "grpc")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, topic := range []string{"billing.invoice", "billing.audit", "shipping.audit", "shipping.parcel"} {
+		if err := r.Dispatch(context.Background(), core.Event{Topic: topic}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if grpc.count() != 1 || hook.count() != 2 || audit.count() != 3 {
+		t.Fatalf("Wrong routing: expected:%d/%d/%d, got:%d/%d/%d\n", 1, 2, 3, grpc.count(), hook.count(), audit.count())
+	}
+}
+
+func TestRouter_FailurePolicy(t *testing.T) {
+	reg := NewRegistry()
+
+	ok, failing, ignored := newSinkMock(nil), newSinkMock(errors.New("unavailable")), newSinkMock(errors.New("unavailable"))
+
+	reg.Register("ok", ok, Options{})
+	reg.Register("failing", failing, Options{FailurePolicy: Retry})
+	reg.Register("ignored", ignored, Options{FailurePolicy: Ignore})
+
+	r, err := NewRouter(reg, []Route{{Topics: []string{"*"}, Sinks: []string{"ok", "failing", "ignored"}}}, "ok")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Dispatch(context.Background(), core.Event{Topic: "billing"})
+
+	var retryErr *core.RetryError
+
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("A failing sink must be reported for retry: got %v\n", err)
+	}
+
+	if err := retryErr.Retry(context.Background(), core.Event{Topic: "billing"}); err == nil {
+		t.Fatalf("The failing sink must still fail\n")
+	}
+
+	if ok.count() != 1 || failing.count() != 2 || ignored.count() != 1 {
+		t.Fatalf("Only the failing sink must be retried: expected:%d/%d/%d, got:%d/%d/%d\n", 1, 2, 1, ok.count(), failing.count(), ignored.count())
+	}
+}
+
+func TestRegistry_Concurrency(t *testing.T) {
+	var running, max int32
+
+	slow := Func(func(ctx context.Context, e core.Event) error {
+		n := atomic.AddInt32(&running, 1)
+
+		for {
+			m := atomic.LoadInt32(&max)
+
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		return nil
+	})
+
+	reg := NewRegistry()
+	reg.Register("slow", slow, Options{Concurrency: 2})
+
+	r, err := NewRouter(reg, nil, "slow")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			r.Dispatch(context.Background(), core.Event{})
+		}()
+	}
+
+	wg.Wait()
+
+	if m := atomic.LoadInt32(&max); m != 2 {
+		t.Fatalf("Wrong number of concurrent sends: expected:%d, got:%d\n", 2, m)
+	}
+}
+
+func TestWriter_Send(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+
+	at := time.Unix(1600000000, 0)
+
+	if err := w.Send(context.Background(), core.Event{ID: "1", Topic: "billing", ShouldExecuteAt: at, Payload: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rec Record
+
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.ID != "1" || rec.Topic != "billing" || rec.ScheduledAt != at.Unix() || string(rec.Payload) != "hello" || rec.FiredAt == 0 {
+		t.Fatalf("Wrong record: %+v\n", rec)
+	}
+}
+
+func TestOutbox_Send(t *testing.T) {
+	o := &Outbox{insert: "INSERT", now: time.Now}
+
+	if err := o.Send(context.Background(), core.Event{ID: "1"}); !errors.Is(err, core.ErrNotTransactional) {
+		t.Fatalf("An outbox must only be written in the transaction completing the dispatch: got %v\n", err)
+	}
+
+	for name, valid := range map[string]bool{"outbox": true, "public.outbox": true, "outbox; DROP TABLE events": false, "": false} {
+		if ValidTableName(name) != valid {
+			t.Fatalf("Wrong validation of %q: expected:%t\n", name, valid)
+		}
+	}
+}
+
+func TestCommand_Send(t *testing.T) {
+	var logs []string
+
+	c, err := NewCommand(CommandConfig{
+		Args: []string{"sh", "-c", `read p && echo "$1 $SCHEDULO_EVENT_ID $p" && [ "$p" = hello ]`, "sh", "{{.Topic}}"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.logf = func(format string, v ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, v...))
+	}
+
+	if err := c.Send(context.Background(), core.Event{ID: "1", Topic: "billing", Payload: []byte("hello\n")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logs) != 1 || !strings.HasSuffix(logs[0], "billing 1 hello\n") {
+		t.Fatalf("The output of the command must be logged: got %q\n", logs)
+	}
+
+	err = c.Send(context.Background(), core.Event{ID: "2", Topic: "billing", Payload: []byte("bye\n")})
+
+	var exitErr *ExitError
+
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 1 {
+		t.Fatalf("A non-zero exit status must fail the dispatch: got %v\n", err)
+	}
+
+	if _, err := NewCommand(CommandConfig{Args: []string{"{{.Unknown"}}); err == nil {
+		t.Fatalf("An invalid argument template must be rejected\n")
+	}
+}
+
+func TestCommand_Timeout(t *testing.T) {
+	c, err := NewCommand(CommandConfig{Args: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if err := c.Send(context.Background(), core.Event{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("A command must be killed after its timeout: got %v\n", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("The command ran too long: %s\n", d)
+	}
+}
+
+func TestCommand_TimeoutChildren(t *testing.T) {
+	// The background child keeps the output open after its parent is killed
+	c, err := NewCommand(CommandConfig{Args: []string{"sh", "-c", "sleep 60 & sleep 60"}, Timeout: 50 * time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	if err := c.Send(context.Background(), core.Event{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("A command must be killed after its timeout: got %v\n", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("The children of the command must be killed as well: took %s\n", d)
+	}
+}
+
+func TestCommand_MaxProcesses(t *testing.T) {
+	c, err := NewCommand(CommandConfig{Args: []string{"sleep", "0.1"}, MaxProcesses: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := &sync.WaitGroup{}
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			c.Send(context.Background(), core.Event{})
+		}()
+	}
+
+	wg.Wait()
+
+	if d := time.Since(start); d < 300*time.Millisecond {
+		t.Fatalf("The processes must run one at a time: took %s\n", d)
+	}
+}
+
+func TestRouter_NarrowedRetry(t *testing.T) {
+	var sends, retries int32
+
+	partial := Func(func(ctx context.Context, e core.Event) error {
+		atomic.AddInt32(&sends, 1)
+
+		return &core.RetryError{Err: errors.New("partially failed"), Retry: func(ctx context.Context, e core.Event) error {
+			atomic.AddInt32(&retries, 1)
+			return nil
+		}}
+	})
+
+	reg := NewRegistry()
+	reg.Register("partial", partial, Options{})
+
+	r, err := NewRouter(reg, nil, "partial")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var retryErr *core.RetryError
+
+	if err := r.Dispatch(context.Background(), core.Event{}); !errors.As(err, &retryErr) {
+		t.Fatalf("A failing sink must be reported for retry: got %v\n", err)
+	}
+
+	if err := retryErr.Retry(context.Background(), core.Event{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if sends != 1 || retries != 1 {
+		t.Fatalf("Only the retry of the sink must be called again: expected:%d/%d, got:%d/%d\n", 1, 1, sends, retries)
+	}
+}
@@ -0,0 +1,84 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/yanishoss/schedulo/internal/core"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is the representation of a dispatched event shared by the sinks writing to external systems
+type Record struct {
+	ID          core.ID           `json:"id"`
+	Topic       string            `json:"topic"`
+	Payload     []byte            `json:"payload"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ScheduledAt int64             `json:"scheduledAt"`
+	FiredAt     int64             `json:"firedAt"`
+}
+
+func NewRecord(e core.Event, firedAt time.Time) Record {
+	return Record{
+		ID:          e.ID,
+		Topic:       e.Topic,
+		Payload:     e.Payload,
+		Labels:      e.Labels,
+		ScheduledAt: e.ShouldExecuteAt.Unix(),
+		FiredAt:     firedAt.Unix(),
+	}
+}
+
+// Writer writes each event as a line of JSON
+type Writer struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:  w,
+		mu: &sync.Mutex{},
+	}
+}
+
+// NewFile returns a Writer appending to the file, which is created if needed
+func NewFile(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriter(f), nil
+}
+
+func (w *Writer) Send(ctx context.Context, e core.Event) error {
+	buf, err := json.Marshal(NewRecord(e, time.Now()))
+
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.w.Write(append(buf, '\n'))
+
+	return err
+}
+
+// Close closes the underlying writer, unless it is one of the standard outputs
+func (w *Writer) Close() error {
+	if w.w == os.Stdout || w.w == os.Stderr {
+		return nil
+	}
+
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package tlstest generates self-signed certificates for tests
+package tlstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Authority is a self-signed certificate authority
+type Authority struct {
+	CertFile string
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	dir      string
+}
+
+// KeyPair is a certificate signed by an Authority
+type KeyPair struct {
+	CertFile string
+	KeyFile  string
+}
+
+// TempDir returns a directory removed at the end of the test
+func TempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "schedulo-tls")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	return dir
+}
+
+// NewAuthority writes a new authority named name in dir
+func NewAuthority(t *testing.T, dir string, name string) *Authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial(t),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Authority{
+		CertFile: filepath.Join(dir, name+".ca.pem"),
+		cert:     cert,
+		key:      key,
+		dir:      dir,
+	}
+
+	writePEM(t, a.CertFile, "CERTIFICATE", der)
+
+	return a
+}
+
+// Issue writes a certificate valid for localhost, usable by both servers and clients
+func (a *Authority) Issue(t *testing.T, name string) KeyPair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial(t),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, &key.PublicKey, a.key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kp := KeyPair{
+		CertFile: filepath.Join(a.dir, name+".pem"),
+		KeyFile:  filepath.Join(a.dir, name+".key"),
+	}
+
+	writePEM(t, kp.CertFile, "CERTIFICATE", der)
+	writePEM(t, kp.KeyFile, "EC PRIVATE KEY", keyDer)
+
+	return kp
+}
+
+func serial(t *testing.T) *big.Int {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+func writePEM(t *testing.T, path string, typ string, der []byte) {
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,293 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoCertificate     = errors.New("no certificate could be found in the file")
+	ErrNoServerName      = errors.New("the server name is required to verify the server against a CA file")
+	ErrNoPeerCertificate = errors.New("the server presented no certificate")
+)
+
+// watchedFiles tells whether a set of files has been modified since the last check
+type watchedFiles struct {
+	paths    []string
+	modTimes []time.Time
+}
+
+func newWatchedFiles(paths ...string) *watchedFiles {
+	return &watchedFiles{
+		paths:    paths,
+		modTimes: make([]time.Time, len(paths)),
+	}
+}
+
+func (w *watchedFiles) changed() bool {
+	changed := false
+
+	for i, p := range w.paths {
+		fstat, err := os.Stat(p)
+
+		// A file being rewritten may briefly be missing, the current version is kept meanwhile
+		if err != nil {
+			continue
+		}
+
+		if !fstat.ModTime().Equal(w.modTimes[i]) {
+			w.modTimes[i] = fstat.ModTime()
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// KeyPairReloader serves a certificate and its key, they are loaded again whenever one of the files changes
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+	files    *watchedFiles
+	cert     *tls.Certificate
+	mu       *sync.Mutex
+}
+
+func NewKeyPairReloader(certFile string, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		files:    newWatchedFiles(certFile, keyFile),
+		mu:       &sync.Mutex{},
+	}
+
+	if _, err := r.Certificate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Certificate returns the current key pair, if the new files cannot be loaded the previous pair is kept
+func (r *KeyPairReloader) Certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.files.changed() && r.cert != nil {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+
+		return nil, err
+	}
+
+	r.cert = &cert
+
+	return r.cert, nil
+}
+
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate()
+}
+
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate()
+}
+
+// PoolReloader serves a pool of certificate authorities, it is loaded again whenever its file changes
+type PoolReloader struct {
+	file  string
+	files *watchedFiles
+	pool  *x509.CertPool
+	mu    *sync.Mutex
+}
+
+func NewPoolReloader(file string) (*PoolReloader, error) {
+	r := &PoolReloader{
+		file:  file,
+		files: newWatchedFiles(file),
+		mu:    &sync.Mutex{},
+	}
+
+	if _, err := r.Pool(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Pool returns the current pool, if the new file cannot be loaded the previous pool is kept
+func (r *PoolReloader) Pool() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.files.changed() && r.pool != nil {
+		return r.pool, nil
+	}
+
+	pool, err := loadPool(r.file)
+
+	if err != nil {
+		if r.pool != nil {
+			return r.pool, nil
+		}
+
+		return nil, err
+	}
+
+	r.pool = pool
+
+	return r.pool, nil
+}
+
+func loadPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: %w", file, ErrNoCertificate)
+	}
+
+	return pool, nil
+}
+
+// ServerConfig returns a TLS configuration serving the given key pair,
+// client certificates signed by the authorities of clientCAFile are required if it is not empty
+func ServerConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	kp, err := NewKeyPairReloader(certFile, keyFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: kp.GetCertificate,
+	}
+
+	if clientCAFile == "" {
+		return conf, nil
+	}
+
+	cas, err := NewPoolReloader(clientCAFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf.ClientAuth = tls.RequireAndVerifyClientCert
+
+	// The client authorities are resolved for every handshake so that they can be reloaded
+	conf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := cas.Pool()
+
+		if err != nil {
+			return nil, err
+		}
+
+		c := conf.Clone()
+		c.GetConfigForClient = nil
+		c.ClientCAs = pool
+
+		return c, nil
+	}
+
+	return conf, nil
+}
+
+// ClientConfig returns a TLS configuration trusting the authorities of caFile, or the system ones if it is empty,
+// and presenting the given key pair if certFile is not empty. The authorities of caFile are reloaded like the
+// client authorities of ServerConfig, the server is then verified against serverName
+func ClientConfig(caFile string, certFile string, keyFile string, serverName string) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		if serverName == "" {
+			return nil, ErrNoServerName
+		}
+
+		cas, err := NewPoolReloader(caFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The default verification only knows the authorities given upfront, VerifyConnection which would
+		// see the server name does not exist before Go 1.15
+		conf.InsecureSkipVerify = true
+		conf.VerifyPeerCertificate = verifyServer(cas, serverName)
+	}
+
+	if certFile != "" {
+		kp, err := NewKeyPairReloader(certFile, keyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		conf.GetClientCertificate = kp.GetClientCertificate
+	}
+
+	return conf, nil
+}
+
+// verifyServer verifies the certificate chain of the server against the current authorities of cas
+func verifyServer(cas *PoolReloader, serverName string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return ErrNoPeerCertificate
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+
+			if err != nil {
+				return err
+			}
+
+			certs[i] = cert
+		}
+
+		pool, err := cas.Pool()
+
+		if err != nil {
+			return err
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         pool,
+			DNSName:       serverName,
+			Intermediates: x509.NewCertPool(),
+		}
+
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		_, err = certs[0].Verify(opts)
+
+		return err
+	}
+}
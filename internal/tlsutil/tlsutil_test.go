@@ -0,0 +1,221 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/yanishoss/schedulo/internal/tlsutil/tlstest"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, conf *tls.Config) string {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", conf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				// The accepted connections are kept open until the client closes them
+				if err := conn.(*tls.Conn).Handshake(); err == nil {
+					io.Copy(ioutil.Discard, conn)
+				}
+			}()
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestServerConfig_MutualTLS(t *testing.T) {
+	dir := tlstest.TempDir(t)
+	ca := tlstest.NewAuthority(t, dir, "ca")
+	server := ca.Issue(t, "server")
+	client := ca.Issue(t, "client")
+
+	rogue := tlstest.NewAuthority(t, dir, "rogue").Issue(t, "rogue")
+
+	serverConf, err := ServerConfig(server.CertFile, server.KeyFile, ca.CertFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serve(t, serverConf)
+
+	dial := func(certFile string, keyFile string) error {
+		conf, err := ClientConfig(ca.CertFile, certFile, keyFile, "localhost")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := tls.Dial("tcp", addr, conf)
+
+		if err != nil {
+			return err
+		}
+
+		defer conn.Close()
+
+		// With TLS 1.3 the client certificate is only rejected after the handshake
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = conn.Read(make([]byte, 1))
+
+		if netErr, ok := err.(interface{ Timeout() bool }); ok && netErr.Timeout() {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := dial(client.CertFile, client.KeyFile); err != nil {
+		t.Fatalf("A client presenting a trusted certificate must be accepted: %v\n", err)
+	}
+
+	if err := dial("", ""); err == nil {
+		t.Fatalf("A client without certificate must be rejected\n")
+	}
+
+	if err := dial(rogue.CertFile, rogue.KeyFile); err == nil {
+		t.Fatalf("A client presenting an untrusted certificate must be rejected\n")
+	}
+}
+
+func TestClientConfig_RotatedAuthority(t *testing.T) {
+	dir := tlstest.TempDir(t)
+	old := tlstest.NewAuthority(t, dir, "old")
+	rotated := tlstest.NewAuthority(t, dir, "rotated")
+	server := rotated.Issue(t, "server")
+
+	serverConf, err := ServerConfig(server.CertFile, server.KeyFile, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serve(t, serverConf)
+
+	trusted := dir + "/trusted.pem"
+	copyFile(t, old.CertFile, trusted)
+
+	conf, err := ClientConfig(trusted, "", "", "localhost")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial := func() error {
+		conn, err := tls.Dial("tcp", addr, conf)
+
+		if err == nil {
+			conn.Close()
+		}
+
+		return err
+	}
+
+	if err := dial(); err == nil {
+		t.Fatalf("A server signed by an untrusted authority must be rejected\n")
+	}
+
+	copyFile(t, rotated.CertFile, trusted)
+
+	later := time.Now().Add(time.Minute)
+
+	if err := os.Chtimes(trusted, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dial(); err != nil {
+		t.Fatalf("The rotated authority must be trusted: %v\n", err)
+	}
+
+	if _, err := ClientConfig(trusted, "", "", ""); err != ErrNoServerName {
+		t.Fatalf("A server name is required with a CA file: expected:%v, got:%v\n", ErrNoServerName, err)
+	}
+}
+
+func copyFile(t *testing.T, src string, dst string) {
+	b, err := ioutil.ReadFile(src)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(dst, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	dir := tlstest.TempDir(t)
+	ca := tlstest.NewAuthority(t, dir, "ca")
+	kp := ca.Issue(t, "server")
+
+	r, err := NewKeyPairReloader(kp.CertFile, kp.KeyFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := r.Certificate()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kp = ca.Issue(t, "server")
+
+	later := time.Now().Add(time.Minute)
+
+	for _, f := range []string{kp.CertFile, kp.KeyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := r.Certificate()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstLeaf, _ := x509.ParseCertificate(first.Certificate[0])
+	secondLeaf, _ := x509.ParseCertificate(second.Certificate[0])
+
+	if firstLeaf.SerialNumber.Cmp(secondLeaf.SerialNumber) == 0 {
+		t.Fatalf("The certificate must be reloaded when its files change\n")
+	}
+
+	if err := ioutil.WriteFile(kp.KeyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(kp.KeyFile, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	third, err := r.Certificate()
+
+	if err != nil || third != second {
+		t.Fatalf("The previous certificate must be kept when the new files are invalid: %v\n", err)
+	}
+}
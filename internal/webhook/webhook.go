@@ -0,0 +1,124 @@
+// Package webhook dispatches the events by POSTing their payload to HTTP endpoints
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/yanishoss/schedulo/internal/core"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+
+	HeaderEventID   = "X-Schedulo-Event-Id"
+	HeaderTopic     = "X-Schedulo-Topic"
+	HeaderTimestamp = "X-Schedulo-Timestamp"
+	HeaderSignature = "X-Schedulo-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Endpoint is the HTTP endpoint the events are POSTed to
+type Endpoint struct {
+	URL string
+
+	// Secret is the key of the HMAC signature, the requests are not signed if it is empty
+	Secret string
+
+	// Timeout bounds each request, DefaultTimeout is used if it is zero
+	Timeout time.Duration
+}
+
+// StatusError is returned when an endpoint does not answer with a 2xx status code
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook %s answered with status %d", e.URL, e.StatusCode)
+}
+
+// Webhook POSTs the payload of the events to its endpoint
+type Webhook struct {
+	endpoint Endpoint
+	client   *http.Client
+	now      func() time.Time
+}
+
+func New(endpoint Endpoint) *Webhook {
+	return &Webhook{
+		endpoint: endpoint,
+		client:   &http.Client{},
+		now:      time.Now,
+	}
+}
+
+// Send POSTs the event to the endpoint, it fails if the endpoint does not answer with a 2xx status code
+// so that the dispatch can be retried
+func (w *Webhook) Send(ctx context.Context, e core.Event) error {
+	timeout := w.endpoint.Timeout
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint.URL, bytes.NewReader(e.Payload))
+
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(w.now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(HeaderEventID, string(e.ID))
+	req.Header.Set(HeaderTopic, e.Topic)
+	req.Header.Set(HeaderTimestamp, timestamp)
+
+	if w.endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(w.endpoint.Secret, timestamp, e.Payload))
+	}
+
+	resp, err := w.client.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return err
+	}
+
+	// The body is drained so that the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{URL: w.endpoint.URL, StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// Sign returns the signature header of a request, the HMAC-SHA256 of `<timestamp>.<payload>`
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a request, the receivers should also reject the timestamps which are too old
+func Verify(secret string, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"github.com/yanishoss/schedulo/internal/core"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	var received []byte
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if !Verify("s3cr3t", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Header.Get(HeaderEventID) != "1" || r.Header.Get(HeaderTopic) != "billing.invoice" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		received = body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	w := New(Endpoint{URL: ts.URL, Secret: "s3cr3t"})
+
+	if err := w.Send(context.Background(), core.Event{ID: "1", Topic: "billing.invoice", Payload: []byte("hello")}); err != nil {
+		t.Fatalf("The event must be delivered: %v\n", err)
+	}
+
+	if string(received) != "hello" {
+		t.Fatalf("Wrong payload: expected:%s, got:%s\n", "hello", received)
+	}
+
+	var statusErr *StatusError
+
+	w = New(Endpoint{URL: ts.URL, Secret: "wrong"})
+
+	if err := w.Send(context.Background(), core.Event{ID: "1", Topic: "billing.invoice"}); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("A non-2xx response must fail: got %v\n", err)
+	}
+}
+
+func TestWebhook_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	w := New(Endpoint{URL: ts.URL, Timeout: 20 * time.Millisecond})
+
+	start := time.Now()
+
+	if err := w.Send(context.Background(), core.Event{ID: "1", Topic: "billing"}); err == nil {
+		t.Fatalf("A request exceeding the timeout must fail\n")
+	}
+
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("The request was not interrupted: %s\n", elapsed)
+	}
+}
@@ -2,25 +2,81 @@ package schedulo
 
 import (
 	"context"
+	"errors"
 	"github.com/yanishoss/schedulo/api"
 	"github.com/yanishoss/schedulo/internal/core"
+	"github.com/yanishoss/schedulo/internal/tlsutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	"io"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
 	TimestampMode = core.TimestampMode
-	CronMode = core.TimestampMode
+	CronMode      = core.CronMode
+	IntervalMode  = core.IntervalMode
+	RRuleMode     = core.RRuleMode
 )
 
+const (
+	MisfireFireOnce = core.MisfireFireOnce
+	MisfireFireAll  = core.MisfireFireAll
+	MisfireSkip     = core.MisfireSkip
+)
+
+const (
+	CalendarSkip  = core.CalendarSkip
+	CalendarShift = core.CalendarShift
+)
+
+const (
+	JitterHash   = core.JitterHash
+	JitterRandom = core.JitterRandom
+)
+
+// NoJitter opts an event out of the jitter of its topic
+const NoJitter = core.NoJitter
+
 type Event = core.Event
 type ID = core.ID
+type MisfirePolicy = core.MisfirePolicy
+type CalendarPolicy = core.CalendarPolicy
+type JitterMode = core.JitterMode
+type Preview = core.Preview
+
+// AllTopics subscribes to every topic, the topics of the subscriptions are dot-separated levels where
+// "*" matches one level and ">" the trailing levels, e.g. "billing.*.due" or "billing.>"
+const AllTopics = ">"
+
+// ErrSubSecondInterval is returned for an interval which is not a whole number of seconds, the API carries
+// the intervals in seconds
+var ErrSubSecondInterval = errors.New("the interval must be a whole number of seconds")
+
+// ResumeBackoff is the delay before a failed stream is resumed
+var ResumeBackoff = time.Second
 
 type Client interface {
 	Schedule(ctx context.Context, e Event) (ID, error)
+
+	// Preview lists the next n occurrences of the event without scheduling it, the error tells why
+	// Schedule would reject the event
+	Preview(ctx context.Context, e Event, n int) (Preview, error)
 	Unschedule(ctx context.Context, id ID) error
+	List(ctx context.Context, topic string) ([]Event, error)
+
+	// OnEvent calls cb with each event dispatched to the topic. The stream is resumed whenever it fails,
+	// cbErr is called with the error, and the events dispatched meanwhile are received once it is resumed
 	OnEvent(ctx context.Context, topic string, cb func(Event), cbErr func(error)) error
+
+	// OnMatchingEvent only receives the events whose labels match the filter expression,
+	// e.g. `tenant = acme and region in (eu, us)`
+	OnMatchingEvent(ctx context.Context, topic string, filter string, cb func(Event), cbErr func(error)) error
 	ListenToEvent(ctx context.Context, topic string, cb func(Event)) error
 	Close() error
 }
@@ -30,9 +86,103 @@ type client struct {
 	conn *grpc.ClientConn
 }
 
-func New(addr string) (Client, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 20*time.Second)
-	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+type options struct {
+	tls        bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+	token      string
+}
+
+// tokenCredentials sends a bearer token along with every call
+type tokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+type Option func(*options)
+
+// WithTLS enables TLS, the server certificate is verified against the authorities of caFile
+// or against the system ones if caFile is empty
+func WithTLS(caFile string) Option {
+	return func(o *options) {
+		o.tls = true
+		o.caFile = caFile
+	}
+}
+
+// WithClientCertificate presents the given certificate to the server for mutual TLS, it implies WithTLS.
+// The files are loaded again whenever they change
+func WithClientCertificate(certFile string, keyFile string) Option {
+	return func(o *options) {
+		o.tls = true
+		o.certFile = certFile
+		o.keyFile = keyFile
+	}
+}
+
+// WithToken authenticates every call with an API key or a JWT, the token is sent in clear text
+// unless TLS is enabled
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithServerName overrides the name used to verify the server certificate
+func WithServerName(name string) Option {
+	return func(o *options) {
+		o.serverName = name
+	}
+}
+
+func New(addr string, opts ...Option) (Client, error) {
+	o := options{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := grpc.WithInsecure()
+
+	if o.tls {
+		// The server is verified against the host of the address by default
+		if o.serverName == "" {
+			o.serverName = addr
+
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				o.serverName = host
+			}
+		}
+
+		tlsConf, err := tlsutil.ClientConfig(o.caFile, o.certFile, o.keyFile, o.serverName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(tlsConf))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	dialOpts := []grpc.DialOption{transport, grpc.WithBlock()}
+
+	if o.token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials{o.token, o.tls}))
+	}
+
+	conn, err := grpc.DialContext(ctx, addr, dialOpts...)
 
 	if err != nil {
 		return nil, err
@@ -44,9 +194,14 @@ func New(addr string) (Client, error) {
 }
 
 func (cl *client) Schedule(ctx context.Context, e core.Event) (core.ID, error) {
-	ctx, _ = context.WithTimeout(ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
 
-	ev := coreEventToApiEvent(e)
+	ev, err := coreEventToApiEvent(e)
+
+	if err != nil {
+		return "", err
+	}
 
 	resp, err := cl.c.Schedule(ctx, &api.ScheduleRequest{Event: &ev})
 
@@ -57,8 +212,41 @@ func (cl *client) Schedule(ctx context.Context, e core.Event) (core.ID, error) {
 	return core.ID(resp.Id.Id), nil
 }
 
+func (cl *client) Preview(ctx context.Context, e core.Event, n int) (core.Preview, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	ev, err := coreEventToApiEvent(e)
+
+	if err != nil {
+		return core.Preview{}, err
+	}
+
+	resp, err := cl.c.PreviewSchedule(ctx, &api.PreviewScheduleRequest{Event: &ev, Count: uint32(n)})
+
+	if err != nil {
+		return core.Preview{}, err
+	}
+
+	if !resp.Valid {
+		return core.Preview{}, errors.New(resp.Error)
+	}
+
+	p := core.Preview{
+		Description: resp.Description,
+		Occurrences: make([]time.Time, len(resp.Occurrences)),
+	}
+
+	for i, o := range resp.Occurrences {
+		p.Occurrences[i] = time.Unix(o, 0)
+	}
+
+	return p, nil
+}
+
 func (cl *client) Unschedule(ctx context.Context, id core.ID) error {
-	ctx, _ = context.WithTimeout(ctx, 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
 
 	_, err := cl.c.Unschedule(ctx, &api.UnscheduleRequest{
 		Id: &api.Event_ID{
@@ -69,39 +257,82 @@ func (cl *client) Unschedule(ctx context.Context, id core.ID) error {
 	return err
 }
 
+// List returns the scheduled events of the topic, or of every topic if it is empty
+func (cl *client) List(ctx context.Context, topic string) ([]core.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	resp, err := cl.c.ListEvents(ctx, &api.ListEventsRequest{
+		Topic: topic,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]core.Event, len(resp.Events))
+
+	for i, e := range resp.Events {
+		evs[i] = apiEventToCoreEvent(*e)
+	}
+
+	return evs, nil
+}
+
 func (cl *client) Close() error {
 	return cl.conn.Close()
 }
 
 func (cl *client) OnEvent(ctx context.Context, topic string, cb func(core.Event), cbErr func(error)) error {
-	stream, err := cl.c.StreamEvents(ctx, &api.StreamEventsRequest{
-		Topic: topic,
-	})
+	return cl.OnMatchingEvent(ctx, topic, "", cb, cbErr)
+}
+
+func (cl *client) OnMatchingEvent(ctx context.Context, topic string, filter string, cb func(core.Event), cbErr func(error)) error {
+	req := &api.StreamEventsRequest{
+		Topic:  topic,
+		Filter: filter,
+	}
+
+	stream, err := cl.c.StreamEvents(ctx, req)
 
 	if err != nil {
 		return err
 	}
 
 	go func() {
+		r := &resumer{seen: make(map[uint64]bool)}
+
 		for {
-			select {
-			case <- stream.Context().Done():
-				cbErr(stream.Context().Err())
+			err := r.receive(stream, cb)
+
+			if ctx.Err() != nil {
+				cbErr(ctx.Err())
 				return
-			default:
-				resp, err := stream.Recv()
+			}
+
+			cbErr(err)
+
+			if !resumable(err) {
+				return
+			}
 
-				if err == io.EOF {
-					cbErr(err)
+			// The stream is resumed from the last token, the events dispatched meanwhile are received first
+			for {
+				select {
+				case <-ctx.Done():
+					cbErr(ctx.Err())
 					return
+				case <-time.After(ResumeBackoff):
 				}
 
-				if err != nil {
-					cbErr(err)
+				req.ResumeToken = r.token
+				stream, err = cl.c.StreamEvents(ctx, req)
+
+				if err == nil {
 					break
 				}
 
-				go cb(apiEventToCoreEvent(*resp.Event))
+				cbErr(err)
 			}
 		}
 	}()
@@ -109,6 +340,74 @@ func (cl *client) OnEvent(ctx context.Context, topic string, cb func(core.Event)
 	return nil
 }
 
+// resumer follows the resume token of a stream and skips the events received twice across the reconnects
+type resumer struct {
+	token string
+
+	// seen are the sequences received beyond the token
+	seen map[uint64]bool
+}
+
+// receive calls cb with each event of the stream until it fails
+func (r *resumer) receive(stream api.Scheduler_StreamEventsClient, cb func(core.Event)) error {
+	for {
+		resp, err := stream.Recv()
+
+		if err != nil {
+			return err
+		}
+
+		dup := resp.Sequence != 0 && r.seen[resp.Sequence]
+
+		if resp.ResumeToken != "" {
+			r.advance(resp.ResumeToken)
+		}
+
+		if resp.Sequence != 0 {
+			r.seen[resp.Sequence] = true
+		}
+
+		if !dup {
+			go cb(apiEventToCoreEvent(*resp.Event))
+		}
+	}
+}
+
+// advance moves to the token, which is made of the epoch of the server and of the sequence it covers
+func (r *resumer) advance(token string) {
+	i := strings.LastIndex(token, ".")
+	j := strings.LastIndex(r.token, ".")
+
+	// The sequences start over when the server restarts
+	if i < 0 || j < 0 || token[:i] != r.token[:j] {
+		r.seen = make(map[uint64]bool)
+	}
+
+	r.token = token
+
+	if seq, err := strconv.ParseUint(token[i+1:], 16, 64); err == nil {
+		for s := range r.seen {
+			if s <= seq {
+				delete(r.seen, s)
+			}
+		}
+	}
+}
+
+// resumable tells whether the stream may be resumed after the error
+func resumable(err error) bool {
+	if err == io.EOF {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return false
+	}
+
+	return true
+}
+
 func (cl *client) ListenToEvent(ctx context.Context, topic string, cb func(core.Event)) error {
 	stream, err := cl.c.StreamEvents(ctx, &api.StreamEventsRequest{
 		Topic: topic,
@@ -134,12 +433,27 @@ func (cl *client) ListenToEvent(ctx context.Context, topic string, cb func(core.
 	}
 }
 
-func coreEventToApiEvent(e core.Event) api.Event {
+// coreEventToApiEvent rejects the values the API cannot carry rather than altering them
+func coreEventToApiEvent(e core.Event) (api.Event, error) {
+	// The maximum number of occurrences is unsigned in the API
+	if e.MaxOccurrences < 0 {
+		return api.Event{}, core.ErrInvalidBounds
+	}
+
+	if e.Interval%time.Second != 0 {
+		return api.Event{}, ErrSubSecondInterval
+	}
+
 	var mode api.Event_Mode
 
-	if e.Mode == core.TimestampMode {
+	switch e.Mode {
+	case core.TimestampMode:
 		mode = api.Event_TIMESTAMP
-	} else {
+	case core.IntervalMode:
+		mode = api.Event_INTERVAL
+	case core.RRuleMode:
+		mode = api.Event_RRULE
+	default:
 		mode = api.Event_CRON
 	}
 
@@ -150,15 +464,35 @@ func coreEventToApiEvent(e core.Event) api.Event {
 		Mode:            mode,
 		Topic:           e.Topic,
 		Payload:         e.Payload,
-	}
+		Labels:          e.Labels,
+		TimeZone:        e.TimeZone,
+		StartAt:         unixTimestamp(e.StartAt),
+		EndAt:           unixTimestamp(e.EndAt),
+		MaxOccurrences:  uint32(e.MaxOccurrences),
+		FireCount:       uint32(e.FireCount),
+		MisfirePolicy:   api.Event_MisfirePolicy(e.Misfire),
+		LastFiredAt:     unixTimestamp(e.LastFiredAt),
+		IntervalSeconds: int64(e.Interval / time.Second),
+		Anchor:          unixTimestamp(e.Anchor),
+		Rrule:           e.RRule,
+		Calendar:        e.Calendar,
+		CalendarPolicy:  api.Event_CalendarPolicy(e.CalendarPolicy),
+		JitterMs:        int64(e.Jitter / time.Millisecond),
+		JitterMode:      api.Event_JitterMode(e.JitterMode),
+	}, nil
 }
 
 func apiEventToCoreEvent(e api.Event) core.Event {
 	var mode core.EventMode
 
-	if e.Mode == api.Event_TIMESTAMP {
+	switch e.Mode {
+	case api.Event_TIMESTAMP:
 		mode = core.TimestampMode
-	} else {
+	case api.Event_INTERVAL:
+		mode = core.IntervalMode
+	case api.Event_RRULE:
+		mode = core.RRuleMode
+	default:
 		mode = core.CronMode
 	}
 
@@ -169,5 +503,37 @@ func apiEventToCoreEvent(e api.Event) core.Event {
 		Mode:            mode,
 		Topic:           e.Topic,
 		Payload:         e.Payload,
+		Labels:          e.Labels,
+		TimeZone:        e.TimeZone,
+		StartAt:         fromUnixTimestamp(e.StartAt),
+		EndAt:           fromUnixTimestamp(e.EndAt),
+		MaxOccurrences:  int(e.MaxOccurrences),
+		FireCount:       int(e.FireCount),
+		Misfire:         core.MisfirePolicy(e.MisfirePolicy),
+		LastFiredAt:     fromUnixTimestamp(e.LastFiredAt),
+		Interval:        time.Duration(e.IntervalSeconds) * time.Second,
+		Anchor:          fromUnixTimestamp(e.Anchor),
+		RRule:           e.Rrule,
+		Calendar:        e.Calendar,
+		CalendarPolicy:  core.CalendarPolicy(e.CalendarPolicy),
+		Jitter:          time.Duration(e.JitterMs) * time.Millisecond,
+		JitterMode:      core.JitterMode(e.JitterMode),
 	}
+}
+
+// unixTimestamp converts the optional times, the zero time is 0
+func unixTimestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+func fromUnixTimestamp(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(ts, 0)
 }
\ No newline at end of file
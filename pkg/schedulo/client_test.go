@@ -0,0 +1,185 @@
+package schedulo
+
+import (
+	"context"
+	"github.com/yanishoss/schedulo/api"
+	"github.com/yanishoss/schedulo/internal/core"
+	"github.com/yanishoss/schedulo/internal/tlsutil"
+	"github.com/yanishoss/schedulo/internal/tlsutil/tlstest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"net"
+	"testing"
+	"time"
+)
+
+type _schedulerServerMock struct {
+	api.UnimplementedSchedulerServer
+	unscheduled []string
+	tokens      chan string
+}
+
+func (s *_schedulerServerMock) StreamEvents(req *api.StreamEventsRequest, stream api.Scheduler_StreamEventsServer) error {
+	s.tokens <- req.ResumeToken
+
+	// The first stream fails after the second event, which is replayed once the stream is resumed
+	resps := []*api.StreamEventsResponse{
+		{Event: &api.Event{Id: "1"}, Sequence: 1, ResumeToken: "e.0"},
+		{Event: &api.Event{Id: "2"}, Sequence: 2, ResumeToken: "e.1"},
+	}
+
+	if req.ResumeToken != "" {
+		resps = []*api.StreamEventsResponse{
+			{Event: &api.Event{Id: "2"}, Sequence: 2, ResumeToken: "e.2"},
+			{Event: &api.Event{Id: "3"}, Sequence: 3, ResumeToken: "e.2"},
+		}
+	}
+
+	for _, resp := range resps {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	if req.ResumeToken == "" {
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	}
+
+	<-stream.Context().Done()
+
+	return nil
+}
+
+func (s *_schedulerServerMock) Unschedule(ctx context.Context, req *api.UnscheduleRequest) (*api.UnscheduleResponse, error) {
+	s.unscheduled = append(s.unscheduled, req.Id.Id)
+
+	return &api.UnscheduleResponse{}, nil
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	dir := tlstest.TempDir(t)
+	ca := tlstest.NewAuthority(t, dir, "ca")
+	server := ca.Issue(t, "server")
+	client := ca.Issue(t, "client")
+
+	tlsConf, err := tlsutil.ServerConfig(server.CertFile, server.KeyFile, ca.CertFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &_schedulerServerMock{}
+
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConf)))
+	api.RegisterSchedulerServer(grpcServer, srv)
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	cl, err := New(lis.Addr().String(), WithTLS(ca.CertFile), WithClientCertificate(client.CertFile, client.KeyFile), WithServerName("localhost"))
+
+	if err != nil {
+		t.Fatalf("An error occurred while creating the client: %v\n", err)
+	}
+
+	defer cl.Close()
+
+	if err := cl.Unschedule(context.Background(), "test"); err != nil {
+		t.Fatalf("An error occurred while calling the server over mutual TLS: %v\n", err)
+	}
+
+	if len(srv.unscheduled) != 1 || srv.unscheduled[0] != "test" {
+		t.Fatalf("The request has not reached the server: %v\n", srv.unscheduled)
+	}
+}
+
+func TestClient_ResumeStream(t *testing.T) {
+	ResumeBackoff = 10 * time.Millisecond
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &_schedulerServerMock{tokens: make(chan string, 2)}
+
+	grpcServer := grpc.NewServer()
+	api.RegisterSchedulerServer(grpcServer, srv)
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	cl, err := New(lis.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer cl.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan ID, 4)
+
+	if err := cl.OnEvent(ctx, "billing", func(e Event) { received <- e.ID }, func(error) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if token := <-srv.tokens; token != "" {
+		t.Fatalf("The first stream must not be resumed: got %q\n", token)
+	}
+
+	if token := <-srv.tokens; token != "e.1" {
+		t.Fatalf("Wrong resume token: expected:%s, got:%s\n", "e.1", token)
+	}
+
+	ids := make(map[ID]int)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case id := <-received:
+			ids[id]++
+		case <-time.After(time.Second):
+			t.Fatalf("Missing events: got %v\n", ids)
+		}
+	}
+
+	select {
+	case id := <-received:
+		t.Fatalf("The replayed event %s must be received once\n", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if ids["1"] != 1 || ids["2"] != 1 || ids["3"] != 1 {
+		t.Fatalf("Wrong events: %v\n", ids)
+	}
+}
+
+func TestClient_InvalidEvent(t *testing.T) {
+	cl := &client{}
+
+	// The event is rejected before any call to the server
+	if _, err := cl.Schedule(context.Background(), Event{Mode: CronMode, CronExpression: "* * * * *", MaxOccurrences: -1}); err != core.ErrInvalidBounds {
+		t.Fatalf("A negative maximum number of occurrences must be rejected: expected:%v, got:%v\n", core.ErrInvalidBounds, err)
+	}
+
+	if _, err := cl.Preview(context.Background(), Event{Mode: CronMode, CronExpression: "* * * * *", MaxOccurrences: -1}, 3); err != core.ErrInvalidBounds {
+		t.Fatalf("A negative maximum number of occurrences must be rejected: expected:%v, got:%v\n", core.ErrInvalidBounds, err)
+	}
+
+	for _, interval := range []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond} {
+		if _, err := cl.Schedule(context.Background(), Event{Mode: IntervalMode, Interval: interval}); err != ErrSubSecondInterval {
+			t.Fatalf("An interval of %s must be rejected rather than truncated: expected:%v, got:%v\n", interval, ErrSubSecondInterval, err)
+		}
+	}
+}